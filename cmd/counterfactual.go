@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/explain"
+	m "github.com/nyunja/c4.5-decision-tree/internal/model/model"
+	p "github.com/nyunja/c4.5-decision-tree/internal/model/parser"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/predict"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
+	"github.com/nyunja/c4.5-decision-tree/internal/source"
+)
+
+// runCounterfactual finds, for every row of the input file, the cheapest
+// changes that would change its predicted class
+func runCounterfactual() {
+	if modelFile == "" {
+		utils.LogError("model_file_not_found")
+	}
+
+	if !source.Exists(input) {
+		utils.LogError("missing_input_file")
+	}
+
+	model, err := m.LoadModel(modelFile)
+	if err != nil {
+		utils.LogError("model_file_not_found")
+	}
+
+	options, err := counterfactualOptionsFromFlags()
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if err := explain.ValidateCounterfactualOptions(model, options); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	instances, _, _, err := p.ParsePredictionFile(input, model, inputOptionsFromFlags())
+	if err != nil {
+		log.Fatalf("Error parsing input: %v", err)
+	}
+
+	predictions := predict.BatchPredict(model, instances)
+	column := make([]string, len(instances))
+	for i, instance := range instances {
+		counterfactuals, err := explain.Counterfactuals(model, instance, options)
+		if errors.Is(err, explain.ErrAlreadyPredicted) {
+			// Rows already predicted as the desired class need no change
+			counterfactuals = []explain.Counterfactual{}
+		} else if err != nil {
+			log.Fatalf("Error finding counterfactuals for row %d: %v", i+1, err)
+		}
+		encoded, err := json.Marshal(counterfactuals)
+		if err != nil {
+			log.Fatalf("Error encoding counterfactuals: %v", err)
+		}
+		column[i] = string(encoded)
+	}
+
+	err = predict.SaveColumns(output, []string{"prediction", "counterfactuals"}, [][]string{predictions, column})
+	if err != nil {
+		log.Fatalf("Error saving counterfactuals: %v", err)
+	}
+	fmt.Printf("Counterfactuals saved to %s\n", output)
+}
+
+// counterfactualOptionsFromFlags builds the counterfactual search options from the CLI flags
+func counterfactualOptionsFromFlags() (explain.CounterfactualOptions, error) {
+	options := explain.CounterfactualOptions{
+		Target:    desiredClass,
+		Immutable: immutableFeatures,
+		Costs:     make(map[string]float64, len(featureCosts)),
+		Limit:     counterfactualLimit,
+	}
+	for feature, value := range featureCosts {
+		cost, err := strconv.ParseFloat(value, 64)
+		if err != nil || cost < 0 {
+			return options, fmt.Errorf("invalid cost '%s' for feature '%s'", value, feature)
+		}
+		options.Costs[feature] = cost
+	}
+	return options, nil
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/evaluate"
+	m "github.com/nyunja/c4.5-decision-tree/internal/model/model"
+	p "github.com/nyunja/c4.5-decision-tree/internal/model/parser"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
+	"github.com/nyunja/c4.5-decision-tree/internal/source"
+)
+
+// runEvaluate scores a saved model against a labelled input file
+func runEvaluate() {
+	if modelFile == "" {
+		utils.LogError("model_file_not_found")
+	}
+
+	// check if input file exists
+	if !source.Exists(input) {
+		utils.LogError("missing_input_file")
+	}
+
+	model, err := m.LoadModel(modelFile)
+	if err != nil {
+		log.Fatalf("Error loading model: %v", err)
+	}
+
+	instances, _, _, err := p.ParsePredictionFile(input, model, inputOptionsFromFlags())
+	if err != nil {
+		log.Fatalf("Error parsing input: %v", err)
+	}
+
+	metrics, err := evaluate.Model(model, instances)
+	if err != nil {
+		log.Fatalf("Error evaluating model: %v", err)
+	}
+
+	switch metrics := metrics.(type) {
+	case evaluate.RegressionMetrics:
+		fmt.Printf("Evaluated %d instances\n", metrics.Count)
+		fmt.Printf("MAE:  %.6g\n", metrics.MAE)
+		fmt.Printf("RMSE: %.6g\n", metrics.RMSE)
+		fmt.Printf("R²:   %.6g\n", metrics.R2)
+	case evaluate.ClassificationMetrics:
+		fmt.Printf("Evaluated %d instances\n", metrics.Count)
+		fmt.Printf("Accuracy: %.4f (%d/%d)\n", metrics.Accuracy, metrics.Correct, metrics.Count)
+	}
+}
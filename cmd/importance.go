@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/importance"
+	m "github.com/nyunja/c4.5-decision-tree/internal/model/model"
+	p "github.com/nyunja/c4.5-decision-tree/internal/model/parser"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
+	"github.com/nyunja/c4.5-decision-tree/internal/source"
+)
+
+// runImportance reports the feature importances of a saved model, adding
+// permutation importance when a labelled input file is given
+func runImportance() {
+	if modelFile == "" {
+		utils.LogError("model_file_not_found")
+	}
+
+	model, err := m.LoadModel(modelFile)
+	if err != nil {
+		utils.LogError("model_file_not_found")
+	}
+
+	var instances []t.Instance
+	if input != "" {
+		if !source.Exists(input) {
+			utils.LogError("missing_input_file")
+		}
+		instances, _, _, err = p.ParsePredictionFile(input, model, inputOptionsFromFlags())
+		if err != nil {
+			log.Fatalf("Error parsing input: %v", err)
+		}
+	}
+
+	importances, err := importance.Report(model, instances, repeats, m.ResolveSeed(seed))
+	if err != nil {
+		log.Fatalf("Error computing feature importance: %v", err)
+	}
+
+	out := os.Stdout
+	if output != "" {
+		out, err = os.Create(output)
+		if err != nil {
+			log.Fatalf("Error creating output file: %v", err)
+		}
+		defer out.Close()
+	}
+	if err := importance.Write(out, importances, format); err != nil {
+		log.Fatalf("Error writing feature importance: %v", err)
+	}
+	if output != "" {
+		fmt.Printf("Feature importance saved to %s\n", output)
+	}
+}
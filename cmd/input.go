@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	tcsv "github.com/nyunja/c4.5-decision-tree/internal/csv"
+	p "github.com/nyunja/c4.5-decision-tree/internal/model/parser"
+	"github.com/nyunja/c4.5-decision-tree/internal/schema"
+)
+
+// inputOptionsFromFlags builds the options input files are read with from the flags
+func inputOptionsFromFlags() p.Options {
+	options := p.DefaultOptions()
+	options.Dialect = csvDialectFromFlags()
+	options.SinglePass = singlePass
+	if schemaFile != "" {
+		declared, err := schema.Load(schemaFile)
+		if err != nil {
+			log.Fatalf("Error in input options: %v", err)
+		}
+		options.Schema = declared
+	}
+	if len(missing) > 0 {
+		if options.Schema == nil {
+			options.Schema = &schema.Schema{}
+		}
+		options.Schema.Missing = append(options.Schema.Missing, missing...)
+	}
+	options.Strict = strict
+	if inputFormat != "" {
+		options.Format = strings.ToLower(inputFormat)
+		if !slices.Contains(p.Formats, options.Format) {
+			log.Fatalf("Error in input options: unknown input format '%s', expected one of %s", inputFormat, strings.Join(p.Formats, ", "))
+		}
+	}
+	return options
+}
+
+// csvDialectFromFlags builds the dialect CSV inputs are read in from the flags
+func csvDialectFromFlags() tcsv.Dialect {
+	dialect, err := csvDialect()
+	if err != nil {
+		log.Fatalf("Error in CSV options: %v", err)
+	}
+	return dialect
+}
+
+func csvDialect() (tcsv.Dialect, error) {
+	var err error
+	dialect := tcsv.DefaultDialect()
+
+	dialect.Delimiter, err = tcsv.ParseDelimiter(delimiter)
+	if err != nil {
+		return dialect, err
+	}
+	if comment != "" {
+		if utf8.RuneCountInString(comment) != 1 {
+			return dialect, fmt.Errorf("comment must be a single character, got '%s'", comment)
+		}
+		dialect.Comment, _ = utf8.DecodeRuneInString(comment)
+		if dialect.Comment == dialect.Delimiter {
+			return dialect, fmt.Errorf("comment and delimiter must differ")
+		}
+	}
+	dialect.Encoding, err = tcsv.ParseEncoding(encoding)
+	if err != nil {
+		return dialect, err
+	}
+
+	dialect.LazyQuotes = lazyQuotes
+	dialect.TrimSpace = trimSpace
+	dialect.NoHeader = noHeader
+	if len(columnNames) > 0 && !noHeader {
+		return dialect, fmt.Errorf("--columns names the columns of files read with --no-header")
+	}
+	dialect.Columns = columnNames
+	return dialect, nil
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/explain"
+	m "github.com/nyunja/c4.5-decision-tree/internal/model/model"
+	p "github.com/nyunja/c4.5-decision-tree/internal/model/parser"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/predict"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
+	"github.com/nyunja/c4.5-decision-tree/internal/source"
+)
+
+// runPredict predicts the input file with a saved model and writes the predictions
+func runPredict() {
+	if modelFile == "" {
+		utils.LogError("model_file_not_found")
+	}
+
+	// check if input file exists
+	if !source.Exists(input) {
+		utils.LogError("missing_input_file")
+	}
+
+	// Load the model
+	fmt.Println("Loading model...")
+	model, err := m.LoadModel(modelFile)
+	if err != nil {
+		utils.LogError("model_file_not_found")
+	}
+	fmt.Println("Model loaded successfully")
+
+	// parse the CSV file with streaming
+	instances, headers, _, err := p.ParsePredictionFile(input, model, inputOptionsFromFlags())
+	if err != nil {
+		log.Fatalf("Error parsing input: %v", err)
+	}
+	fmt.Printf("Parsed %d instances with %d features\n", len(instances), len(headers))
+
+	// Make predictions
+	fmt.Println("Making predictions...")
+	predictions := predict.BatchPredict(model, instances)
+	fmt.Println("Predictions made successfully")
+
+	// Save predictions
+	fmt.Println("Saving predictions...")
+	if explainFlag || shapFlag {
+		err = saveWithExplanations(model, instances, predictions)
+	} else {
+		err = predict.SavePredictions(instances, predictions, output, headers)
+	}
+	if err != nil {
+		log.Fatalf("Error saving predictions: %v", err)
+	}
+
+	fmt.Printf("Predictions successfully made and saved to %s\n", output)
+}
+
+// saveWithExplanations writes the predictions with an explanation column
+// holding each row's decision path as a single line of JSON, and SHAP value
+// columns, as requested by the flags
+func saveWithExplanations(model *t.Model, instances []t.Instance, predictions []string) error {
+	header := []string{"prediction"}
+	columns := [][]string{predictions}
+
+	if explainFlag {
+		explanations := explain.Batch(model, instances)
+		column := make([]string, len(explanations))
+		for i, explanation := range explanations {
+			encoded, err := json.Marshal(explanation)
+			if err != nil {
+				return fmt.Errorf("error encoding explanation: %v", err)
+			}
+			column[i] = string(encoded)
+		}
+		header = append(header, "explanation")
+		columns = append(columns, column)
+	}
+
+	if shapFlag {
+		shapHeader, shapColumns := explain.SHAPColumns(model, explain.SHAPBatch(model, instances))
+		header = append(header, shapHeader...)
+		columns = append(columns, shapColumns...)
+	}
+
+	return predict.SaveColumns(output, header, columns)
+}
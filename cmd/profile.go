@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	p "github.com/nyunja/c4.5-decision-tree/internal/model/parser"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
+	"github.com/nyunja/c4.5-decision-tree/internal/profile"
+	"github.com/nyunja/c4.5-decision-tree/internal/source"
+)
+
+// runProfile reports the column types, missing values, cardinalities and
+// value distributions of the input file, with ID, constant and leaking
+// columns flagged, to review a dataset before training on it
+func runProfile() {
+	// check if input file exists
+	if !source.Exists(input) {
+		utils.LogError("missing_input_file")
+	}
+
+	inputOptions := inputOptionsFromFlags()
+	if target == "" {
+		target = inputOptions.Schema.Target()
+	}
+	if target == "" {
+		target = p.DefaultTarget(input)
+	}
+
+	instances, headers, featureTypes, err := p.ParseTrainingFile(input, target, inputOptions)
+	if err != nil {
+		log.Fatalf("Error parsing input: %v", err)
+	}
+	if _, ok := featureTypes[target]; target != "" && !ok {
+		utils.LogError("target_column_not_found")
+	}
+
+	report, err := profile.Build(instances, headers, featureTypes, target)
+	if err != nil {
+		log.Fatalf("Error profiling data: %v", err)
+	}
+
+	out := os.Stdout
+	if output != "" {
+		out, err = os.Create(output)
+		if err != nil {
+			log.Fatalf("Error creating output file: %v", err)
+		}
+		defer out.Close()
+	}
+	if err := profile.Write(out, report, format); err != nil {
+		log.Fatalf("Error writing profile: %v", err)
+	}
+	if output != "" {
+		fmt.Printf("Profile saved to %s\n", output)
+	}
+}
@@ -2,14 +2,11 @@ package cmd
 
 import (
 	"fmt"
-	"log"
-	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 
-	m "github.com/nyunja/c4.5-decision-tree/internal/model/model"
-	p "github.com/nyunja/c4.5-decision-tree/internal/model/parser"
-	"github.com/nyunja/c4.5-decision-tree/internal/model/predict"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/server"
 	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
 )
 
@@ -19,6 +16,59 @@ var (
 	input     string
 	output    string
 	modelFile string
+
+	criterion           string
+	mdlCorrection       bool
+	averageGainFilter   bool
+	subsetGrouping      bool
+	binaryCategorical   bool
+	weightColumn        string
+	costMatrix          string
+	task                string
+	regressionCriterion string
+	linearLeaves        bool
+
+	ensemble    string
+	trees       int
+	maxFeatures int
+	voting      string
+
+	boostDepth         int
+	validationFraction float64
+	patience           int
+
+	seed               int64
+	bagSize            float64
+	withoutReplacement bool
+
+	format      string
+	repeats     int
+	explainFlag bool
+	shapFlag    bool
+
+	desiredClass        string
+	immutableFeatures   []string
+	featureCosts        map[string]string
+	counterfactualLimit int
+
+	delimiter   string
+	comment     string
+	lazyQuotes  bool
+	trimSpace   bool
+	noHeader    bool
+	columnNames []string
+	encoding    string
+	inputFormat string
+	singlePass  bool
+	schemaFile  string
+	missing     []string
+	strict      bool
+	autoExclude bool
+
+	addr           string
+	grpcAddr       string
+	maxBodyBytes   int64
+	reloadInterval time.Duration
 )
 
 // Define the subcommands for train and predict commands
@@ -26,102 +76,57 @@ var RootCmd = &cobra.Command{
 	Use:   "dt",
 	Short: "C4.5 Decision Tree CLI",
 	Run: func(cmd *cobra.Command, args []string) {
-		if output == "" {
-			utils.LogError("output_path_missing")
-		}
-		if input == "" {
-			utils.LogError("missing_input_file")
-		}
 		if command == "" {
 			cmd.Usage()
 			return
 		}
+		// importance only needs labelled data for permutation importance,
+		// and serve reads its instances from requests
+		if input == "" && command != "importance" && command != "serve" {
+			utils.LogError("missing_input_file")
+		}
 		switch command {
 		case "train":
-			if target == "" {
-				utils.LogError("target_column_not_found")
-			}
-
-			// check if input file exists
-			if _, err := os.Stat(input); os.IsNotExist(err) {
-				utils.LogError("missing_input_file")
-			}
-
-			// parse the CSV file with streaming
-			instances, headers, featureTypes, err := p.StreamingCSVParser(input, true, 10000, target)
-			if err != nil {
-				utils.LogError("missing_parsing_csv")
+			if output == "" {
+				utils.LogError("output_path_missing")
 			}
-			fmt.Printf("Parsed %d instances with %d features\n", len(instances), len(headers))
+			runTrain()
 
-			// Check if target column exists
-			if _, ok := featureTypes[target]; !ok {
-				utils.LogError("target_column_not_found")
+		case "predict":
+			if output == "" {
+				utils.LogError("output_path_missing")
 			}
+			runPredict()
 
-			// Parse user-specified excluded columns
-			excludeColumns := []string{}
+		case "evaluate":
+			runEvaluate()
 
-			fmt.Printf("Columns excluded from training: %v\n", excludeColumns)
+		case "importance":
+			runImportance()
 
-			// Train the model
-			fmt.Println("Training model...")
-			model, err := m.Train(instances, headers, target, featureTypes, excludeColumns, 20)
-			if err != nil {
-				utils.LogError("training_error")
-			}
-			fmt.Println("Model trained successfully")
+		case "shap":
+			runSHAP()
 
-			// Save the model
-			fmt.Println("Saving model...")
-			err = m.SaveModel(model, output)
-			if err != nil {
-				utils.LogError("saving_model_error")
+		case "counterfactual":
+			if output == "" {
+				utils.LogError("output_path_missing")
 			}
+			runCounterfactual()
 
-		case "predict":
-			if modelFile == "" {
-				utils.LogError("model_file_not_found")
-			}
+		case "serve":
+			runServe()
 
-			// check if input file exists
-			if _, err := os.Stat(input); os.IsNotExist(err) {
-				utils.LogError("missing_input_file")
-			}
+		case "profile":
+			runProfile()
 
-			// Load the model
-			fmt.Println("Loading model...")
-			model, err := m.LoadModel(modelFile)
-			if err != nil {
-				utils.LogError("model_file_not_found")
+		case "schema":
+			if output == "" {
+				utils.LogError("output_path_missing")
 			}
-			fmt.Println("Model loaded successfully")
-
-			// parse the CSV file with streaming
-			instances, headers, _, err := p.PredictionCSVParser(input, true, 10000, model.TargetName)
-			if err != nil {
-				utils.LogError("error_parsing_csv")
-				log.Fatalf("Error parsing CSV: %v", err)
-			}
-			fmt.Printf("Parsed %d instances with %d features\n", len(instances), len(headers))
-
-			// Make predictions
-			fmt.Println("Making predictions...")
-			predictions := predict.BatchPredict(model, instances)
-			fmt.Println("Predictions made successfully")
-
-			// Save predictions
-			fmt.Println("Saving predictions...")
-			err = predict.SavePredictions(instances, predictions, output, headers)
-			if err != nil {
-				utils.LogError("error_saving_predictions")
-				log.Fatalf("Error saving predictions: %v", err)
-			}
-
-			fmt.Printf("Predictions successfully made and saved to %s\n", output)
+			runSchema()
 
 		default:
-			fmt.Println("Invalid command. Use -c train")
+			fmt.Println("Invalid command. Use -c train, predict, evaluate, importance, shap, counterfactual, serve, schema or profile")
 			cmd.Usage()
 		}
 	},
@@ -129,10 +134,55 @@ var RootCmd = &cobra.Command{
 
 // Run the command
 func init() {
-	RootCmd.PersistentFlags().StringVarP(&command, "command", "c", "", "Specify command (train)")
+	RootCmd.PersistentFlags().StringVarP(&command, "command", "c", "", "Specify command (train, predict, evaluate, importance)")
 	RootCmd.MarkPersistentFlagRequired("command")
 	RootCmd.PersistentFlags().StringVarP(&target, "target", "t", "", "Specify target column")
-	RootCmd.PersistentFlags().StringVarP(&input, "input", "i", "", "Input data file (CSV format)")
+	RootCmd.PersistentFlags().StringVarP(&input, "input", "i", "", "Input data file, possibly gzip, zstd or bzip2 compressed, or - for standard input")
 	RootCmd.PersistentFlags().StringVarP(&output, "output", "o", "", "Output model file")
 	RootCmd.PersistentFlags().StringVarP(&modelFile, "model", "m", "", "Training model file")
+	RootCmd.PersistentFlags().StringVar(&criterion, "criterion", "gain-ratio", "Split criterion (gain-ratio, info-gain, gini, chi-square, g-statistic)")
+	RootCmd.PersistentFlags().BoolVar(&mdlCorrection, "mdl-correction", true, "Penalise continuous thresholds by log2(N-1)/|D| (C4.5 Release 8)")
+	RootCmd.PersistentFlags().BoolVar(&averageGainFilter, "average-gain-filter", true, "Only pick among features with at least average gain (C4.5 Release 8)")
+	RootCmd.PersistentFlags().BoolVar(&subsetGrouping, "subset-grouping", false, "Merge categorical values into groups by greedy gain-ratio merging (C4.5 -s)")
+	RootCmd.PersistentFlags().StringVar(&weightColumn, "weight-column", "", "Numeric column holding instance weights")
+	RootCmd.PersistentFlags().StringVar(&costMatrix, "cost-matrix", "", "CSV file of misclassification costs (actual,predicted,cost)")
+	RootCmd.PersistentFlags().BoolVar(&binaryCategorical, "binary-categorical", false, "Split categorical features into value in S / not in S for two-class nodes")
+	RootCmd.PersistentFlags().StringVar(&task, "task", "auto", "Learning task (auto, classification, regression)")
+	RootCmd.PersistentFlags().StringVar(&regressionCriterion, "regression-criterion", "variance", "Regression split criterion (variance, sd)")
+	RootCmd.PersistentFlags().BoolVar(&linearLeaves, "linear-leaves", false, "Fit a linear model at each regression leaf instead of the mean")
+	RootCmd.PersistentFlags().StringVar(&ensemble, "ensemble", "", "Train an ensemble instead of a single tree (forest, bag, boost)")
+	RootCmd.PersistentFlags().IntVar(&trees, "trees", 100, "Number of trees in the ensemble, the maximum number of rounds when boosting")
+	RootCmd.PersistentFlags().IntVar(&maxFeatures, "max-features", 0, "Features drawn at each forest node, sqrt(p) or p/3 for regression when 0")
+	RootCmd.PersistentFlags().StringVar(&voting, "voting", "majority", "How forest trees vote (majority, probability)")
+	RootCmd.PersistentFlags().IntVar(&boostDepth, "boost-depth", 3, "Maximum depth of each boosted tree")
+	RootCmd.PersistentFlags().Float64Var(&validationFraction, "validation-fraction", 0.2, "Fraction of instances held out for early stopping of boosting, 0 keeps every round")
+	RootCmd.PersistentFlags().IntVar(&patience, "patience", 5, "Boosting rounds without a lower validation error before stopping")
+	RootCmd.PersistentFlags().Int64Var(&seed, "seed", 0, "Seed for row sampling, bootstrap draws and validation splits, random when 0")
+	RootCmd.PersistentFlags().Float64Var(&bagSize, "bag-size", 1, "Sample size of each forest or bagging tree as a fraction of the instances")
+	RootCmd.PersistentFlags().BoolVar(&withoutReplacement, "without-replacement", false, "Draw forest and bagging samples without replacement")
+	RootCmd.PersistentFlags().StringVar(&format, "format", "table", "Report format (table, csv, json; html for profile)")
+	RootCmd.PersistentFlags().IntVar(&repeats, "repeats", 5, "Shuffles per feature for permutation importance")
+	RootCmd.PersistentFlags().BoolVar(&explainFlag, "explain", false, "Add an explanation column with each prediction's decision path as JSON")
+	RootCmd.PersistentFlags().BoolVar(&shapFlag, "shap", false, "Add TreeSHAP feature contribution columns for each class to the predictions")
+	RootCmd.PersistentFlags().StringVar(&desiredClass, "desired-class", "", "Class counterfactuals should reach (any other class when empty)")
+	RootCmd.PersistentFlags().StringSliceVar(&immutableFeatures, "immutable", nil, "Features counterfactuals may not change (comma separated)")
+	RootCmd.PersistentFlags().StringToStringVar(&featureCosts, "feature-costs", nil, "Cost of changing each feature, e.g. income=0.001,region=5 (default 1)")
+	RootCmd.PersistentFlags().IntVar(&counterfactualLimit, "limit", 3, "Counterfactuals reported per row (0 for all)")
+	RootCmd.PersistentFlags().StringVar(&delimiter, "delimiter", ",", "CSV field delimiter: a single character, or tab, semicolon or pipe")
+	RootCmd.PersistentFlags().StringVar(&comment, "comment", "", "Skip CSV lines starting with this character")
+	RootCmd.PersistentFlags().BoolVar(&lazyQuotes, "lazy-quotes", false, "Accept stray quotes in CSV fields")
+	RootCmd.PersistentFlags().BoolVar(&trimSpace, "trim-space", false, "Trim the white space around CSV fields")
+	RootCmd.PersistentFlags().BoolVar(&noHeader, "no-header", false, "The first CSV row holds values; columns are named by --columns or col0, col1, ...")
+	RootCmd.PersistentFlags().StringSliceVar(&columnNames, "columns", nil, "Column names of CSV files read with --no-header (comma separated)")
+	RootCmd.PersistentFlags().StringVar(&encoding, "encoding", "utf-8", "Encoding of CSV files (utf-8, latin-1)")
+	RootCmd.PersistentFlags().StringVar(&inputFormat, "input-format", "", "Format of the input (csv, jsonl, arff, c4.5, parquet), taken from its extension when empty")
+	RootCmd.PersistentFlags().StringVar(&schemaFile, "schema", "", "YAML or JSON file declaring column types, roles, categories, date formats and missing values")
+	RootCmd.PersistentFlags().StringSliceVar(&missing, "missing", nil, "Values read as missing in every column besides empty ones, e.g. NA,N/A,null,?")
+	RootCmd.PersistentFlags().BoolVar(&strict, "strict", false, "Fail on values that do not convert to their column's type instead of loading them as missing")
+	RootCmd.PersistentFlags().BoolVar(&autoExclude, "auto-exclude", false, "Leave ID, constant and near-unique categorical columns out of training, and warn about possible target leakage")
+	RootCmd.PersistentFlags().BoolVar(&singlePass, "single-pass", false, "Read the input once, inferring column types from the first 10000 rows (always on for -i -)")
+	RootCmd.PersistentFlags().StringVar(&addr, "addr", ":8080", "Address the HTTP prediction server listens on (empty to disable)")
+	RootCmd.PersistentFlags().StringVar(&grpcAddr, "grpc-addr", "", "Address the gRPC prediction service listens on (disabled when empty)")
+	RootCmd.PersistentFlags().Int64Var(&maxBodyBytes, "max-body", server.DefaultMaxBodyBytes, "Largest request body or gRPC message the server accepts, in bytes")
+	RootCmd.PersistentFlags().DurationVar(&reloadInterval, "reload-interval", 2*time.Second, "How often the server checks model files for changes (0 disables reloading)")
 }
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	p "github.com/nyunja/c4.5-decision-tree/internal/model/parser"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
+	"github.com/nyunja/c4.5-decision-tree/internal/source"
+)
+
+// runSchema writes the schema inferred from the input file, as a template
+// to edit and pass back with --schema. It is JSON when the output file ends
+// in .json and YAML otherwise.
+func runSchema() {
+	// check if input file exists
+	if !source.Exists(input) {
+		utils.LogError("missing_input_file")
+	}
+
+	inputOptions := inputOptionsFromFlags()
+	if target == "" {
+		target = inputOptions.Schema.Target()
+	}
+	if target == "" {
+		target = p.DefaultTarget(input)
+	}
+
+	instances, headers, featureTypes, err := p.ParseFile(input, target, inputOptions)
+	if err != nil {
+		log.Fatalf("Error parsing input: %v", err)
+	}
+
+	inferred := inputOptions.Schema.Infer(instances, headers, featureTypes, target, weightColumn)
+	f, err := os.Create(output)
+	if err != nil {
+		log.Fatalf("Error writing schema: %v", err)
+	}
+	defer f.Close()
+
+	schemaFormat := "yaml"
+	if strings.ToLower(filepath.Ext(output)) == ".json" {
+		schemaFormat = "json"
+	}
+	if err := inferred.Write(f, schemaFormat); err != nil {
+		log.Fatalf("Error writing schema: %v", err)
+	}
+	fmt.Printf("Schema of %d columns written to %s\n", len(headers), output)
+}
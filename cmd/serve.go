@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/rpc"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/server"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
+)
+
+// shutdownTimeout is how long in-flight requests may take to finish on shutdown
+const shutdownTimeout = 10 * time.Second
+
+// runServe serves predictions of the models given with -m over HTTP, gRPC
+// or both until the process is interrupted
+func runServe() {
+	if modelFile == "" {
+		utils.LogError("model_file_not_found")
+	}
+	if addr == "" && grpcAddr == "" {
+		log.Fatalf("Error: set --addr, --grpc-addr or both")
+	}
+
+	files := strings.Split(modelFile, ",")
+	for i := range files {
+		files[i] = strings.TrimSpace(files[i])
+	}
+
+	s, err := server.New(files, server.Options{
+		MaxBodyBytes:    maxBodyBytes,
+		ShutdownTimeout: shutdownTimeout,
+	})
+	if err != nil {
+		log.Fatalf("Error loading models: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if reloadInterval > 0 {
+		go s.Watch(ctx, reloadInterval)
+	}
+
+	// Stop both servers when either fails
+	errs := make(chan error, 2)
+	servers := 0
+	if addr != "" {
+		servers++
+		log.Printf("Serving %d model(s) over HTTP on %s", len(files), addr)
+		go func() {
+			errs <- s.ListenAndServe(ctx, addr)
+		}()
+	}
+	if grpcAddr != "" {
+		servers++
+		log.Printf("Serving %d model(s) over gRPC on %s", len(files), grpcAddr)
+		grpcServer := rpc.NewGRPCServer(s, int(maxBodyBytes))
+		go func() {
+			errs <- rpc.ListenAndServe(ctx, grpcServer, grpcAddr, shutdownTimeout)
+		}()
+	}
+
+	failed := false
+	for i := 0; i < servers; i++ {
+		if err := <-errs; err != nil {
+			log.Printf("Error serving: %v", err)
+			failed = true
+			stop()
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+	log.Println("Server stopped")
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/explain"
+	m "github.com/nyunja/c4.5-decision-tree/internal/model/model"
+	p "github.com/nyunja/c4.5-decision-tree/internal/model/parser"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
+	"github.com/nyunja/c4.5-decision-tree/internal/source"
+)
+
+// runSHAP summarises the TreeSHAP values of a saved model over the input
+// file: the mean absolute contribution of every feature to every class
+func runSHAP() {
+	if modelFile == "" {
+		utils.LogError("model_file_not_found")
+	}
+
+	if !source.Exists(input) {
+		utils.LogError("missing_input_file")
+	}
+
+	model, err := m.LoadModel(modelFile)
+	if err != nil {
+		utils.LogError("model_file_not_found")
+	}
+
+	instances, _, _, err := p.ParsePredictionFile(input, model, inputOptionsFromFlags())
+	if err != nil {
+		log.Fatalf("Error parsing input: %v", err)
+	}
+
+	summary := explain.Summarise(model, explain.SHAPBatch(model, instances))
+
+	out := os.Stdout
+	if output != "" {
+		out, err = os.Create(output)
+		if err != nil {
+			log.Fatalf("Error creating output file: %v", err)
+		}
+		defer out.Close()
+	}
+	if err := explain.WriteSummary(out, summary, format); err != nil {
+		log.Fatalf("Error writing SHAP summary: %v", err)
+	}
+	if output != "" {
+		fmt.Printf("SHAP summary saved to %s\n", output)
+	}
+}
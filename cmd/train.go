@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	m "github.com/nyunja/c4.5-decision-tree/internal/model/model"
+	p "github.com/nyunja/c4.5-decision-tree/internal/model/parser"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/split"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
+	"github.com/nyunja/c4.5-decision-tree/internal/profile"
+	"github.com/nyunja/c4.5-decision-tree/internal/source"
+)
+
+// runTrain trains a model on the input file and saves it to the output file
+func runTrain() {
+	inputOptions := inputOptionsFromFlags()
+
+	// Schema files, C4.5 and ARFF datasets declare their target
+	if target == "" {
+		target = inputOptions.Schema.Target()
+	}
+	if target == "" {
+		target = p.DefaultTarget(input)
+	}
+	if target == "" {
+		utils.LogError("target_column_not_found")
+	}
+
+	// check if input file exists
+	if !source.Exists(input) {
+		utils.LogError("missing_input_file")
+	}
+
+	// One seed drives the row sampling and the ensemble draws
+	seed = m.ResolveSeed(seed)
+
+	// parse the CSV file with streaming
+	inputOptions.Seed = seed
+	instances, headers, featureTypes, err := p.ParseTrainingFile(input, target, inputOptions)
+	if err != nil {
+		log.Fatalf("Error parsing input: %v", err)
+	}
+	fmt.Printf("Parsed %d instances with %d features\n", len(instances), len(headers))
+
+	// Check if target column exists
+	if _, ok := featureTypes[target]; !ok {
+		utils.LogError("target_column_not_found")
+	}
+
+	options, err := trainOptionsFromFlags()
+	if err != nil {
+		log.Fatalf("Error in training options: %v", err)
+	}
+	if options.WeightColumn == "" {
+		options.WeightColumn = inputOptions.Schema.Weight()
+	}
+
+	// Few distinct integers are taken for class labels unless a task is given
+	if task == "" || task == "auto" {
+		resolved, err := m.ResolveTask(task, instances, target, featureTypes)
+		if err == nil && resolved == t.Classification && featureTypes[target] == "numerical" {
+			fmt.Printf("Treating the numerical target '%s' as class labels since it holds few distinct integers; pass --task regression to regress it\n", target)
+		}
+	}
+
+	// Columns the schema declares as IDs or ignored are not features
+	var excluded, warnings []t.ColumnNote
+	for _, column := range inputOptions.Schema.Excluded() {
+		excluded = append(excluded, t.ColumnNote{Column: column, Reason: "declared in the schema"})
+	}
+	if autoExclude {
+		excluded, warnings = autoExcludeColumns(instances, headers, featureTypes, options.WeightColumn, excluded)
+	}
+	excludeColumns := make([]string, len(excluded))
+	for i, note := range excluded {
+		excludeColumns[i] = note.Column
+	}
+
+	fmt.Printf("Columns excluded from training: %v\n", excludeColumns)
+
+	// Train the model
+	fmt.Println("Training model...")
+	model, err := trainModel(instances, headers, featureTypes, excludeColumns, options)
+	if err != nil {
+		log.Fatalf("Error training model: %v", err)
+	}
+	fmt.Printf("Model trained successfully (%s)\n", model.Task)
+	if model.Ensemble != nil && model.Ensemble.Method != t.Boost {
+		fmt.Printf("Ensemble of %d trees, out-of-bag error %.4f\n", len(model.Ensemble.Trees), model.Ensemble.OOBError)
+	}
+	if model.Ensemble != nil && model.Ensemble.Method == t.Boost {
+		fmt.Printf("Boosted %d trees, validation error %.4f\n", len(model.Ensemble.Trees), model.Ensemble.ValidationError)
+	}
+
+	model.ExcludedColumns = excluded
+	model.Warnings = warnings
+	model.Reading = inputOptions.Schema.Reading()
+
+	// Save the model
+	fmt.Println("Saving model...")
+	err = m.SaveModel(model, output)
+	if err != nil {
+		utils.LogError("saving_model_error")
+	}
+}
+
+// autoExcludeColumns adds the ID, constant and near-unique categorical
+// columns of the profile of the instances to excluded, logging each one and
+// the columns that may leak the target, which are kept. The target and
+// weight columns are never excluded.
+func autoExcludeColumns(instances []t.Instance, headers []string, featureTypes map[string]string, weight string, excluded []t.ColumnNote) ([]t.ColumnNote, []t.ColumnNote) {
+	report, err := profile.Build(instances, headers, featureTypes, target)
+	if err != nil {
+		log.Fatalf("Error profiling the input: %v", err)
+	}
+
+	seen := make(map[string]bool, len(excluded))
+	for _, note := range excluded {
+		seen[note.Column] = true
+	}
+	detected, warnings := report.Exclusions()
+	for _, note := range detected {
+		if seen[note.Column] || note.Column == target || note.Column == weight {
+			continue
+		}
+		fmt.Printf("Excluding column '%s': %s\n", note.Column, note.Reason)
+		excluded = append(excluded, note)
+	}
+	for _, note := range warnings {
+		fmt.Printf("Warning: column '%s' kept for training: %s\n", note.Column, note.Reason)
+	}
+	return excluded, warnings
+}
+
+// trainModel trains a single tree or the ensemble selected by the flags
+func trainModel(instances []t.Instance, headers []string, featureTypes map[string]string, excludeColumns []string, options m.TrainOptions) (*t.Model, error) {
+	switch ensemble {
+	case "":
+		return m.TrainWithOptions(instances, headers, target, featureTypes, excludeColumns, options)
+	case t.Forest:
+		forest := m.DefaultForestOptions()
+		forest.Trees = trees
+		forest.MaxFeatures = maxFeatures
+		forest.Voting = voting
+		forest.BagSize = bagSize
+		forest.WithoutReplacement = withoutReplacement
+		return m.TrainForest(instances, headers, target, featureTypes, excludeColumns, options, forest)
+	case t.Bag:
+		bagging := m.DefaultForestOptions()
+		bagging.Trees = trees
+		bagging.Voting = voting
+		bagging.BagSize = bagSize
+		bagging.WithoutReplacement = withoutReplacement
+		return m.TrainBagging(instances, headers, target, featureTypes, excludeColumns, options, bagging)
+	case t.Boost:
+		boost := m.DefaultBoostOptions()
+		boost.Rounds = trees
+		boost.MaxDepth = boostDepth
+		boost.ValidationFraction = validationFraction
+		boost.Patience = patience
+		return m.TrainBoosted(instances, headers, target, featureTypes, excludeColumns, options, boost)
+	default:
+		return nil, fmt.Errorf("unknown ensemble '%s', expected forest, bag or boost", ensemble)
+	}
+}
+
+// trainOptionsFromFlags builds the training options from the command line flags
+func trainOptionsFromFlags() (m.TrainOptions, error) {
+	var err error
+	options := m.DefaultTrainOptions()
+
+	options.Split.Criterion, err = split.CriterionByName(criterion)
+	if err != nil {
+		return options, err
+	}
+	options.Split.MDLCorrection = mdlCorrection
+	options.Split.AverageGainFilter = averageGainFilter
+	options.Split.SubsetGrouping = subsetGrouping
+	options.Split.BinaryCategorical = binaryCategorical
+	options.WeightColumn = weightColumn
+	if costMatrix != "" {
+		options.Costs, err = m.LoadCostMatrix(costMatrix)
+		if err != nil {
+			return options, err
+		}
+	}
+
+	options.Seed = seed
+	options.Task = task
+	options.LinearLeaves = linearLeaves
+	options.Split.Regression, err = split.RegressionCriterionByName(regressionCriterion)
+	if err != nil {
+		return options, err
+	}
+
+	return options, nil
+}
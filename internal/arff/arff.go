@@ -0,0 +1,544 @@
+package arff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
+	"github.com/nyunja/c4.5-decision-tree/internal/source"
+)
+
+// Attribute types of an ARFF header
+const (
+	Numeric = "numeric"
+	Nominal = "nominal"
+	String  = "string"
+	Date    = "date"
+)
+
+// DefaultDateFormat is the date format of date attributes that do not declare one
+const DefaultDateFormat = "yyyy-MM-dd'T'HH:mm:ss"
+
+// Attribute is one @attribute declaration
+type Attribute struct {
+	Name   string
+	Type   string
+	Values []string // declared values of nominal attributes
+	Layout string   // Go layout of the date format of date attributes
+	Time   bool     // whether the date format has a time of day
+}
+
+// Header holds the declarations of an ARFF file
+type Header struct {
+	Relation   string
+	Attributes []Attribute
+}
+
+// Load reads the header and instances of an ARFF file, which may be
+// compressed or standard input
+func Load(file string) (*Header, []t.Instance, error) {
+	f, err := source.Open(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening ARFF file: %v", err)
+	}
+	defer f.Close()
+
+	header, instances, err := Read(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing %s: %v", file, err)
+	}
+	return header, instances, nil
+}
+
+// LoadHeader reads the header of an ARFF file
+func LoadHeader(file string) (*Header, error) {
+	f, err := source.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("error opening ARFF file: %v", err)
+	}
+	defer f.Close()
+
+	header, err := newReader(f).header()
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", file, err)
+	}
+	return header, nil
+}
+
+// Read reads the header and instances of ARFF content
+func Read(r io.Reader) (*Header, []t.Instance, error) {
+	reader := newReader(r)
+	header, err := reader.header()
+	if err != nil {
+		return nil, nil, err
+	}
+	instances, err := reader.data(header)
+	if err != nil {
+		return nil, nil, err
+	}
+	return header, instances, nil
+}
+
+// Headers returns the attribute names in declaration order
+func (h *Header) Headers() []string {
+	headers := make([]string, len(h.Attributes))
+	for i, attribute := range h.Attributes {
+		headers[i] = attribute.Name
+	}
+	return headers
+}
+
+// FeatureTypes returns the feature type of every attribute, as declared:
+// numeric attributes are numerical, nominal and string attributes are
+// categorical, and date attributes are dates, or timestamps when their
+// format has a time of day
+func (h *Header) FeatureTypes() map[string]string {
+	featureTypes := make(map[string]string, len(h.Attributes))
+	for _, attribute := range h.Attributes {
+		switch {
+		case attribute.Type == Numeric:
+			featureTypes[attribute.Name] = "numerical"
+		case attribute.Type == Date && attribute.Time:
+			featureTypes[attribute.Name] = "timestamp"
+		case attribute.Type == Date:
+			featureTypes[attribute.Name] = "date"
+		default:
+			featureTypes[attribute.Name] = "categorical"
+		}
+	}
+	return featureTypes
+}
+
+// Target returns the last attribute, which holds the class by convention
+func (h *Header) Target() string {
+	if len(h.Attributes) == 0 {
+		return ""
+	}
+	return h.Attributes[len(h.Attributes)-1].Name
+}
+
+// reader reads the lines of an ARFF file, skipping comments and blank lines
+type reader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newReader(r io.Reader) *reader {
+	scanner := bufio.NewScanner(bufio.NewReaderSize(r, 1<<20))
+	scanner.Buffer(make([]byte, 0, 1<<16), 1<<24)
+	return &reader{scanner: scanner}
+}
+
+// next returns the next line that holds content
+func (r *reader) next() (string, bool) {
+	for r.scanner.Scan() {
+		r.line++
+		line := strings.TrimSpace(r.scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "%") {
+			return line, true
+		}
+	}
+	return "", false
+}
+
+// header reads the declarations up to and including @data
+func (r *reader) header() (*Header, error) {
+	header := &Header{}
+	seen := make(map[string]bool)
+	for {
+		line, ok := r.next()
+		if !ok {
+			if err := r.scanner.Err(); err != nil {
+				return nil, fmt.Errorf("error reading ARFF header: %v", err)
+			}
+			return nil, fmt.Errorf("missing @data section")
+		}
+
+		keyword, rest := splitKeyword(line)
+		switch strings.ToLower(keyword) {
+		case "@relation":
+			name, _, err := nextToken(rest)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", r.line, err)
+			}
+			header.Relation = name
+
+		case "@attribute":
+			attribute, err := parseAttribute(rest)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", r.line, err)
+			}
+			if seen[attribute.Name] {
+				return nil, fmt.Errorf("line %d: attribute '%s' is declared twice", r.line, attribute.Name)
+			}
+			seen[attribute.Name] = true
+			header.Attributes = append(header.Attributes, attribute)
+
+		case "@data":
+			if len(header.Attributes) == 0 {
+				return nil, fmt.Errorf("line %d: no attributes declared", r.line)
+			}
+			return header, nil
+
+		default:
+			return nil, fmt.Errorf("line %d: unexpected '%s' in header", r.line, keyword)
+		}
+	}
+}
+
+// parseAttribute parses the name and type that follow @attribute
+func parseAttribute(declaration string) (Attribute, error) {
+	name, rest, err := nextToken(declaration)
+	if err != nil {
+		return Attribute{}, err
+	}
+	attribute := Attribute{Name: name}
+	rest = strings.TrimSpace(rest)
+
+	if strings.HasPrefix(rest, "{") {
+		end := strings.LastIndex(rest, "}")
+		if end < 0 {
+			return attribute, fmt.Errorf("attribute '%s': unterminated value list", name)
+		}
+		values, err := splitValues(rest[1:end])
+		if err != nil {
+			return attribute, fmt.Errorf("attribute '%s': %v", name, err)
+		}
+		attribute.Type = Nominal
+		for _, value := range values {
+			attribute.Values = append(attribute.Values, value.text)
+		}
+		return attribute, nil
+	}
+
+	kind, format := splitKeyword(rest)
+	switch strings.ToLower(kind) {
+	case "numeric", "real", "integer":
+		attribute.Type = Numeric
+	case "string":
+		attribute.Type = String
+	case "date":
+		attribute.Type = Date
+		if format == "" {
+			format = DefaultDateFormat
+		} else if format, _, err = nextToken(format); err != nil {
+			return attribute, fmt.Errorf("attribute '%s': %v", name, err)
+		}
+		attribute.Layout, attribute.Time, err = dateLayout(format)
+		if err != nil {
+			return attribute, fmt.Errorf("attribute '%s': %v", name, err)
+		}
+	case "relational":
+		return attribute, fmt.Errorf("attribute '%s': relational attributes are not supported", name)
+	default:
+		return attribute, fmt.Errorf("attribute '%s': unknown type '%s'", name, kind)
+	}
+	return attribute, nil
+}
+
+// data reads the instances of the @data section, dense or sparse
+func (r *reader) data(header *Header) ([]t.Instance, error) {
+	var instances []t.Instance
+	for {
+		line, ok := r.next()
+		if !ok {
+			break
+		}
+
+		var instance t.Instance
+		var err error
+		if strings.HasPrefix(line, "{") {
+			instance, err = sparseInstance(line, header)
+		} else {
+			instance, err = denseInstance(line, header)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", r.line, err)
+		}
+		instances = append(instances, instance)
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading ARFF data: %v", err)
+	}
+	return instances, nil
+}
+
+// denseInstance converts a line holding every value in declaration order
+func denseInstance(line string, header *Header) (t.Instance, error) {
+	line = stripWeight(line)
+	values, err := splitValues(line)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != len(header.Attributes) {
+		return nil, fmt.Errorf("expected %d values, got %d", len(header.Attributes), len(values))
+	}
+
+	instance := make(t.Instance, len(header.Attributes))
+	for i, attribute := range header.Attributes {
+		if instance[attribute.Name], err = convertValue(attribute, values[i]); err != nil {
+			return nil, err
+		}
+	}
+	return instance, nil
+}
+
+// sparseInstance converts a line of "index value" pairs. Values left out are
+// 0, which is the first value of nominal attributes and the epoch of date
+// attributes; string attributes left out are missing.
+func sparseInstance(line string, header *Header) (t.Instance, error) {
+	line = stripWeight(line)
+	end := strings.LastIndex(line, "}")
+	if end < 0 {
+		return nil, fmt.Errorf("unterminated sparse instance")
+	}
+
+	instance := make(t.Instance, len(header.Attributes))
+	for _, attribute := range header.Attributes {
+		switch attribute.Type {
+		case Numeric:
+			instance[attribute.Name] = 0.0
+		case Nominal:
+			instance[attribute.Name] = attribute.Values[0]
+		case Date:
+			instance[attribute.Name] = time.Unix(0, 0).UTC()
+		default:
+			instance[attribute.Name] = nil
+		}
+	}
+
+	pairs, err := splitValues(line[1:end])
+	if err != nil {
+		return nil, err
+	}
+	for _, pair := range pairs {
+		if pair.text == "" && !pair.quoted {
+			continue
+		}
+		index, rest := splitKeyword(strings.TrimSpace(pair.raw))
+		position, err := strconv.Atoi(index)
+		if err != nil || position < 0 || position >= len(header.Attributes) {
+			return nil, fmt.Errorf("invalid sparse index '%s'", index)
+		}
+		value, err := parseValue(rest)
+		if err != nil {
+			return nil, err
+		}
+		attribute := header.Attributes[position]
+		if instance[attribute.Name], err = convertValue(attribute, value); err != nil {
+			return nil, err
+		}
+	}
+	return instance, nil
+}
+
+// stripWeight drops the instance weight some files append as a last {w}
+func stripWeight(line string) string {
+	if !strings.HasSuffix(line, "}") {
+		return line
+	}
+	start := strings.LastIndex(line, "{")
+	if start <= 0 || !strings.HasSuffix(strings.TrimSpace(line[:start]), ",") {
+		return line
+	}
+	return strings.TrimSuffix(strings.TrimSpace(line[:start]), ",")
+}
+
+// convertValue converts a value to the type its attribute declares
+func convertValue(attribute Attribute, v value) (interface{}, error) {
+	if v.text == "?" && !v.quoted {
+		return nil, nil
+	}
+
+	switch attribute.Type {
+	case Numeric:
+		number, err := utils.ConvertStringToNumerical(v.text)
+		if err != nil {
+			return nil, fmt.Errorf("attribute '%s' is numeric, got '%s'", attribute.Name, v.text)
+		}
+		return number, nil
+	case Nominal:
+		if !utils.Contains(attribute.Values, v.text) {
+			return nil, fmt.Errorf("attribute '%s' has undeclared value '%s'", attribute.Name, v.text)
+		}
+		return v.text, nil
+	case Date:
+		date, err := time.Parse(attribute.Layout, v.text)
+		if err != nil {
+			return nil, fmt.Errorf("attribute '%s' is a date, got '%s'", attribute.Name, v.text)
+		}
+		return date, nil
+	}
+	return v.text, nil
+}
+
+// value is a value of a line, unquoted, with the text it was read from
+type value struct {
+	text   string
+	raw    string
+	quoted bool
+}
+
+// splitValues splits a comma separated list of values, which may be quoted
+// with single or double quotes and escape characters with a backslash
+func splitValues(line string) ([]value, error) {
+	var values []value
+	for {
+		start := len(line)
+		text, rest, quoted, err := readValue(line)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value{text: text, raw: line[:start-len(rest)], quoted: quoted})
+
+		rest = strings.TrimSpace(rest)
+		if rest == "" {
+			return values, nil
+		}
+		if rest[0] != ',' {
+			return nil, fmt.Errorf("expected ',' before '%s'", rest)
+		}
+		line = rest[1:]
+	}
+}
+
+// readValue reads one value up to the next unquoted comma
+func readValue(line string) (string, string, bool, error) {
+	line = strings.TrimLeft(line, " \t")
+	if line != "" && (line[0] == '\'' || line[0] == '"') {
+		text, rest, err := unquote(line)
+		return text, rest, true, err
+	}
+
+	end := strings.IndexByte(line, ',')
+	if end < 0 {
+		end = len(line)
+	}
+	// Sparse pairs quote the value after the index
+	if quote := strings.IndexAny(line[:end], `'"`); quote > 0 {
+		_, rest, err := unquote(line[quote:])
+		if err != nil {
+			return "", "", false, err
+		}
+		return strings.TrimSpace(line[:len(line)-len(rest)]), rest, false, nil
+	}
+	return strings.TrimSpace(line[:end]), line[end:], false, nil
+}
+
+// parseValue parses the value of a sparse pair
+func parseValue(text string) (value, error) {
+	if text != "" && (text[0] == '\'' || text[0] == '"') {
+		unquoted, _, err := unquote(text)
+		return value{text: unquoted, raw: text, quoted: true}, err
+	}
+	return value{text: text, raw: text}, nil
+}
+
+// unquote reads the quoted string at the start of s and returns the rest of s
+func unquote(s string) (string, string, error) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			default:
+				b.WriteByte(s[i])
+			}
+		case c == quote:
+			return b.String(), s[i+1:], nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", fmt.Errorf("unterminated quote in %s", s)
+}
+
+// nextToken reads a name, which may be quoted, and returns the rest of s
+func nextToken(s string) (string, string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", "", fmt.Errorf("missing name")
+	}
+	if s[0] == '\'' || s[0] == '"' {
+		return unquote(s)
+	}
+	end := strings.IndexAny(s, " \t{")
+	if end < 0 {
+		return s, "", nil
+	}
+	return s[:end], s[end:], nil
+}
+
+// splitKeyword splits the first word of a line from the rest
+func splitKeyword(line string) (string, string) {
+	end := strings.IndexAny(line, " \t")
+	if end < 0 {
+		return line, ""
+	}
+	return line[:end], strings.TrimSpace(line[end:])
+}
+
+// dateLayout converts a Java SimpleDateFormat pattern to a Go layout, and
+// reports whether it has a time of day
+func dateLayout(format string) (string, bool, error) {
+	replacements := []struct{ pattern, layout string }{
+		{"yyyy", "2006"}, {"yy", "06"},
+		{"MMMM", "January"}, {"MMM", "Jan"}, {"MM", "01"}, {"M", "1"},
+		{"dd", "02"}, {"d", "2"},
+		{"EEEE", "Monday"}, {"EEE", "Mon"},
+		{"HH", "15"}, {"H", "15"}, {"hh", "03"}, {"h", "3"},
+		{"mm", "04"}, {"m", "4"}, {"ss", "05"}, {"s", "5"},
+		{"SSS", "000"}, {"a", "PM"},
+		{"XXX", "Z07:00"}, {"X", "Z07"}, {"Z", "-0700"}, {"z", "MST"},
+	}
+
+	var layout strings.Builder
+	hasTime := false
+	for i := 0; i < len(format); {
+		c := format[i]
+		switch {
+		case c == '\'':
+			// Quoted literal text, where '' is a single quote
+			end := strings.IndexByte(format[i+1:], '\'')
+			if end < 0 {
+				return "", false, fmt.Errorf("unterminated quote in date format '%s'", format)
+			}
+			if end == 0 {
+				layout.WriteByte('\'')
+			}
+			layout.WriteString(format[i+1 : i+1+end])
+			i += end + 2
+		case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			matched := false
+			for _, replacement := range replacements {
+				if strings.HasPrefix(format[i:], replacement.pattern) {
+					layout.WriteString(replacement.layout)
+					hasTime = hasTime || strings.ContainsAny(replacement.pattern[:1], "HhmsSa")
+					i += len(replacement.pattern)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return "", false, fmt.Errorf("unsupported date pattern '%c' in '%s'", c, format)
+			}
+		default:
+			layout.WriteByte(c)
+			i++
+		}
+	}
+	return layout.String(), hasTime, nil
+}
@@ -0,0 +1,143 @@
+package arff
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const weather = `% Quinlan's weather data
+@RELATION weather
+
+@attribute outlook {sunny, overcast, 'light rain'}
+@attribute temperature REAL
+@attribute 'relative humidity' integer
+@attribute note string
+@attribute day date yyyy-MM-dd
+@attribute observed date "yyyy-MM-dd HH:mm"
+@attribute play {yes, no}
+
+@data
+sunny, 85, 85, 'hot, dry', 2024-06-01, "2024-06-01 14:30", no
+'light rain', 70.5, ?, "it\'s wet", 2024-06-02, ?, yes
+% a comment between instances
+
+overcast, 64, 65, ?, ?, '2024-06-03 09:00', yes, {0.5}
+`
+
+func TestRead(t *testing.T) {
+	header, instances, err := Read(strings.NewReader(weather))
+	require.NoError(t, err)
+
+	assert.Equal(t, "weather", header.Relation)
+	assert.Equal(t, []string{"outlook", "temperature", "relative humidity", "note", "day", "observed", "play"}, header.Headers())
+	assert.Equal(t, map[string]string{
+		"outlook":           "categorical",
+		"temperature":       "numerical",
+		"relative humidity": "numerical",
+		"note":              "categorical",
+		"day":               "date",
+		"observed":          "timestamp",
+		"play":              "categorical",
+	}, header.FeatureTypes())
+	assert.Equal(t, "play", header.Target())
+	assert.Equal(t, []string{"sunny", "overcast", "light rain"}, header.Attributes[0].Values)
+
+	require.Len(t, instances, 3)
+	assert.Equal(t, "sunny", instances[0]["outlook"])
+	assert.Equal(t, 85.0, instances[0]["temperature"])
+	assert.Equal(t, "hot, dry", instances[0]["note"])
+	assert.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), instances[0]["day"])
+	assert.Equal(t, time.Date(2024, 6, 1, 14, 30, 0, 0, time.UTC), instances[0]["observed"])
+
+	assert.Equal(t, "light rain", instances[1]["outlook"])
+	assert.Nil(t, instances[1]["relative humidity"])
+	assert.Contains(t, instances[1], "relative humidity")
+	assert.Equal(t, "it's wet", instances[1]["note"])
+
+	// The trailing instance weight is not a value
+	assert.Equal(t, "yes", instances[2]["play"])
+	assert.Nil(t, instances[2]["note"])
+}
+
+func TestRead_Sparse(t *testing.T) {
+	content := `@relation words
+@attribute free numeric
+@attribute money numeric
+@attribute sender string
+@attribute label {ham, spam}
+@data
+{1 3, 2 'a b, c', 3 spam}
+{}
+{0 ?, 3 ham}, {2}
+`
+	_, instances, err := Read(strings.NewReader(content))
+	require.NoError(t, err)
+	require.Len(t, instances, 3)
+
+	assert.Equal(t, 0.0, instances[0]["free"])
+	assert.Equal(t, 3.0, instances[0]["money"])
+	assert.Equal(t, "a b, c", instances[0]["sender"])
+	assert.Equal(t, "spam", instances[0]["label"])
+
+	// Values left out are 0, the first nominal value, or missing strings
+	assert.Equal(t, 0.0, instances[1]["money"])
+	assert.Equal(t, "ham", instances[1]["label"])
+	assert.Nil(t, instances[1]["sender"])
+
+	assert.Nil(t, instances[2]["free"])
+}
+
+func TestRead_DefaultDateFormat(t *testing.T) {
+	header, instances, err := Read(strings.NewReader("@relation r\n@attribute at date\n@data\n2024-06-01T08:15:00\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "timestamp", header.FeatureTypes()["at"])
+	assert.Equal(t, time.Date(2024, 6, 1, 8, 15, 0, 0, time.UTC), instances[0]["at"])
+}
+
+func TestRead_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"Missing data", "@relation r\n@attribute a numeric\n"},
+		{"No attributes", "@relation r\n@data\n"},
+		{"Unknown type", "@relation r\n@attribute a complex\n@data\n"},
+		{"Relational", "@relation r\n@attribute bag relational\n@data\n"},
+		{"Duplicate attribute", "@relation r\n@attribute a numeric\n@attribute a numeric\n@data\n"},
+		{"Unsupported date pattern", "@relation r\n@attribute a date 'yyyy-ww'\n@data\n"},
+		{"Wrong value count", "@relation r\n@attribute a numeric\n@attribute b numeric\n@data\n1\n"},
+		{"Not a number", "@relation r\n@attribute a numeric\n@data\nten\n"},
+		{"Undeclared value", "@relation r\n@attribute a {x, y}\n@data\nz\n"},
+		{"Bad date", "@relation r\n@attribute a date yyyy-MM-dd\n@data\n01/06/2024\n"},
+		{"Unterminated quote", "@relation r\n@attribute a string\n@data\n'open\n"},
+		{"Bad sparse index", "@relation r\n@attribute a numeric\n@data\n{4 1}\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := Read(strings.NewReader(tc.content))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "weather.arff")
+	require.NoError(t, os.WriteFile(file, []byte(weather), 0o644))
+
+	header, instances, err := Load(file)
+	require.NoError(t, err)
+	assert.Len(t, instances, 3)
+
+	onlyHeader, err := LoadHeader(file)
+	require.NoError(t, err)
+	assert.Equal(t, header, onlyHeader)
+
+	_, _, err = Load(filepath.Join(t.TempDir(), "missing.arff"))
+	assert.Error(t, err)
+}
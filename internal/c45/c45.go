@@ -0,0 +1,423 @@
+package c45
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
+	"github.com/nyunja/c4.5-decision-tree/internal/source"
+)
+
+// ClassColumn names the class column of .data files whose .names file
+// starts with the list of classes, which C4.5 reads as the last column
+const ClassColumn = "class"
+
+// Attribute kinds of a .names file
+const (
+	Continuous = "continuous"
+	Discrete   = "discrete"
+	Ignore     = "ignore"
+	Label      = "label"
+	Date       = "date"
+	Timestamp  = "timestamp"
+)
+
+// Attribute is one column of the .data files
+type Attribute struct {
+	Name   string
+	Kind   string
+	Values []string // declared values of discrete attributes, empty when any value is allowed
+}
+
+// Schema holds the declarations of a .names file
+type Schema struct {
+	Target     string
+	Classes    []string    // declared classes, or the values of a discrete target attribute
+	Attributes []Attribute // columns of the .data files, in order
+}
+
+// NamesFile returns the .names file describing a .names, .data or .test
+// file, which may be compressed
+func NamesFile(file string) string {
+	file = source.BaseName(file)
+	return strings.TrimSuffix(file, filepath.Ext(file)) + ".names"
+}
+
+// DataFile returns the file holding the cases of file: the .data file next
+// to a .names file, or the file itself
+func DataFile(file string) string {
+	if filepath.Ext(file) == ".names" {
+		return strings.TrimSuffix(file, ".names") + ".data"
+	}
+	return file
+}
+
+// ReadNames reads a .names file
+func ReadNames(file string) (*Schema, error) {
+	f, err := source.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("error opening names file: %v", err)
+	}
+	defer f.Close()
+
+	schema, err := ParseNames(f)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", file, err)
+	}
+	return schema, nil
+}
+
+// ParseNames parses .names declarations. The first entry lists the classes,
+// which then make up the last column of the cases, or names the attribute
+// to predict. Every other entry declares an attribute, in column order, as
+// continuous, discrete with a list of values, "discrete N", ignore, label,
+// date or timestamp. Entries end with a period, "|" starts a comment and "\"
+// escapes the next character.
+func ParseNames(r io.Reader) (*Schema, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	entries := splitEntries(string(content))
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no class declaration")
+	}
+
+	schema := &Schema{}
+	seen := make(map[string]bool)
+	for _, entry := range entries[1:] {
+		name, declaration, ok := cut(entry, ':')
+		if !ok {
+			return nil, fmt.Errorf("expected 'name: type' declaration, got '%s'", entry)
+		}
+		if strings.HasPrefix(declaration, "=") {
+			return nil, fmt.Errorf("defined attributes are not supported: '%s'", entry)
+		}
+		name = unescape(name)
+		if name == "" {
+			return nil, fmt.Errorf("attribute without a name: '%s'", entry)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("attribute '%s' is declared twice", name)
+		}
+		seen[name] = true
+
+		attribute, err := parseAttribute(name, declaration)
+		if err != nil {
+			return nil, err
+		}
+		schema.Attributes = append(schema.Attributes, attribute)
+	}
+
+	// A single class that names an attribute makes that attribute the target
+	classes := splitList(entries[0], ',')
+	if len(classes) == 1 && seen[classes[0]] {
+		schema.Target = classes[0]
+		for _, attribute := range schema.Attributes {
+			if attribute.Name != schema.Target {
+				continue
+			}
+			if attribute.Kind == Ignore || attribute.Kind == Label {
+				return nil, fmt.Errorf("target attribute '%s' is declared %s", attribute.Name, attribute.Kind)
+			}
+			schema.Classes = attribute.Values
+		}
+		return schema, nil
+	}
+
+	if seen[ClassColumn] {
+		return nil, fmt.Errorf("attribute '%s' clashes with the class column", ClassColumn)
+	}
+	for _, class := range classes {
+		if class == "" {
+			return nil, fmt.Errorf("empty class in '%s'", entries[0])
+		}
+	}
+	schema.Target = ClassColumn
+	schema.Classes = classes
+	schema.Attributes = append(schema.Attributes, Attribute{Name: ClassColumn, Kind: Discrete, Values: classes})
+	return schema, nil
+}
+
+// parseAttribute parses the declaration of an attribute
+func parseAttribute(name, declaration string) (Attribute, error) {
+	attribute := Attribute{Name: name}
+	keyword := strings.ToLower(strings.TrimSpace(declaration))
+
+	switch {
+	case keyword == Continuous:
+		attribute.Kind = Continuous
+	case keyword == Ignore:
+		attribute.Kind = Ignore
+	case keyword == Label:
+		attribute.Kind = Label
+	case keyword == Date:
+		attribute.Kind = Date
+	case keyword == Timestamp:
+		attribute.Kind = Timestamp
+	case strings.HasPrefix(keyword, Discrete+" "):
+		// discrete N allows any of at most N values, collected from the data
+		if _, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(keyword, Discrete))); err != nil {
+			return attribute, fmt.Errorf("attribute '%s': invalid declaration '%s'", name, declaration)
+		}
+		attribute.Kind = Discrete
+	default:
+		attribute.Kind = Discrete
+		attribute.Values = splitList(declaration, ',')
+		for _, value := range attribute.Values {
+			if value == "" {
+				return attribute, fmt.Errorf("attribute '%s': empty value in '%s'", name, declaration)
+			}
+		}
+	}
+	return attribute, nil
+}
+
+// FeatureType returns the feature type the trees use for an attribute kind
+func FeatureType(kind string) string {
+	switch kind {
+	case Continuous:
+		return "numerical"
+	case Date:
+		return "date"
+	case Timestamp:
+		return "timestamp"
+	}
+	return "categorical"
+}
+
+// Headers returns the attributes instances hold, in column order. Ignored
+// and label attributes are left out.
+func (s *Schema) Headers() []string {
+	var headers []string
+	for _, attribute := range s.Attributes {
+		if attribute.Kind != Ignore && attribute.Kind != Label {
+			headers = append(headers, attribute.Name)
+		}
+	}
+	return headers
+}
+
+// FeatureTypes returns the type of every attribute instances hold
+func (s *Schema) FeatureTypes() map[string]string {
+	featureTypes := make(map[string]string)
+	for _, attribute := range s.Attributes {
+		if attribute.Kind != Ignore && attribute.Kind != Label {
+			featureTypes[attribute.Name] = FeatureType(attribute.Kind)
+		}
+	}
+	return featureTypes
+}
+
+// LoadData reads the cases of a .data or .test file, which may be
+// compressed or standard input
+func LoadData(file string, schema *Schema) ([]t.Instance, error) {
+	f, err := source.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("error opening data file: %v", err)
+	}
+	defer f.Close()
+
+	instances, err := ReadData(f, schema)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", file, err)
+	}
+	return instances, nil
+}
+
+// ReadData reads one case per line, with values separated by commas in the
+// column order of the schema. "?" and "N/A" are missing values. Cases may
+// leave out a target in the last column, as cases to be classified do.
+func ReadData(r io.Reader, schema *Schema) ([]t.Instance, error) {
+	scanner := bufio.NewScanner(bufio.NewReaderSize(r, 1<<20))
+	scanner.Buffer(make([]byte, 0, 1<<16), 1<<24)
+
+	last := schema.Attributes[len(schema.Attributes)-1].Name
+	var instances []t.Instance
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(stripComment(scanner.Text()))
+		if text == "" {
+			continue
+		}
+		// Cases may end with a period, as those of the UCI .test files do
+		if strings.HasSuffix(text, ".") && !strings.HasSuffix(text, `\.`) {
+			text = text[:len(text)-1]
+		}
+
+		values := splitList(text, ',')
+		if len(values) != len(schema.Attributes) && !(len(values) == len(schema.Attributes)-1 && last == schema.Target) {
+			return nil, fmt.Errorf("line %d: expected %d values, got %d", line, len(schema.Attributes), len(values))
+		}
+
+		instance := make(t.Instance, len(schema.Attributes))
+		for i, attribute := range schema.Attributes {
+			if attribute.Kind == Ignore || attribute.Kind == Label {
+				continue
+			}
+			if i >= len(values) {
+				instance[attribute.Name] = nil
+				continue
+			}
+			value, err := convertValue(attribute, values[i])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+			instance[attribute.Name] = value
+		}
+		instances = append(instances, instance)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading data: %v", err)
+	}
+	return instances, nil
+}
+
+// convertValue converts a value of a case to the type of its attribute
+func convertValue(attribute Attribute, value string) (interface{}, error) {
+	if value == "?" || value == "N/A" {
+		return nil, nil
+	}
+
+	switch attribute.Kind {
+	case Continuous:
+		number, err := utils.ConvertStringToNumerical(value)
+		if err != nil {
+			return nil, fmt.Errorf("attribute '%s' is continuous, got '%s'", attribute.Name, value)
+		}
+		return number, nil
+	case Date:
+		date, err := utils.ConvertStringToDate(value)
+		if err != nil {
+			return nil, fmt.Errorf("attribute '%s' is a date, got '%s'", attribute.Name, value)
+		}
+		return *date, nil
+	case Timestamp:
+		timestamp, err := parseTimestamp(value)
+		if err != nil {
+			return nil, fmt.Errorf("attribute '%s' is a timestamp, got '%s'", attribute.Name, value)
+		}
+		return timestamp, nil
+	}
+
+	if len(attribute.Values) > 0 && !utils.Contains(attribute.Values, value) {
+		return nil, fmt.Errorf("attribute '%s' has undeclared value '%s'", attribute.Name, value)
+	}
+	return value, nil
+}
+
+// parseTimestamp parses the YYYY/MM/DD HH:MM:SS timestamps of .data files,
+// or the timestamp formats of CSV files
+func parseTimestamp(value string) (time.Time, error) {
+	for _, layout := range []string{"2006/01/02 15:04:05", "2006/01/02 15:04"} {
+		if timestamp, err := time.Parse(layout, value); err == nil {
+			return timestamp, nil
+		}
+	}
+	timestamp, err := utils.ConvertStringToTimestamp(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return *timestamp, nil
+}
+
+// splitEntries splits .names content into its period terminated entries,
+// dropping comments. A period only ends an entry when followed by white
+// space, so values such as 1.5 are kept whole. Escapes are kept for the
+// entries to be split further.
+func splitEntries(content string) []string {
+	var entries []string
+	var entry strings.Builder
+	flush := func() {
+		if text := strings.TrimSpace(entry.String()); text != "" {
+			entries = append(entries, text)
+		}
+		entry.Reset()
+	}
+
+	for i := 0; i < len(content); i++ {
+		switch c := content[i]; {
+		case c == '\\' && i+1 < len(content):
+			entry.WriteByte(c)
+			entry.WriteByte(content[i+1])
+			i++
+		case c == '|':
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+			entry.WriteByte(' ')
+		case c == '.' && (i+1 == len(content) || isSpace(content[i+1])):
+			flush()
+		default:
+			entry.WriteByte(c)
+		}
+	}
+	flush()
+	return entries
+}
+
+// stripComment drops the comment at the end of a line
+func stripComment(line string) string {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '|':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// cut splits s around the first unescaped sep
+func cut(s string, sep byte) (string, string, bool) {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			return s[:i], s[i+1:], true
+		}
+	}
+	return s, "", false
+}
+
+// splitList splits s on unescaped sep, unescaping and trimming every item
+func splitList(s string, sep byte) []string {
+	var items []string
+	for {
+		item, rest, ok := cut(s, sep)
+		items = append(items, unescape(item))
+		if !ok {
+			return items
+		}
+		s = rest
+	}
+}
+
+// unescape removes the escapes of s and the white space around it
+func unescape(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,163 @@
+package c45
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const golfNames = `| Quinlan's golf dataset
+Play, Don't Play.
+
+outlook: sunny, overcast, rain.
+temperature: continuous.
+humidity: continuous.   | percent
+windy: true, false.
+day: ignore.
+`
+
+func TestParseNames(t *testing.T) {
+	schema, err := ParseNames(strings.NewReader(golfNames))
+	require.NoError(t, err)
+
+	assert.Equal(t, ClassColumn, schema.Target)
+	assert.Equal(t, []string{"Play", "Don't Play"}, schema.Classes)
+	assert.Equal(t, []string{"outlook", "temperature", "humidity", "windy", "class"}, schema.Headers())
+	assert.Equal(t, map[string]string{
+		"outlook":     "categorical",
+		"temperature": "numerical",
+		"humidity":    "numerical",
+		"windy":       "categorical",
+		"class":       "categorical",
+	}, schema.FeatureTypes())
+	assert.Equal(t, []string{"sunny", "overcast", "rain"}, schema.Attributes[0].Values)
+	assert.Equal(t, Ignore, schema.Attributes[4].Kind)
+}
+
+func TestParseNames_TargetAttribute(t *testing.T) {
+	names := `price.   | predict the price
+
+id:       label.
+sold:     date.
+area:     continuous.
+district: discrete 20.
+version:  1.5, 2.0.
+price:    continuous.
+`
+	schema, err := ParseNames(strings.NewReader(names))
+	require.NoError(t, err)
+
+	assert.Equal(t, "price", schema.Target)
+	assert.Empty(t, schema.Classes)
+	assert.Equal(t, []string{"sold", "area", "district", "version", "price"}, schema.Headers())
+	assert.Equal(t, "date", schema.FeatureTypes()["sold"])
+	assert.Equal(t, "numerical", schema.FeatureTypes()["price"])
+	assert.Empty(t, schema.Attributes[3].Values)
+	assert.Equal(t, []string{"1.5", "2.0"}, schema.Attributes[4].Values)
+}
+
+func TestParseNames_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		names string
+	}{
+		{"Empty", "| nothing here\n"},
+		{"Missing type", "yes, no.\nage.\n"},
+		{"Duplicate attribute", "yes, no.\nage: continuous.\nage: continuous.\n"},
+		{"Defined attribute", "yes, no.\nage: continuous.\nold := age > 60.\n"},
+		{"Invalid discrete", "yes, no.\ncolour: discrete many.\n"},
+		{"Ignored target", "id.\nid: ignore.\n"},
+		{"Class clash", "yes, no.\nclass: a, b.\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseNames(strings.NewReader(tc.names))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestReadData(t *testing.T) {
+	schema, err := ParseNames(strings.NewReader(golfNames))
+	require.NoError(t, err)
+
+	data := `sunny, 85, 85, false, d1, Don't Play
+overcast, 83, ?, false, d2, Play   | humidity was not recorded
+
+rain, 70.5, 96, true, d3, Play.
+sunny, 72, 95, false, d4
+`
+	instances, err := ReadData(strings.NewReader(data), schema)
+	require.NoError(t, err)
+	require.Len(t, instances, 4)
+
+	assert.Equal(t, "sunny", instances[0]["outlook"])
+	assert.Equal(t, 85.0, instances[0]["temperature"])
+	assert.Equal(t, "Don't Play", instances[0]["class"])
+	assert.NotContains(t, instances[0], "day")
+
+	assert.Nil(t, instances[1]["humidity"])
+	assert.Contains(t, instances[1], "humidity")
+	assert.Equal(t, 70.5, instances[2]["temperature"])
+	assert.Equal(t, "Play", instances[2]["class"])
+
+	// Cases to classify may leave out the class
+	assert.Nil(t, instances[3]["class"])
+}
+
+func TestReadData_TypedValues(t *testing.T) {
+	schema, err := ParseNames(strings.NewReader("y.\nday: date.\nat: timestamp.\ny: continuous.\n"))
+	require.NoError(t, err)
+
+	instances, err := ReadData(strings.NewReader("2024/03/01, 2024/03/01 10:30:00, 4\n"), schema)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), instances[0]["day"])
+	assert.Equal(t, time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC), instances[0]["at"])
+}
+
+func TestReadData_Errors(t *testing.T) {
+	schema, err := ParseNames(strings.NewReader(golfNames))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"Too few values", "sunny, 85, 85\n"},
+		{"Too many values", "sunny, 85, 85, false, d1, Play, extra\n"},
+		{"Not a number", "sunny, hot, 85, false, d1, Play\n"},
+		{"Undeclared value", "foggy, 85, 85, false, d1, Play\n"},
+		{"Undeclared class", "sunny, 85, 85, false, d1, Maybe\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ReadData(strings.NewReader(tc.data), schema)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFiles(t *testing.T) {
+	assert.Equal(t, "data/golf.names", NamesFile("data/golf.test"))
+	assert.Equal(t, "data/golf.names", NamesFile("data/golf.names"))
+	assert.Equal(t, "data/golf.data", DataFile("data/golf.names"))
+	assert.Equal(t, "data/golf.test", DataFile("data/golf.test"))
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "golf.names"), []byte(golfNames), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "golf.data"), []byte("rain, 70, 96, true, d3, Play\n"), 0o644))
+
+	schema, err := ReadNames(NamesFile(filepath.Join(dir, "golf.data")))
+	require.NoError(t, err)
+	instances, err := LoadData(filepath.Join(dir, "golf.data"), schema)
+	require.NoError(t, err)
+	assert.Len(t, instances, 1)
+
+	_, err = LoadData(filepath.Join(dir, "golf.test"), schema)
+	assert.Error(t, err)
+}
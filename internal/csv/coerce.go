@@ -0,0 +1,113 @@
+package csv
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
+)
+
+// coercionSamples is the number of failed values kept per column
+const coercionSamples = 5
+
+// CoercionSample is a value that failed to convert, with where it was read
+type CoercionSample struct {
+	Row   int // row of the dataset, from 1
+	Line  int // line of the file the row started on, 0 when unknown
+	Value string
+}
+
+// ColumnCoercions counts the values of a column that failed to convert to
+// its type
+type ColumnCoercions struct {
+	Column  string
+	Type    string
+	Failed  int
+	Samples []CoercionSample // the first values that failed
+}
+
+// Coercions converts records to instances and reports, per column, the
+// values that do not convert to the column's type. They are loaded as
+// missing values, or stop loading in strict mode.
+type Coercions struct {
+	Strict  bool
+	columns map[string]*ColumnCoercions
+}
+
+// NewCoercions returns an empty report
+func NewCoercions(strict bool) *Coercions {
+	return &Coercions{Strict: strict, columns: make(map[string]*ColumnCoercions)}
+}
+
+// Convert converts a record to an instance, recording the values that fail.
+// In strict mode the first failure is an error.
+func (c *Coercions) Convert(record []string, headers []string, featureTypes map[string]string, row, line int) (t.Instance, error) {
+	instance := make(t.Instance, len(headers))
+	for i, value := range record {
+		header := headers[i]
+		converted, ok := utils.ConvertValue(value, featureTypes[header])
+		if !ok {
+			c.add(header, featureTypes[header], CoercionSample{Row: row, Line: line, Value: value})
+			if c.Strict {
+				return nil, fmt.Errorf("%s: '%s' in column '%s' is not a valid %s value (strict mode)", where(row, line), value, header, featureTypes[header])
+			}
+		}
+		instance[header] = converted
+	}
+	return instance, nil
+}
+
+func (c *Coercions) add(column, featureType string, sample CoercionSample) {
+	coercions, ok := c.columns[column]
+	if !ok {
+		coercions = &ColumnCoercions{Column: column, Type: featureType}
+		c.columns[column] = coercions
+	}
+	coercions.Failed++
+	if len(coercions.Samples) < coercionSamples {
+		coercions.Samples = append(coercions.Samples, sample)
+	}
+}
+
+// Failed returns the number of values that failed to convert
+func (c *Coercions) Failed() int {
+	failed := 0
+	for _, coercions := range c.columns {
+		failed += coercions.Failed
+	}
+	return failed
+}
+
+// Columns returns the columns with values that failed to convert, by name
+func (c *Coercions) Columns() []ColumnCoercions {
+	columns := make([]ColumnCoercions, 0, len(c.columns))
+	for _, coercions := range c.columns {
+		columns = append(columns, *coercions)
+	}
+	sort.Slice(columns, func(i, j int) bool { return columns[i].Column < columns[j].Column })
+	return columns
+}
+
+// String formats the report, one line per column
+func (c *Coercions) String() string {
+	var report strings.Builder
+	fmt.Fprintf(&report, "%d values failed to convert and were loaded as missing:\n", c.Failed())
+	for _, coercions := range c.Columns() {
+		samples := make([]string, len(coercions.Samples))
+		for i, sample := range coercions.Samples {
+			samples[i] = fmt.Sprintf("%s: %q", where(sample.Row, sample.Line), sample.Value)
+		}
+		fmt.Fprintf(&report, "  %s (%s): %d, e.g. %s\n", coercions.Column, coercions.Type, coercions.Failed, strings.Join(samples, ", "))
+	}
+	return report.String()
+}
+
+// where names the row and, when known, the line of a value
+func where(row, line int) string {
+	if line > 0 {
+		return fmt.Sprintf("line %d (row %d)", line, row)
+	}
+	return fmt.Sprintf("row %d", row)
+}
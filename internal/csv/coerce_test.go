@@ -0,0 +1,61 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const spending = `zip,spend,joined,label
+02139,10,2024-01-03,yes
+10001,,2024-01-15,no
+02139,twelve,2024-02-20,yes
+94105,3,not a date,no
+10001,5,2024-03-11,
+`
+
+var spendingTypes = map[string]string{"zip": "categorical", "spend": "numerical", "joined": "date", "label": "categorical"}
+
+func spendingRecords(tt *testing.T) (RecordReader, []string) {
+	dialect := DefaultDialect()
+	reader := NewCSVReader(strings.NewReader(spending), dialect)
+	headers, err := ReadCSVHeaders(reader, dialect)
+	require.NoError(tt, err)
+	return reader, headers
+}
+
+func TestLoadRecordInstances_Coercions(tt *testing.T) {
+	records, headers := spendingRecords(tt)
+	coercions := NewCoercions(false)
+
+	instances, err := LoadRecordInstances(records, headers, spendingTypes, "label", 5, 100, nil, coercions)
+	require.NoError(tt, err)
+	// The row without a label is dropped
+	require.Len(tt, instances, 4)
+	assert.Equal(tt, 10.0, instances[0]["spend"])
+	assert.Equal(tt, time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), instances[0]["joined"])
+	assert.Equal(tt, "02139", instances[0]["zip"])
+	// Empty values and values that fail to convert are missing
+	assert.Nil(tt, instances[1]["spend"])
+	assert.Nil(tt, instances[2]["spend"])
+	assert.Nil(tt, instances[3]["joined"])
+
+	assert.Equal(tt, 2, coercions.Failed())
+	assert.Equal(tt, []ColumnCoercions{
+		{Column: "joined", Type: "date", Failed: 1, Samples: []CoercionSample{{Row: 4, Line: 5, Value: "not a date"}}},
+		{Column: "spend", Type: "numerical", Failed: 1, Samples: []CoercionSample{{Row: 3, Line: 4, Value: "twelve"}}},
+	}, coercions.Columns())
+	assert.Contains(tt, coercions.String(), `spend (numerical): 1, e.g. line 4 (row 3): "twelve"`)
+}
+
+func TestLoadPredictionRecordInstances_Strict(tt *testing.T) {
+	records, headers := spendingRecords(tt)
+
+	_, err := LoadPredictionRecordInstances(records, headers, spendingTypes, "label", 5, 100, NewCoercions(true))
+	require.Error(tt, err)
+	assert.Contains(tt, err.Error(), "line 4 (row 3)")
+	assert.Contains(tt, err.Error(), "'spend'")
+}
@@ -1,47 +1,53 @@
 package csv
 
 import (
-	"bufio"
-	"encoding/csv"
 	"fmt"
 	"io"
 	"math"
 	"math/rand/v2"
-	"os"
 	"strconv"
 
 	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
 	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
+	"github.com/nyunja/c4.5-decision-tree/internal/source"
 )
 
-// openCSVFile opens a CSV file and creates a buffered reader
-func OpenCSVFile(file string) (*os.File, *csv.Reader, error) {
-	f, err := os.Open(file)
+// openCSVFile opens a CSV file, which may be compressed or standard input,
+// and creates a buffered reader
+func OpenCSVFile(file string, dialect Dialect) (io.ReadCloser, RecordReader, error) {
+	f, err := source.Open(file)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error opening file: %v", err)
+		return nil, nil, err
 	}
 
-	// Use buffered reader for better performance
-	reader := bufio.NewReaderSize(f, 1<<20) // 1MB buffer
-	csvReader := csv.NewReader(reader)
-	csvReader.ReuseRecord = true // Reuse record buffer for better performance
-
-	return f, csvReader, nil
+	return f, NewCSVReader(f, dialect), nil
 }
 
-// readCSVHeaders reads headers from CSV file
-func ReadCSVHeaders(csvReader *csv.Reader, hasHeader bool) ([]string, error) {
-	var headers []string
+// readCSVHeaders reads headers from CSV file. Headerless files take the
+// dialect's column names, or col0, col1, ... for every field of the first row.
+func ReadCSVHeaders(csvReader RecordReader, dialect Dialect) ([]string, error) {
+	record, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV header: %v", err)
+	}
 
-	if hasHeader {
-		record, err := csvReader.Read()
-		if err != nil {
-			return nil, fmt.Errorf("error reading CSV header: %v", err)
-		}
-		headers = make([]string, len(record))
+	if !dialect.NoHeader {
+		headers := make([]string, len(record))
 		copy(headers, record)
+		return headers, nil
 	}
 
+	if len(dialect.Columns) > 0 {
+		if len(dialect.Columns) != len(record) {
+			return nil, fmt.Errorf("%d column names given for %d columns", len(dialect.Columns), len(record))
+		}
+		return dialect.Columns, nil
+	}
+
+	headers := make([]string, len(record))
+	for i := range record {
+		headers[i] = fmt.Sprintf("col%d", i)
+	}
 	return headers, nil
 }
 
@@ -55,6 +61,8 @@ func InitializeColumnStats(headers []string) map[string]*t.ColumnStats {
 			Max:          -math.MaxFloat64,
 			UniqueValues: make(map[string]int),
 			IsNumeric:    true,
+			IsInteger:    true,
+			Integers:     make(map[int64]bool),
 			IsDate:       true,
 			IsTimestamp:  true,
 		}
@@ -63,40 +71,82 @@ func InitializeColumnStats(headers []string) map[string]*t.ColumnStats {
 	return columnStats
 }
 
-// collectDatasetStatistics performs the first pass through the data to gather statistics
-func CollectDatasetStatistics(f *os.File, headers []string, hasHeader bool) (*t.DatasetStats, error) {
-	// Reset file to beginning
-	f.Seek(0, 0)
-	reader := bufio.NewReaderSize(f, 1<<20)
-	csvReader := csv.NewReader(reader)
-	csvReader.ReuseRecord = true
+// RecordReader reads the rows of a dataset as records of values in header
+// order, with empty values for missing ones. *csv.Reader is a RecordReader.
+type RecordReader interface {
+	Read() ([]string, error)
+}
+
+// TextReader is implemented by RecordReaders of typed formats to report
+// whether the value in a column of the last record was written as text.
+// Text values are never taken for numbers.
+type TextReader interface {
+	IsText(column int) bool
+}
+
+// LineReader is implemented by RecordReaders that know the line of the file
+// the last record started on, for error reports
+type LineReader interface {
+	Line() int
+}
+
+// SampleSize is the number of rows column types are determined from
+const SampleSize = 10000
+
+// MaxUniqueValues is the most distinct values counted per column
+const MaxUniqueValues = 1000
+
+// OpenCSVRecords opens a CSV file and skips its header, if present, so the
+// reader returns the records of the rows
+func OpenCSVRecords(file string, dialect Dialect) (io.ReadCloser, RecordReader, error) {
+	f, csvReader, err := OpenCSVFile(file, dialect)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// Skip header if present
-	if hasHeader {
+	if !dialect.NoHeader {
 		_, err := csvReader.Read()
 		if err != nil {
-			return nil, fmt.Errorf("error skipping CSV header: %v", err)
+			f.Close()
+			return nil, nil, fmt.Errorf("error skipping CSV header: %v", err)
 		}
 	}
+	return f, csvReader, nil
+}
 
+// collectDatasetStatistics performs the first pass through the data to gather statistics
+func CollectDatasetStatistics(file string, headers []string, dialect Dialect) (*t.DatasetStats, error) {
+	// Open the file again from the beginning
+	f, csvReader, err := OpenCSVRecords(file, dialect)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return CollectRecordStatistics(csvReader, headers)
+}
+
+// CollectRecordStatistics gathers the statistics of the records of any format
+func CollectRecordStatistics(records RecordReader, headers []string) (*t.DatasetStats, error) {
 	stats := &t.DatasetStats{
 		ColumnStats: make(map[string]*t.ColumnStats),
 	}
 
 	// Initialize column stats
 	stats.ColumnStats = InitializeColumnStats(headers)
+	texts, _ := records.(TextReader)
 
 	// Sample the data to determine column types
-	sampleSize := 10000
 	sampleCount := 0
 
 	for {
-		record, err := csvReader.Read()
+		record, err := records.Read()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return nil, fmt.Errorf("error reading CSV record: %v", err)
+			return nil, fmt.Errorf("error reading record: %v", err)
 		}
 
 		if len(headers) == 0 {
@@ -111,7 +161,14 @@ func CollectDatasetStatistics(f *os.File, headers []string, hasHeader bool) (*t.
 		stats.RowCount++
 
 		// Only sample a subset of rows for type detection
-		if sampleCount < sampleSize {
+		if sampleCount < SampleSize {
+			if texts != nil {
+				for i, value := range record {
+					if value != "" && texts.IsText(i) {
+						stats.ColumnStats[headers[i]].IsNumeric = false
+					}
+				}
+			}
 			UpdateColumnStatistics(record, headers, stats.ColumnStats)
 			sampleCount++
 		}
@@ -120,19 +177,96 @@ func CollectDatasetStatistics(f *os.File, headers []string, hasHeader bool) (*t.
 	return stats, nil
 }
 
+// CollectHeadStatistics gathers the statistics of the first SampleSize
+// records in a single pass, for streams that cannot be read twice. It
+// returns a reader that replays the sampled records before the rest.
+func CollectHeadStatistics(records RecordReader, headers []string) (*t.DatasetStats, RecordReader, error) {
+	head := &headReader{rest: records}
+	texts, _ := records.(TextReader)
+	for len(head.records) < SampleSize {
+		record, err := records.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading record: %v", err)
+		}
+		head.records = append(head.records, append([]string(nil), record...))
+		if lines, ok := records.(LineReader); ok {
+			head.lines = append(head.lines, lines.Line())
+		}
+		if texts != nil {
+			text := make([]bool, len(record))
+			for i := range record {
+				text[i] = texts.IsText(i)
+			}
+			head.texts = append(head.texts, text)
+		}
+	}
+
+	stats, err := CollectRecordStatistics(&headReader{records: head.records, texts: head.texts}, headers)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stats, head, nil
+}
+
+// headReader replays buffered records, then reads the rest of a stream
+type headReader struct {
+	records [][]string
+	texts   [][]bool
+	lines   []int
+	rest    RecordReader
+	next    int
+}
+
+func (r *headReader) Read() ([]string, error) {
+	if r.next < len(r.records) {
+		r.next++
+		return r.records[r.next-1], nil
+	}
+	if r.rest == nil {
+		return nil, io.EOF
+	}
+	r.next = len(r.records) + 1
+	return r.rest.Read()
+}
+
+func (r *headReader) Line() int {
+	if r.next <= len(r.lines) {
+		return r.lines[r.next-1]
+	}
+	if lines, ok := r.rest.(LineReader); ok && r.next > len(r.records) {
+		return lines.Line()
+	}
+	return 0
+}
+
+func (r *headReader) IsText(column int) bool {
+	if r.next <= len(r.texts) {
+		return r.texts[r.next-1][column]
+	}
+	if texts, ok := r.rest.(TextReader); ok && r.next > len(r.records) {
+		return texts.IsText(column)
+	}
+	return false
+}
+
 // updateColumnStatistics analyzes a single row and updates column statistics
 func UpdateColumnStatistics(record []string, headers []string, columnStats map[string]*t.ColumnStats) {
 	for i, value := range record {
 		header := headers[i]
 		colStats := columnStats[header]
 
-		// Skip empty values
+		// Count and skip empty values
 		if value == "" {
+			colStats.Missing++
 			continue
 		}
 
-		// Update unique values (limit to 1000 unique values for memory efficiency)
-		if len(colStats.UniqueValues) < 1000 {
+		// Update unique values (limit to 1000 unique values for memory
+		// efficiency), still counting the values already kept
+		if _, ok := colStats.UniqueValues[value]; ok || len(colStats.UniqueValues) < MaxUniqueValues {
 			colStats.UniqueValues[value]++
 		}
 
@@ -150,6 +284,9 @@ func UpdateColumnStatistics(record []string, headers []string, columnStats map[s
 				if floatVal > colStats.Max {
 					colStats.Max = floatVal
 				}
+				if colStats.IsInteger {
+					updateIntegers(colStats, floatVal)
+				}
 			}
 		}
 
@@ -165,6 +302,17 @@ func UpdateColumnStatistics(record []string, headers []string, columnStats map[s
 	}
 }
 
+// updateIntegers records a numeric value in the distinct integers of a
+// column, or drops them once the column holds a value that is not whole
+func updateIntegers(colStats *t.ColumnStats, value float64) {
+	if value != math.Trunc(value) || math.Abs(value) > 1<<53 {
+		colStats.IsInteger = false
+		colStats.Integers = nil
+		return
+	}
+	colStats.Integers[int64(value)] = true
+}
+
 // determineColumnTypes analyzes statistics to determine the type of each column
 func DetermineColumnTypes(stats *t.DatasetStats) map[string]string {
 	featureTypes := make(map[string]string)
@@ -185,28 +333,31 @@ func DetermineColumnTypes(stats *t.DatasetStats) map[string]string {
 	return featureTypes
 }
 
-// loadInstances performs the second pass through the data to load instances
+// loadInstances performs the second pass through the data to load instances.
+// Rows of very large files are sampled with sampler, or the global source when it is nil.
 func LoadInstances(file string, headers []string, featureTypes map[string]string,
-	targetColumn string, totalRows int, chunkSize int, hasHeader bool,
+	targetColumn string, totalRows int, chunkSize int, dialect Dialect, sampler *rand.Rand, coercions *Coercions,
 ) ([]t.Instance, error) {
 	// Open file again for second pass
-	f, err := os.Open(file)
+	f, csvReader, err := OpenCSVRecords(file, dialect)
 	if err != nil {
-		return nil, fmt.Errorf("error opening file: %v", err)
+		return nil, err
 	}
 	defer f.Close()
 
-	reader := bufio.NewReaderSize(f, 1<<20)
-	csvReader := csv.NewReader(reader)
-	csvReader.ReuseRecord = true
+	return LoadRecordInstances(csvReader, headers, featureTypes, targetColumn, totalRows, chunkSize, sampler, coercions)
+}
 
-	// Skip header if present
-	if hasHeader {
-		_, err := csvReader.Read()
-		if err != nil {
-			return nil, fmt.Errorf("error skipping CSV header: %v", err)
-		}
+// LoadRecordInstances loads the instances of the records of any format.
+// Rows of very large datasets are sampled with sampler, or the global source when it is nil.
+// Values that fail to convert are recorded in coercions, when it is not nil.
+func LoadRecordInstances(records RecordReader, headers []string, featureTypes map[string]string,
+	targetColumn string, totalRows int, chunkSize int, sampler *rand.Rand, coercions *Coercions,
+) ([]t.Instance, error) {
+	if coercions == nil {
+		coercions = NewCoercions(false)
 	}
+	lines, _ := records.(LineReader)
 
 	// Determine if we should use sampling for very large datasets
 	useSampling := totalRows > 100000
@@ -217,30 +368,38 @@ func LoadInstances(file string, headers []string, featureTypes map[string]string
 		fmt.Printf("Using sampling rate of %.2f%% for large dataset (%d rows)\n", samplingRate*100, totalRows)
 	}
 
+	draw := rand.Float64
+	if sampler != nil {
+		draw = sampler.Float64
+	}
+
 	// Read and convert data
 	instances := make([]t.Instance, 0, utils.Min(totalRows, 100000))
 	rowCount := 0
 
 	for {
-		record, err := csvReader.Read()
+		record, err := records.Read()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return nil, fmt.Errorf("error reading CSV record: %v", err)
+			return nil, fmt.Errorf("error reading record: %v", err)
 		}
 
 		rowCount++
 
 		// Apply sampling if needed
-		if useSampling && rand.Float64() > samplingRate {
+		if useSampling && draw() > samplingRate {
 			continue
 		}
 
-		instance := utils.ConvertRecordToInstance(record, headers, featureTypes)
+		instance, err := coercions.Convert(record, headers, featureTypes, rowCount, lineOf(lines))
+		if err != nil {
+			return nil, err
+		}
 
-		// Only include instances that have the target column
-		if _, ok := instance[targetColumn]; ok {
+		// Only include instances that have a value for the target column
+		if instance[targetColumn] != nil {
 			instances = append(instances, instance)
 		}
 
@@ -251,31 +410,35 @@ func LoadInstances(file string, headers []string, featureTypes map[string]string
 	}
 
 	fmt.Printf("Loaded %d instances from %d total rows\n", len(instances), rowCount)
+	if coercions.Failed() > 0 {
+		fmt.Print(coercions)
+	}
 	return instances, nil
 }
 
 // loadPredictionInstances performs the second pass through the data to load instances for prediction
 func LoadPredictionInstances(file string, headers []string, featureTypes map[string]string,
-	targetColumn string, totalRows int, chunkSize int, hasHeader bool,
+	targetColumn string, totalRows int, chunkSize int, dialect Dialect, coercions *Coercions,
 ) ([]t.Instance, error) {
 	// Open file again for second pass
-	f, err := os.Open(file)
+	f, csvReader, err := OpenCSVRecords(file, dialect)
 	if err != nil {
-		return nil, fmt.Errorf("error opening file: %v", err)
+		return nil, err
 	}
 	defer f.Close()
 
-	reader := bufio.NewReaderSize(f, 1<<20)
-	csvReader := csv.NewReader(reader)
-	csvReader.ReuseRecord = true
+	return LoadPredictionRecordInstances(csvReader, headers, featureTypes, targetColumn, totalRows, chunkSize, coercions)
+}
 
-	// Skip header if present
-	if hasHeader {
-		_, err := csvReader.Read()
-		if err != nil {
-			return nil, fmt.Errorf("error skipping CSV header: %v", err)
-		}
+// LoadPredictionRecordInstances loads the instances to predict from the records of any format.
+// Values that fail to convert are recorded in coercions, when it is not nil.
+func LoadPredictionRecordInstances(records RecordReader, headers []string, featureTypes map[string]string,
+	targetColumn string, totalRows int, chunkSize int, coercions *Coercions,
+) ([]t.Instance, error) {
+	if coercions == nil {
+		coercions = NewCoercions(false)
 	}
+	lines, _ := records.(LineReader)
 
 	// Determine if we should use sampling for very large datasets
 	useSampling := totalRows > 100000
@@ -295,12 +458,12 @@ func LoadPredictionInstances(file string, headers []string, featureTypes map[str
 	rowCount := 0
 
 	for {
-		record, err := csvReader.Read()
+		record, err := records.Read()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return nil, fmt.Errorf("error reading CSV record: %v", err)
+			return nil, fmt.Errorf("error reading record: %v", err)
 		}
 
 		rowCount++
@@ -310,7 +473,10 @@ func LoadPredictionInstances(file string, headers []string, featureTypes map[str
 			continue
 		}
 
-		instance := utils.ConvertPredictionRecordToInstance(record, headers, featureTypes)
+		instance, err := coercions.Convert(record, headers, featureTypes, rowCount, lineOf(lines))
+		if err != nil {
+			return nil, err
+		}
 
 		// For prediction, we don't require the target column to be present
 		instances = append(instances, instance)
@@ -322,5 +488,16 @@ func LoadPredictionInstances(file string, headers []string, featureTypes map[str
 	}
 
 	fmt.Printf("Loaded %d instances from %d total rows for prediction\n", len(instances), rowCount)
+	if coercions.Failed() > 0 {
+		fmt.Print(coercions)
+	}
 	return instances, nil
 }
+
+// lineOf returns the line of the last record of lines, 0 when it is nil
+func lineOf(lines LineReader) int {
+	if lines == nil {
+		return 0
+	}
+	return lines.Line()
+}
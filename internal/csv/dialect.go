@@ -0,0 +1,143 @@
+package csv
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Encodings of CSV files
+const (
+	UTF8   = "utf-8"
+	Latin1 = "latin-1"
+)
+
+// Dialect describes how a CSV file is written
+type Dialect struct {
+	Delimiter  rune     // separates fields, ',' when 0
+	Comment    rune     // starts lines to skip, none when 0
+	LazyQuotes bool     // allow quotes in unquoted fields and unescaped quotes in quoted fields
+	TrimSpace  bool     // trim the white space around fields
+	NoHeader   bool     // the first row holds values rather than column names
+	Columns    []string // column names of headerless files, col0, col1, ... when empty
+	Encoding   string   // utf-8 (default) or latin-1
+}
+
+// DefaultDialect is a comma separated UTF-8 file with a header row
+func DefaultDialect() Dialect {
+	return Dialect{Delimiter: ','}
+}
+
+// ParseDelimiter parses a delimiter flag: a single character, or tab, \t,
+// semicolon, pipe or comma by name
+func ParseDelimiter(value string) (rune, error) {
+	switch strings.ToLower(value) {
+	case "tab", `\t`:
+		return '\t', nil
+	case "semicolon":
+		return ';', nil
+	case "pipe":
+		return '|', nil
+	case "comma", "":
+		return ',', nil
+	}
+	if utf8.RuneCountInString(value) != 1 {
+		return 0, fmt.Errorf("delimiter must be a single character, got '%s'", value)
+	}
+	r, _ := utf8.DecodeRuneInString(value)
+	return r, nil
+}
+
+// ParseEncoding normalises the name of an encoding
+func ParseEncoding(value string) (string, error) {
+	switch strings.ToLower(strings.ReplaceAll(value, "_", "-")) {
+	case "", "utf-8", "utf8":
+		return UTF8, nil
+	case "latin-1", "latin1", "iso-8859-1", "iso8859-1":
+		return Latin1, nil
+	}
+	return "", fmt.Errorf("unknown encoding '%s', expected utf-8 or latin-1", value)
+}
+
+// NewCSVReader returns a buffered reader of the records of r in the dialect.
+// A UTF-8 byte order mark at the start of r is skipped.
+func NewCSVReader(r io.Reader, dialect Dialect) RecordReader {
+	buffered := bufio.NewReaderSize(r, 1<<20) // 1MB buffer
+	if bom, err := buffered.Peek(3); err == nil && string(bom) == "\xef\xbb\xbf" {
+		buffered.Discard(3)
+	}
+
+	var source io.Reader = buffered
+	if dialect.Encoding == Latin1 {
+		source = &latin1Reader{source: buffered}
+	}
+
+	csvReader := csv.NewReader(source)
+	csvReader.ReuseRecord = true // Reuse record buffer for better performance
+	if dialect.Delimiter != 0 {
+		csvReader.Comma = dialect.Delimiter
+	}
+	csvReader.Comment = dialect.Comment
+	csvReader.LazyQuotes = dialect.LazyQuotes
+	csvReader.TrimLeadingSpace = dialect.TrimSpace
+
+	return &csvRecordReader{reader: csvReader, trimSpace: dialect.TrimSpace}
+}
+
+// csvRecordReader reads the records of a csv.Reader, trimming the white
+// space left after their fields when trimSpace is set
+type csvRecordReader struct {
+	reader    *csv.Reader
+	trimSpace bool
+}
+
+func (r *csvRecordReader) Read() ([]string, error) {
+	record, err := r.reader.Read()
+	if r.trimSpace {
+		for i := range record {
+			record[i] = strings.TrimRight(record[i], " \t\r")
+		}
+	}
+	return record, err
+}
+
+// Line returns the line the last record started on
+func (r *csvRecordReader) Line() int {
+	line, _ := r.reader.FieldPos(0)
+	return line
+}
+
+// latin1Reader decodes ISO-8859-1 to UTF-8. Every byte is the code point of
+// the same value, so bytes above 0x7f take two bytes in UTF-8.
+type latin1Reader struct {
+	source  *bufio.Reader
+	pending []byte
+}
+
+func (r *latin1Reader) Read(p []byte) (int, error) {
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+
+	for n < len(p) {
+		b, err := r.source.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		if b < utf8.RuneSelf {
+			p[n] = b
+			n++
+			continue
+		}
+		encoded := utf8.AppendRune(nil, rune(b))
+		copied := copy(p[n:], encoded)
+		r.pending = append(r.pending, encoded[copied:]...)
+		n += copied
+	}
+	return n, nil
+}
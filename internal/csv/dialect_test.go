@@ -0,0 +1,157 @@
+package csv
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAll(tt *testing.T, content string, dialect Dialect) [][]string {
+	reader := NewCSVReader(strings.NewReader(content), dialect)
+	var records [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return records
+		}
+		require.NoError(tt, err)
+		records = append(records, append([]string(nil), record...))
+	}
+}
+
+func TestNewCSVReader(tt *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		dialect Dialect
+		want    [][]string
+	}{
+		{
+			name:    "Semicolons with a byte order mark",
+			content: "\xef\xbb\xbfcity;price\nGenève;1,5\n",
+			dialect: Dialect{Delimiter: ';'},
+			want:    [][]string{{"city", "price"}, {"Genève", "1,5"}},
+		},
+		{
+			name:    "Tabs and comments",
+			content: "# exported\na\tb\n# note\n1\t2\n",
+			dialect: Dialect{Delimiter: '\t', Comment: '#'},
+			want:    [][]string{{"a", "b"}, {"1", "2"}},
+		},
+		{
+			name:    "Trimmed fields",
+			content: "a | b \n 1 |2  \n",
+			dialect: Dialect{Delimiter: '|', TrimSpace: true},
+			want:    [][]string{{"a", "b"}, {"1", "2"}},
+		},
+		{
+			name:    "Lazy quotes",
+			content: "a,b\n5\" screen,1\n",
+			dialect: Dialect{LazyQuotes: true},
+			want:    [][]string{{"a", "b"}, {"5\" screen", "1"}},
+		},
+		{
+			name:    "Latin-1",
+			content: "city\nM\xfcnchen\nS\xe3o Paulo\n",
+			dialect: Dialect{Encoding: Latin1},
+			want:    [][]string{{"city"}, {"München"}, {"São Paulo"}},
+		},
+	}
+	for _, tc := range tests {
+		tt.Run(tc.name, func(tt *testing.T) {
+			assert.Equal(tt, tc.want, readAll(tt, tc.content, tc.dialect))
+		})
+	}
+}
+
+func TestReadCSVHeaders(tt *testing.T) {
+	headers, err := ReadCSVHeaders(NewCSVReader(strings.NewReader("a,b\n1,2\n"), Dialect{}), Dialect{})
+	require.NoError(tt, err)
+	assert.Equal(tt, []string{"a", "b"}, headers)
+
+	headerless := Dialect{NoHeader: true}
+	headers, err = ReadCSVHeaders(NewCSVReader(strings.NewReader("1,2\n"), headerless), headerless)
+	require.NoError(tt, err)
+	assert.Equal(tt, []string{"col0", "col1"}, headers)
+
+	named := Dialect{NoHeader: true, Columns: []string{"x", "y"}}
+	headers, err = ReadCSVHeaders(NewCSVReader(strings.NewReader("1,2\n"), named), named)
+	require.NoError(tt, err)
+	assert.Equal(tt, []string{"x", "y"}, headers)
+
+	named.Columns = []string{"x"}
+	_, err = ReadCSVHeaders(NewCSVReader(strings.NewReader("1,2\n"), named), named)
+	assert.Error(tt, err)
+}
+
+func TestLoadInstances_Headerless(tt *testing.T) {
+	file := filepath.Join(tt.TempDir(), "data.csv")
+	require.NoError(tt, os.WriteFile(file, []byte("red;yes\nblue;no\n"), 0o644))
+	dialect := Dialect{Delimiter: ';', NoHeader: true, Columns: []string{"colour", "label"}}
+
+	stats, err := CollectDatasetStatistics(file, dialect.Columns, dialect)
+	require.NoError(tt, err)
+	assert.Equal(tt, 2, stats.RowCount)
+
+	instances, err := LoadPredictionInstances(file, dialect.Columns, DetermineColumnTypes(stats), "label", stats.RowCount, 10, dialect, nil)
+	require.NoError(tt, err)
+	require.Len(tt, instances, 2)
+	assert.Equal(tt, "red", instances[0]["colour"])
+	assert.Equal(tt, "no", instances[1]["label"])
+}
+
+func TestParseDelimiter(tt *testing.T) {
+	for value, want := range map[string]rune{"tab": '\t', `\t`: '\t', ";": ';', "semicolon": ';', "pipe": '|', "": ','} {
+		got, err := ParseDelimiter(value)
+		require.NoError(tt, err)
+		assert.Equal(tt, want, got, value)
+	}
+	_, err := ParseDelimiter(";;")
+	assert.Error(tt, err)
+
+	encoding, err := ParseEncoding("ISO-8859-1")
+	require.NoError(tt, err)
+	assert.Equal(tt, Latin1, encoding)
+	_, err = ParseEncoding("utf-16")
+	assert.Error(tt, err)
+}
+
+func TestCollectHeadStatistics(tt *testing.T) {
+	var content strings.Builder
+	content.WriteString("size,label\n")
+	for i := 0; i < SampleSize+5; i++ {
+		content.WriteString("1.5,yes\n")
+	}
+	content.WriteString("large,no\n")
+
+	dialect := DefaultDialect()
+	reader := NewCSVReader(strings.NewReader(content.String()), dialect)
+	headers, err := ReadCSVHeaders(reader, dialect)
+	require.NoError(tt, err)
+
+	stats, records, err := CollectHeadStatistics(reader, headers)
+	require.NoError(tt, err)
+	assert.Equal(tt, SampleSize, stats.RowCount)
+	// Types come from the head only
+	assert.Equal(tt, "numerical", DetermineColumnTypes(stats)["size"])
+
+	// The head is replayed before the rest of the stream
+	count := 0
+	var last []string
+	for {
+		record, err := records.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(tt, err)
+		last = record
+		count++
+	}
+	assert.Equal(tt, SampleSize+6, count)
+	assert.Equal(tt, []string{"large", "no"}, last)
+}
@@ -0,0 +1,238 @@
+package jsonl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Reader reads JSON Lines, one object per line, as records of values in
+// header order. Nested objects are flattened with dotted keys, numbers keep
+// their JSON text, and nulls and keys a line leaves out are empty, so the
+// records go through the same statistics and loading as CSV records.
+type Reader struct {
+	scanner *bufio.Scanner
+	columns map[string]int
+	record  []string
+	text    []bool
+	line    int
+	pending []object // objects read ahead by ReadHead
+	current int      // line of the last object read
+}
+
+// object is the flattened fields of the object on a line
+type object struct {
+	fields []field
+	line   int
+}
+
+// NewReader returns a reader of the lines of r with the given headers
+func NewReader(r io.Reader, headers []string) *Reader {
+	reader := &Reader{scanner: newScanner(r)}
+	reader.setHeaders(headers)
+	return reader
+}
+
+// setHeaders sets the columns of the records read
+func (r *Reader) setHeaders(headers []string) {
+	r.columns = make(map[string]int, len(headers))
+	for i, header := range headers {
+		r.columns[header] = i
+	}
+	r.record = make([]string, len(headers))
+	r.text = make([]bool, len(headers))
+}
+
+// Read returns the record of the next object. The record is reused by the
+// next call, as with a csv.Reader with ReuseRecord set.
+func (r *Reader) Read() ([]string, error) {
+	values, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range r.record {
+		r.record[i] = ""
+		r.text[i] = false
+	}
+	for _, field := range values {
+		i, ok := r.columns[field.key]
+		if !ok {
+			continue
+		}
+		r.record[i], r.text[i] = field.text, field.isText
+	}
+	return r.record, nil
+}
+
+// Line returns the line of the last object read
+func (r *Reader) Line() int {
+	return r.current
+}
+
+// IsText reports whether the value in a column of the last record was a
+// JSON string, which is never taken for a number
+func (r *Reader) IsText(column int) bool {
+	return r.text[column]
+}
+
+// next returns the flattened fields of the next object
+func (r *Reader) next() ([]field, error) {
+	if len(r.pending) > 0 {
+		next := r.pending[0]
+		r.pending = r.pending[1:]
+		r.current = next.line
+		return next.fields, nil
+	}
+	for r.scanner.Scan() {
+		r.line++
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		values, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", r.line, err)
+		}
+		r.current = r.line
+		return values, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// ReadHeaders returns the flattened keys of every object of r, in the
+// order they first appear
+func ReadHeaders(r io.Reader) ([]string, error) {
+	headers, _, err := readKeys(NewReader(r, nil), -1)
+	return headers, err
+}
+
+// ReadHead reads the first n objects of r, for streams that cannot be read
+// twice, and returns their keys with a reader of every object of r. Keys
+// that only appear after the first n objects are not read.
+func ReadHead(r io.Reader, n int) ([]string, *Reader, error) {
+	reader := NewReader(r, nil)
+	headers, head, err := readKeys(reader, n)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader.setHeaders(headers)
+	reader.pending = head
+	return headers, reader, nil
+}
+
+// readKeys reads up to n objects, every object when n is negative, and
+// returns their keys in the order they first appear with the objects read
+func readKeys(reader *Reader, n int) ([]string, []object, error) {
+	var headers []string
+	var objects []object
+	seen := make(map[string]bool)
+	for n < 0 || len(objects) < n {
+		values, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading JSON lines: %v", err)
+		}
+		for _, field := range values {
+			if !seen[field.key] {
+				seen[field.key] = true
+				headers = append(headers, field.key)
+			}
+		}
+		if n >= 0 {
+			objects = append(objects, object{fields: values, line: reader.current})
+		}
+	}
+	if len(headers) == 0 {
+		return nil, nil, fmt.Errorf("no JSON objects found")
+	}
+	return headers, objects, nil
+}
+
+// field is a flattened value, with whether it was a JSON string
+type field struct {
+	key    string
+	text   string
+	isText bool
+}
+
+// parseLine flattens the object on a line into its fields, in order
+func parseLine(line []byte) ([]field, error) {
+	if line[0] != '{' {
+		return nil, fmt.Errorf("expected a JSON object")
+	}
+	fields, err := flatten(line, "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	return fields, nil
+}
+
+// flatten appends the fields of a JSON object, with keys prefixed by prefix.
+// Nested objects add their keys after a dot, arrays keep their JSON text.
+func flatten(object []byte, prefix string, fields []field) ([]field, error) {
+	decoder := json.NewDecoder(bytes.NewReader(object))
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key := prefix + token.(string)
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, err
+		}
+
+		switch raw[0] {
+		case '{':
+			if fields, err = flatten(raw, key+".", fields); err != nil {
+				return nil, err
+			}
+		case '"':
+			var text string
+			if err := json.Unmarshal(raw, &text); err != nil {
+				return nil, err
+			}
+			fields = append(fields, field{key: key, text: text, isText: true})
+		case 'n':
+			fields = append(fields, field{key: key})
+		case '[':
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, raw); err != nil {
+				return nil, err
+			}
+			fields = append(fields, field{key: key, text: compact.String()})
+		default:
+			// Numbers keep their JSON text, booleans become true or false
+			fields = append(fields, field{key: key, text: string(raw)})
+		}
+	}
+
+	// The closing brace, and nothing after it
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the object")
+	}
+	return fields, nil
+}
+
+func newScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(bufio.NewReaderSize(r, 1<<20))
+	scanner.Buffer(make([]byte, 0, 1<<16), 1<<26)
+	return scanner
+}
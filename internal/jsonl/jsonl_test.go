@@ -0,0 +1,117 @@
+package jsonl
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tcsv "github.com/nyunja/c4.5-decision-tree/internal/csv"
+)
+
+const events = `{"user": {"id": "0042", "plan": "pro"}, "spend": 12.5, "signup": "2024-03-01", "seen": "2024-03-01T10:00:00Z", "churn": "no"}
+{"user": {"id": "0043", "plan": null}, "spend": 3, "signup": "2024-01-15", "churn": "yes", "tags": ["a", "b"]}
+
+{"user": {"id": "0044"}, "spend": null, "signup": null, "seen": "2024-02-01T08:30:00Z", "churn": "no", "active": true}
+`
+
+func writeEvents(t *testing.T) *os.File {
+	file := filepath.Join(t.TempDir(), "events.jsonl")
+	require.NoError(t, os.WriteFile(file, []byte(events), 0o644))
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestReadHeaders(t *testing.T) {
+	headers, err := ReadHeaders(writeEvents(t))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user.id", "user.plan", "spend", "signup", "seen", "churn", "tags", "active"}, headers)
+}
+
+func TestReader(t *testing.T) {
+	f := writeEvents(t)
+	headers, err := ReadHeaders(f)
+	require.NoError(t, err)
+
+	f.Seek(0, 0)
+	reader := NewReader(f, headers)
+
+	record, err := reader.Read()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0042", "pro", "12.5", "2024-03-01", "2024-03-01T10:00:00Z", "no", "", ""}, record)
+	assert.True(t, reader.IsText(0))
+	assert.False(t, reader.IsText(2))
+
+	record, err = reader.Read()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0043", "", "3", "2024-01-15", "", "yes", `["a","b"]`, ""}, record)
+
+	record, err = reader.Read()
+	require.NoError(t, err)
+	assert.Equal(t, "true", record[7])
+	assert.Equal(t, "", record[2])
+
+	_, err = reader.Read()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestColumnTypes(t *testing.T) {
+	f := writeEvents(t)
+	headers, err := ReadHeaders(f)
+	require.NoError(t, err)
+
+	f.Seek(0, 0)
+	stats, err := tcsv.CollectRecordStatistics(NewReader(f, headers), headers)
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.RowCount)
+
+	featureTypes := tcsv.DetermineColumnTypes(stats)
+	// Numbers written as strings stay categorical
+	assert.Equal(t, "categorical", featureTypes["user.id"])
+	assert.Equal(t, "numerical", featureTypes["spend"])
+	assert.Equal(t, "date", featureTypes["signup"])
+	assert.Equal(t, "timestamp", featureTypes["seen"])
+	assert.Equal(t, "categorical", featureTypes["churn"])
+	assert.Equal(t, "categorical", featureTypes["active"])
+}
+
+func TestReader_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines string
+	}{
+		{"Not an object", "[1, 2]\n"},
+		{"Invalid JSON", `{"a": 1` + "\n"},
+		{"Trailing data", `{"a": 1} {"b": 2}` + "\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewReader(strings.NewReader(tc.lines), []string{"a"}).Read()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestReadHead(t *testing.T) {
+	headers, reader, err := ReadHead(strings.NewReader(events), 2)
+	require.NoError(t, err)
+	// Keys of objects after the head are not read
+	assert.Equal(t, []string{"user.id", "user.plan", "spend", "signup", "seen", "churn", "tags"}, headers)
+
+	var ids []string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		ids = append(ids, record[0])
+	}
+	assert.Equal(t, []string{"0042", "0043", "0044"}, ids)
+}
@@ -2,25 +2,32 @@ package counter
 
 import (
 	"math"
+	"sort"
 )
 
-// ClassCounter is a helper struct to efficiently count class occurrences
+// ClassCounter is a helper struct to efficiently count class occurrences.
+// Counts are weighted, so an unweighted instance adds 1.
 type ClassCounter struct {
-	Counts map[string]int
-	Total  int
+	Counts map[string]float64
+	Total  float64
 }
 
 // NewClassCounter creates a new ClassCounter
 func NewClassCounter() *ClassCounter {
 	return &ClassCounter{
-		Counts: make(map[string]int),
+		Counts: make(map[string]float64),
 	}
 }
 
 // Add adds a class to the counter
 func (c *ClassCounter) Add(class string) {
-	c.Counts[class]++
-	c.Total++
+	c.AddWeighted(class, 1)
+}
+
+// AddWeighted adds a class to the counter with the given instance weight
+func (c *ClassCounter) AddWeighted(class string, weight float64) {
+	c.Counts[class] += weight
+	c.Total += weight
 }
 
 // GetEntropy calculates the entropy of the class distribution
@@ -30,23 +37,56 @@ func (c *ClassCounter) GetEntropy() float64 {
 	}
 
 	entropy := 0.0
-	totalFloat := float64(c.Total)
 
-	for _, count := range c.Counts {
-		probability := float64(count) / totalFloat
+	for _, class := range c.Classes() {
+		count := c.Counts[class]
+		if count <= 0 {
+			continue
+		}
+		probability := count / c.Total
 		entropy -= probability * math.Log2(probability)
 	}
 
 	return entropy
 }
 
-// GetMajorityClass returns the most frequent class
+// GetMajorityClass returns the most frequent class. Ties go to the
+// alphabetically first class.
 func (c *ClassCounter) GetMajorityClass() string {
 	majorityClass := ""
-	maxCount := 0
+	maxCount := 0.0
+
+	for _, class := range c.Classes() {
+		if count := c.Counts[class]; count > maxCount {
+			maxCount = count
+			majorityClass = class
+		}
+	}
 
-	for class, count := range c.Counts {
-		if count > maxCount {
+	return majorityClass
+}
+
+// GetMajorityClassInOrder returns the most frequent class. Ties go to the
+// class listed first in order, then alphabetically among unlisted classes.
+func (c *ClassCounter) GetMajorityClassInOrder(order []string) string {
+	rank := make(map[string]int, len(order))
+	for i, class := range order {
+		rank[class] = i
+	}
+	classes := c.Classes()
+	sort.SliceStable(classes, func(i, j int) bool {
+		ri, iok := rank[classes[i]]
+		rj, jok := rank[classes[j]]
+		if iok && jok {
+			return ri < rj
+		}
+		return iok && !jok
+	})
+
+	majorityClass := ""
+	maxCount := 0.0
+	for _, class := range classes {
+		if count := c.Counts[class]; count > maxCount {
 			maxCount = count
 			majorityClass = class
 		}
@@ -54,3 +94,40 @@ func (c *ClassCounter) GetMajorityClass() string {
 
 	return majorityClass
 }
+
+// ByFrequency returns the classes from most to least frequent, ties in
+// alphabetical order
+func (c *ClassCounter) ByFrequency() []string {
+	classes := c.Classes()
+	sort.SliceStable(classes, func(i, j int) bool {
+		return c.Counts[classes[i]] > c.Counts[classes[j]]
+	})
+	return classes
+}
+
+// GetGini calculates the Gini impurity of the class distribution
+func (c *ClassCounter) GetGini() float64 {
+	if c.Total == 0 {
+		return 0
+	}
+
+	gini := 1.0
+
+	for _, class := range c.Classes() {
+		probability := c.Counts[class] / c.Total
+		gini -= probability * probability
+	}
+
+	return gini
+}
+
+// Classes returns the counted classes in sorted order, so that sums over
+// them do not depend on map iteration order
+func (c *ClassCounter) Classes() []string {
+	classes := make([]string, 0, len(c.Counts))
+	for class := range c.Counts {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	return classes
+}
@@ -33,7 +33,7 @@ func TestNewClassCounter_InitCountMap(t *testing.T) {
 	}
 
 	if counter.Total != 0 {
-		t.Errorf("Expected Total to be 0, but got %d", counter.Total)
+		t.Errorf("Expected Total to be 0, but got %v", counter.Total)
 	}
 }
 
@@ -48,7 +48,7 @@ func TestNewClassCounter_DistinctInstances(t *testing.T) {
 
 	counter1.Add("class1")
 	if counter2.Total != 0 {
-		t.Errorf("Changes to counter1 should not affect counter2. Expected counter2.Total to be 0, but got %d", counter2.Total)
+		t.Errorf("Changes to counter1 should not affect counter2. Expected counter2.Total to be 0, but got %v", counter2.Total)
 	}
 
 	if len(counter2.Counts) != 0 {
@@ -66,7 +66,7 @@ func TestNewClassCounter_NoPreExistingEntries(t *testing.T) {
 		t.Errorf("Expected empty Counts map, got %d entries", len(counter.Counts))
 	}
 	if counter.Total != 0 {
-		t.Errorf("Expected Total to be 0, got %d", counter.Total)
+		t.Errorf("Expected Total to be 0, got %v", counter.Total)
 	}
 }
 
@@ -85,11 +85,11 @@ func TestClassCounter_Add(t *testing.T) {
 	counter.Add("class1")
 
 	if counter.Counts["class1"] != 1 {
-		t.Errorf("Expected count for class1 to be 1, but got %d", counter.Counts["class1"])
+		t.Errorf("Expected count for class1 to be 1, but got %v", counter.Counts["class1"])
 	}
 
 	if counter.Total != 1 {
-		t.Errorf("Expected total count to be 1, but got %d", counter.Total)
+		t.Errorf("Expected total count to be 1, but got %v", counter.Total)
 	}
 }
 
@@ -100,11 +100,11 @@ func TestClassCounter_AddExistingClass(t *testing.T) {
 	counter.Add("A")
 
 	if count, exists := counter.Counts["A"]; !exists || count != 2 {
-		t.Errorf("Expected count for class 'A' to be 2, got %d", count)
+		t.Errorf("Expected count for class 'A' to be 2, got %v", count)
 	}
 
 	if counter.Total != 2 {
-		t.Errorf("Expected total count to be 2, got %d", counter.Total)
+		t.Errorf("Expected total count to be 2, got %v", counter.Total)
 	}
 }
 
@@ -189,3 +189,95 @@ func TestGetMajorityClass_CorrectReturn(t *testing.T) {
 		t.Errorf("Expected majority class to be 'A', but got '%s'", result)
 	}
 }
+
+// Should calculate the Gini impurity of a class distribution
+func TestGetGini(t *testing.T) {
+	empty := NewClassCounter()
+	if gini := empty.GetGini(); gini != 0 {
+		t.Errorf("Expected Gini of empty counter to be 0, got %f", gini)
+	}
+
+	counter := NewClassCounter()
+	counter.Add("A")
+	counter.Add("A")
+	counter.Add("B")
+	counter.Add("C")
+
+	expected := 1 - (0.25 + 0.0625 + 0.0625)
+	if gini := counter.GetGini(); math.Abs(gini-expected) > 1e-12 {
+		t.Errorf("Expected Gini %f, got %f", expected, gini)
+	}
+}
+
+// Should add the instance weight to the class and the total
+func TestClassCounter_AddWeighted(t *testing.T) {
+	counter := NewClassCounter()
+	counter.AddWeighted("fraud", 2.5)
+	counter.AddWeighted("legit", 0.5)
+	counter.Add("legit")
+
+	if counter.Counts["fraud"] != 2.5 || counter.Counts["legit"] != 1.5 {
+		t.Errorf("Unexpected weighted counts %v", counter.Counts)
+	}
+	if counter.Total != 4 {
+		t.Errorf("Expected total weight 4, got %v", counter.Total)
+	}
+	if majority := counter.GetMajorityClass(); majority != "fraud" {
+		t.Errorf("Expected weighted majority class 'fraud', got %q", majority)
+	}
+}
+
+// Should break ties alphabetically
+func TestGetMajorityClass_Tie(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		counter := NewClassCounter()
+		counter.Add("B")
+		counter.Add("A")
+		counter.Add("C")
+		if result := counter.GetMajorityClass(); result != "A" {
+			t.Fatalf("Expected tie to go to 'A', got '%s'", result)
+		}
+	}
+}
+
+// Should break ties by the given class order, then alphabetically
+func TestGetMajorityClassInOrder(t *testing.T) {
+	counter := NewClassCounter()
+	counter.Add("A")
+	counter.Add("B")
+	counter.Add("C")
+
+	tests := []struct {
+		order []string
+		want  string
+	}{
+		{[]string{"C", "B", "A"}, "C"},
+		{[]string{"B"}, "B"},
+		{nil, "A"},
+	}
+	for _, tt := range tests {
+		if result := counter.GetMajorityClassInOrder(tt.order); result != tt.want {
+			t.Errorf("GetMajorityClassInOrder(%v) = %q, want %q", tt.order, result, tt.want)
+		}
+	}
+
+	counter.Add("B")
+	if result := counter.GetMajorityClassInOrder([]string{"C"}); result != "B" {
+		t.Errorf("Expected the majority 'B' regardless of order, got %q", result)
+	}
+}
+
+// Should order classes by decreasing count, ties alphabetically
+func TestByFrequency(t *testing.T) {
+	counter := NewClassCounter()
+	for _, class := range []string{"c", "b", "a", "c", "b", "d", "c"} {
+		counter.Add(class)
+	}
+	got := counter.ByFrequency()
+	want := []string{"c", "b", "a", "d"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+}
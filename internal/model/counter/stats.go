@@ -0,0 +1,64 @@
+package counter
+
+import (
+	"math"
+)
+
+// TargetStats accumulates the weighted moments of a numeric target
+type TargetStats struct {
+	Weight     float64
+	Sum        float64
+	SumSquares float64
+}
+
+// NewTargetStats creates a new TargetStats
+func NewTargetStats() *TargetStats {
+	return &TargetStats{}
+}
+
+// Add adds a target value with the given instance weight
+func (s *TargetStats) Add(value, weight float64) {
+	s.Weight += weight
+	s.Sum += weight * value
+	s.SumSquares += weight * value * value
+}
+
+// Merge returns new stats holding the values of both stats
+func (s *TargetStats) Merge(other *TargetStats) *TargetStats {
+	return &TargetStats{
+		Weight:     s.Weight + other.Weight,
+		Sum:        s.Sum + other.Sum,
+		SumSquares: s.SumSquares + other.SumSquares,
+	}
+}
+
+// Subtract returns new stats holding the values of s that are not in other
+func (s *TargetStats) Subtract(other *TargetStats) *TargetStats {
+	return &TargetStats{
+		Weight:     s.Weight - other.Weight,
+		Sum:        s.Sum - other.Sum,
+		SumSquares: s.SumSquares - other.SumSquares,
+	}
+}
+
+// Mean returns the weighted mean of the target
+func (s *TargetStats) Mean() float64 {
+	if s.Weight == 0 {
+		return 0
+	}
+	return s.Sum / s.Weight
+}
+
+// Variance returns the weighted population variance of the target
+func (s *TargetStats) Variance() float64 {
+	if s.Weight == 0 {
+		return 0
+	}
+	mean := s.Mean()
+	return math.Max(s.SumSquares/s.Weight-mean*mean, 0)
+}
+
+// StdDev returns the weighted population standard deviation of the target
+func (s *TargetStats) StdDev() float64 {
+	return math.Sqrt(s.Variance())
+}
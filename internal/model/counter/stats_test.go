@@ -0,0 +1,54 @@
+package counter
+
+import (
+	"math"
+	"testing"
+)
+
+// Should compute the weighted mean and population variance
+func TestTargetStats_Moments(t *testing.T) {
+	stats := NewTargetStats()
+	stats.Add(1, 1)
+	stats.Add(3, 1)
+	stats.Add(5, 2)
+
+	if stats.Weight != 4 {
+		t.Errorf("Expected weight 4, got %v", stats.Weight)
+	}
+	if got := stats.Mean(); got != 3.5 {
+		t.Errorf("Expected mean 3.5, got %v", got)
+	}
+	// (2.5² + 0.5² + 2*1.5²) / 4 = 2.75
+	if got := stats.Variance(); math.Abs(got-2.75) > 1e-12 {
+		t.Errorf("Expected variance 2.75, got %v", got)
+	}
+	if got := stats.StdDev(); math.Abs(got-math.Sqrt(2.75)) > 1e-12 {
+		t.Errorf("Expected standard deviation %v, got %v", math.Sqrt(2.75), got)
+	}
+}
+
+// Should return zero moments for empty stats
+func TestTargetStats_Empty(t *testing.T) {
+	stats := NewTargetStats()
+	if stats.Mean() != 0 || stats.Variance() != 0 {
+		t.Errorf("Expected zero mean and variance, got %v and %v", stats.Mean(), stats.Variance())
+	}
+}
+
+// Should merge and subtract stats without modifying the operands
+func TestTargetStats_MergeSubtract(t *testing.T) {
+	left, right := NewTargetStats(), NewTargetStats()
+	left.Add(1, 1)
+	right.Add(5, 3)
+
+	merged := left.Merge(right)
+	if merged.Weight != 4 || merged.Sum != 16 || merged.SumSquares != 76 {
+		t.Errorf("Unexpected merged stats %+v", *merged)
+	}
+	if back := merged.Subtract(right); *back != *left {
+		t.Errorf("Expected %+v after subtracting, got %+v", *left, *back)
+	}
+	if left.Weight != 1 {
+		t.Errorf("Merge modified its receiver: %+v", *left)
+	}
+}
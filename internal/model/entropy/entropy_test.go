@@ -1,8 +1,10 @@
 package entropy
 
 import (
+	"math"
 	"testing"
 
+	"github.com/nyunja/c4.5-decision-tree/internal/model/counter"
 	test "github.com/nyunja/c4.5-decision-tree/internal/model/types"
 )
 
@@ -64,3 +66,31 @@ func TestCalculateEntropy(t *testing.T) {
 		})
 	}
 }
+
+func TestInformationGainAndSplitInfo(t *testing.T) {
+	parent := counter.NewClassCounter()
+	left := counter.NewClassCounter()
+	right := counter.NewClassCounter()
+	for _, class := range []string{"A", "A", "B", "B"} {
+		parent.Add(class)
+	}
+	left.Add("A")
+	left.Add("A")
+	right.Add("B")
+	right.Add("B")
+
+	children := []*counter.ClassCounter{left, right}
+	if got := InformationGain(parent, children); got != 1 {
+		t.Errorf("InformationGain() = %v, want 1", got)
+	}
+	if got := SplitInfo(parent, children); got != 1 {
+		t.Errorf("SplitInfo() = %v, want 1", got)
+	}
+
+	// An instance missing from every child only counts towards the parent
+	parent.Add("A")
+	want := -2 * 0.4 * math.Log2(0.4)
+	if got := SplitInfo(parent, children); math.Abs(got-want) > 1e-12 {
+		t.Errorf("SplitInfo() with an unresolved instance = %v, want %v", got, want)
+	}
+}
@@ -8,7 +8,7 @@ import (
 )
 
 func GainInfoAndSplitInfo(counter *counter.ClassCounter, instances []t.Instance, infoGain float64, splitInfo float64) (float64, float64) {
-	prob := float64(counter.Total) / float64(len(instances))
+	prob := counter.Total / float64(len(instances))
 	if prob > 0 {
 		infoGain -= prob * counter.GetEntropy()
 		splitInfo -= prob * math.Log2(prob)
@@ -32,3 +32,36 @@ func GainRatio(leftProb float64, rightProb float64, infoGain float64) float64 {
 	}
 	return gainRatio
 }
+
+// InformationGain calculates the entropy reduction from splitting parent into children.
+// Instances missing from every child count as unresolved, as in C4.5.
+func InformationGain(parent *counter.ClassCounter, children []*counter.ClassCounter) float64 {
+	if parent.Total == 0 {
+		return 0
+	}
+
+	infoGain := parent.GetEntropy()
+	for _, child := range children {
+		prob := child.Total / parent.Total
+		if prob > 0 {
+			infoGain -= prob * child.GetEntropy()
+		}
+	}
+	return infoGain
+}
+
+// SplitInfo calculates the potential information of the partition into children
+func SplitInfo(parent *counter.ClassCounter, children []*counter.ClassCounter) float64 {
+	if parent.Total == 0 {
+		return 0
+	}
+
+	splitInfo := 0.0
+	for _, child := range children {
+		prob := child.Total / parent.Total
+		if prob > 0 {
+			splitInfo -= prob * math.Log2(prob)
+		}
+	}
+	return splitInfo
+}
@@ -0,0 +1,112 @@
+package evaluate
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/predict"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/split"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+)
+
+// ClassificationMetrics summarises how well predicted classes match the labels
+type ClassificationMetrics struct {
+	Count    int     `json:"count"`
+	Correct  int     `json:"correct"`
+	Accuracy float64 `json:"accuracy"`
+}
+
+// RegressionMetrics summarises the errors of numeric predictions
+type RegressionMetrics struct {
+	Count int     `json:"count"`
+	MAE   float64 `json:"mae"`
+	RMSE  float64 `json:"rmse"`
+	R2    float64 `json:"r2"`
+}
+
+// Classification compares predicted classes against the actual classes
+func Classification(actual, predicted []string) ClassificationMetrics {
+	metrics := ClassificationMetrics{Count: len(actual)}
+	for i := range actual {
+		if actual[i] == predicted[i] {
+			metrics.Correct++
+		}
+	}
+	if metrics.Count > 0 {
+		metrics.Accuracy = float64(metrics.Correct) / float64(metrics.Count)
+	}
+	return metrics
+}
+
+// Regression computes the mean absolute error, root mean squared error and
+// coefficient of determination of the predictions
+func Regression(actual, predicted []float64) RegressionMetrics {
+	metrics := RegressionMetrics{Count: len(actual)}
+	if len(actual) == 0 {
+		return metrics
+	}
+
+	mean := 0.0
+	for _, value := range actual {
+		mean += value
+	}
+	mean /= float64(len(actual))
+
+	absErr, sqErr, totalSq := 0.0, 0.0, 0.0
+	for i := range actual {
+		residual := actual[i] - predicted[i]
+		absErr += math.Abs(residual)
+		sqErr += residual * residual
+		totalSq += (actual[i] - mean) * (actual[i] - mean)
+	}
+
+	n := float64(len(actual))
+	metrics.MAE = absErr / n
+	metrics.RMSE = math.Sqrt(sqErr / n)
+	if totalSq > 0 {
+		metrics.R2 = 1 - sqErr/totalSq
+	}
+	return metrics
+}
+
+// Model scores a model on labelled instances. Instances without a usable
+// label are skipped. It returns ClassificationMetrics or RegressionMetrics.
+func Model(model *t.Model, instances []t.Instance) (interface{}, error) {
+	if model.Task == t.Regression {
+		actual := make([]float64, 0, len(instances))
+		predicted := make([]float64, 0, len(instances))
+		for _, instance := range instances {
+			value, ok := split.TargetValue(instance, model.TargetName)
+			if !ok {
+				if parsed, err := strconv.ParseFloat(fmt.Sprintf("%v", instance[model.TargetName]), 64); err == nil {
+					value, ok = parsed, true
+				}
+			}
+			if !ok {
+				continue
+			}
+			actual = append(actual, value)
+			predicted = append(predicted, predict.PredictValue(model, instance))
+		}
+		if len(actual) == 0 {
+			return nil, fmt.Errorf("no instances with a numeric '%s' to evaluate", model.TargetName)
+		}
+		return Regression(actual, predicted), nil
+	}
+
+	actual := make([]string, 0, len(instances))
+	predicted := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		label, ok := instance[model.TargetName]
+		if !ok || label == nil || label == "" {
+			continue
+		}
+		actual = append(actual, fmt.Sprintf("%v", label))
+		predicted = append(predicted, predict.PredictClass(model, instance))
+	}
+	if len(actual) == 0 {
+		return nil, fmt.Errorf("no instances with a '%s' label to evaluate", model.TargetName)
+	}
+	return Classification(actual, predicted), nil
+}
@@ -0,0 +1,57 @@
+package evaluate
+
+import (
+	"math"
+	"testing"
+
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+)
+
+func TestClassification(tt *testing.T) {
+	metrics := Classification([]string{"yes", "no", "yes", "no"}, []string{"yes", "yes", "yes", "no"})
+	if metrics.Count != 4 || metrics.Correct != 3 || metrics.Accuracy != 0.75 {
+		tt.Errorf("Unexpected metrics %+v", metrics)
+	}
+}
+
+func TestRegression(tt *testing.T) {
+	metrics := Regression([]float64{1, 2, 3, 4}, []float64{1, 2, 4, 2})
+
+	if metrics.MAE != 0.75 {
+		tt.Errorf("Expected MAE 0.75, got %v", metrics.MAE)
+	}
+	if want := math.Sqrt(1.25); math.Abs(metrics.RMSE-want) > 1e-12 {
+		tt.Errorf("Expected RMSE %v, got %v", want, metrics.RMSE)
+	}
+	// 1 - 5 / 5
+	if math.Abs(metrics.R2) > 1e-12 {
+		tt.Errorf("Expected R² 0, got %v", metrics.R2)
+	}
+
+	perfect := Regression([]float64{1, 2, 3}, []float64{1, 2, 3})
+	if perfect.MAE != 0 || perfect.R2 != 1 {
+		tt.Errorf("Expected a perfect fit, got %+v", perfect)
+	}
+}
+
+func TestModel(tt *testing.T) {
+	model := &t.Model{
+		Task:       t.Regression,
+		TargetName: "price",
+		Root:       &t.Node{IsLeaf: true, Mean: 2},
+	}
+	instances := []t.Instance{{"price": 1.0}, {"price": "3"}, {"price": nil}}
+
+	metrics, err := Model(model, instances)
+	if err != nil {
+		tt.Fatal(err)
+	}
+	regression, ok := metrics.(RegressionMetrics)
+	if !ok || regression.Count != 2 || regression.MAE != 1 {
+		tt.Errorf("Unexpected metrics %+v", metrics)
+	}
+
+	if _, err := Model(model, []t.Instance{{"price": nil}}); err == nil {
+		tt.Error("Expected an error without labelled instances")
+	}
+}
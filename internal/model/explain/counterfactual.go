@@ -0,0 +1,364 @@
+package explain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/predict"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/split"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
+)
+
+// secondsPerDay converts date and timestamp distances to days
+const secondsPerDay = 24 * 60 * 60
+
+// CounterfactualOptions constrains the search for counterfactuals
+type CounterfactualOptions struct {
+	// Target is the class the changed instance should be predicted as. Any
+	// class other than the current prediction is accepted when it is empty.
+	Target string
+	// Immutable features are never changed
+	Immutable []string
+	// Costs holds the cost of changing each feature: per unit for numerical
+	// features, per day for dates and timestamps, and per change for
+	// categorical features or when a missing value is filled in. Features
+	// without a cost cost 1.
+	Costs map[string]float64
+	// Limit is the maximum number of counterfactuals returned, all when 0
+	Limit int
+}
+
+// ErrAlreadyPredicted is returned for instances the model already predicts
+// as the target class, which need no change
+var ErrAlreadyPredicted = errors.New("instance is already predicted as the target class")
+
+// Change is the change of one feature value
+type Change struct {
+	Feature string      `json:"feature"`
+	From    interface{} `json:"from"`
+	To      interface{} `json:"to"`
+	Test    string      `json:"test"` // the condition the new value satisfies, like "income > 52000"
+	Cost    float64     `json:"cost"`
+
+	// value is the new value as the model reads it
+	value interface{}
+}
+
+// Counterfactual is a set of changes after which the model predicts another class
+type Counterfactual struct {
+	Prediction string   `json:"prediction"`
+	Changes    []Change `json:"changes"`
+	Cost       float64  `json:"cost"`
+}
+
+// Counterfactuals finds the cheapest changes to an instance that change its
+// predicted class. Every leaf of a target class yields the minimal threshold
+// and category moves that lead the instance to it, and the candidates are
+// sorted by total cost. For ensembles the leaves of every tree are tried and
+// only the candidates that change the ensemble's prediction are kept, so
+// cheaper combinations across trees may be missed.
+func Counterfactuals(model *t.Model, instance t.Instance, options CounterfactualOptions) ([]Counterfactual, error) {
+	if err := ValidateCounterfactualOptions(model, options); err != nil {
+		return nil, err
+	}
+
+	current := predict.PredictClass(model, instance)
+	if options.Target != "" && options.Target == current {
+		return nil, fmt.Errorf("%w '%s'", ErrAlreadyPredicted, current)
+	}
+
+	trees, _ := shapTrees(model)
+	seen := make(map[string]bool)
+	counterfactuals := []Counterfactual{}
+	for _, tree := range trees {
+		var walk func(node *t.Node, constraints map[string]*constraint)
+		walk = func(node *t.Node, constraints map[string]*constraint) {
+			if node.IsLeaf {
+				class := predict.TreeClass(model, node, t.Instance{})
+				if class == current || (options.Target != "" && class != options.Target) {
+					return
+				}
+				counterfactual, ok := moveTo(model, instance, constraints, options)
+				if !ok {
+					return
+				}
+				key := counterfactualKey(counterfactual)
+				if seen[key] {
+					return
+				}
+				seen[key] = true
+
+				// Check the prediction of the whole model for the changed instance
+				counterfactual.Prediction = predict.PredictClass(model, applyChanges(instance, counterfactual.Changes))
+				if counterfactual.Prediction == current || (options.Target != "" && counterfactual.Prediction != options.Target) {
+					return
+				}
+				counterfactuals = append(counterfactuals, counterfactual)
+				return
+			}
+
+			for i, child := range node.Children {
+				narrowed, ok := narrow(node, i, constraints)
+				if ok {
+					walk(child, narrowed)
+				}
+			}
+		}
+		walk(tree, map[string]*constraint{})
+	}
+
+	sort.SliceStable(counterfactuals, func(i, j int) bool {
+		if counterfactuals[i].Cost != counterfactuals[j].Cost {
+			return counterfactuals[i].Cost < counterfactuals[j].Cost
+		}
+		return len(counterfactuals[i].Changes) < len(counterfactuals[j].Changes)
+	})
+	if options.Limit > 0 && len(counterfactuals) > options.Limit {
+		counterfactuals = counterfactuals[:options.Limit]
+	}
+	return counterfactuals, nil
+}
+
+// constraint holds the values of a feature that lead to a node: the
+// interval lower < x <= upper for continuous features, or one of allowed
+// for categorical features
+type constraint struct {
+	continuous bool
+	lower      float64
+	upper      float64
+	allowed    []string
+}
+
+// ValidateCounterfactualOptions checks that the model is a classification
+// model, that the target is one of its classes and that no cost is negative
+func ValidateCounterfactualOptions(model *t.Model, options CounterfactualOptions) error {
+	if model.Task == t.Regression {
+		return fmt.Errorf("counterfactuals need a classification model")
+	}
+	if options.Target != "" && !utils.Contains(Outputs(model), options.Target) {
+		return fmt.Errorf("unknown target class '%s'", options.Target)
+	}
+	for feature, cost := range options.Costs {
+		if cost < 0 {
+			return fmt.Errorf("invalid cost %v for feature '%s'", cost, feature)
+		}
+	}
+	return nil
+}
+
+// narrow adds the test leading from node to its child at index to the
+// constraints. It returns false when no value passes all tests, or when the
+// child is only reached by values unseen in training.
+func narrow(node *t.Node, index int, constraints map[string]*constraint) (map[string]*constraint, bool) {
+	child := node.Children[index]
+	narrowed := make(map[string]*constraint, len(constraints)+1)
+	for feature, c := range constraints {
+		copied := *c
+		narrowed[feature] = &copied
+	}
+
+	c, ok := narrowed[node.Feature]
+	if !ok {
+		c = &constraint{continuous: node.Continuous, lower: math.Inf(-1), upper: math.Inf(1)}
+		narrowed[node.Feature] = c
+	}
+
+	if node.Continuous {
+		if index == 0 {
+			c.upper = math.Min(c.upper, node.Threshold)
+		} else {
+			c.lower = math.Max(c.lower, node.Threshold)
+		}
+		return narrowed, c.lower < c.upper
+	}
+
+	values := child.Values
+	if len(values) == 0 {
+		value := fmt.Sprintf("%v", child.Value)
+		if value == "unknown" {
+			return nil, false
+		}
+		values = []string{value}
+	}
+	if c.allowed == nil {
+		c.allowed = append([]string(nil), values...)
+	} else {
+		var both []string
+		for _, value := range c.allowed {
+			if utils.Contains(values, value) {
+				both = append(both, value)
+			}
+		}
+		c.allowed = both
+	}
+	return narrowed, len(c.allowed) > 0
+}
+
+// moveTo computes the cheapest changes that satisfy the constraints of a
+// leaf. It returns false when an immutable feature would have to change.
+func moveTo(model *t.Model, instance t.Instance, constraints map[string]*constraint, options CounterfactualOptions) (Counterfactual, bool) {
+	features := make([]string, 0, len(constraints))
+	for feature := range constraints {
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+
+	counterfactual := Counterfactual{Changes: []Change{}}
+	for _, feature := range features {
+		change, changed := moveFeature(model, instance, feature, constraints[feature])
+		if !changed {
+			continue
+		}
+		if utils.Contains(options.Immutable, feature) {
+			return Counterfactual{}, false
+		}
+
+		cost, ok := options.Costs[feature]
+		if !ok {
+			cost = 1
+		}
+		change.Cost *= cost
+		counterfactual.Changes = append(counterfactual.Changes, change)
+		counterfactual.Cost += change.Cost
+	}
+	return counterfactual, true
+}
+
+// moveFeature returns the smallest change of a feature that satisfies its
+// constraint, with the distance moved as its cost, and false when the
+// current value already does
+func moveFeature(model *t.Model, instance t.Instance, feature string, c *constraint) (Change, bool) {
+	value, present := instance[feature]
+	if value == nil {
+		present = false
+	}
+	featureType := model.FeatureTypes[feature]
+
+	if !c.continuous {
+		if present && utils.Contains(c.allowed, fmt.Sprintf("%v", value)) {
+			return Change{}, false
+		}
+		allowed := append([]string(nil), c.allowed...)
+		sort.Strings(allowed)
+		test := feature + " = " + allowed[0]
+		if len(allowed) > 1 {
+			test = feature + " in {" + strings.Join(allowed, ", ") + "}"
+		}
+		return Change{Feature: feature, From: value, To: allowed[0], Test: test, Cost: 1, value: allowed[0]}, true
+	}
+
+	x, numeric := split.ExtractNumericValue(value)
+	if present && !numeric {
+		if parsed, err := strconv.ParseFloat(fmt.Sprintf("%v", value), 64); err == nil {
+			x, numeric = parsed, true
+		}
+	}
+	if numeric && x > c.lower && x <= c.upper {
+		return Change{}, false
+	}
+
+	var to float64
+	switch {
+	case !numeric && !math.IsInf(c.upper, 1):
+		to = c.upper
+		if !math.IsInf(c.lower, -1) {
+			to = above(c.lower, c.upper, featureType)
+		}
+	case !numeric:
+		to = above(c.lower, c.upper, featureType)
+	case x <= c.lower:
+		to = above(c.lower, c.upper, featureType)
+	default:
+		to = c.upper
+	}
+
+	cost := 1.0
+	if numeric {
+		cost = math.Abs(to - x)
+		if featureType == "date" || featureType == "timestamp" {
+			cost /= secondsPerDay
+		}
+	}
+
+	change := Change{
+		Feature: feature,
+		From:    value,
+		To:      to,
+		Test:    intervalTest(feature, c, featureType),
+		Cost:    cost,
+		value:   to,
+	}
+	if featureType == "date" || featureType == "timestamp" {
+		change.To = formatThreshold(to, featureType)
+		change.value = time.Unix(int64(to), 0).UTC()
+	}
+	return change, true
+}
+
+// above returns the smallest round value greater than lower, at the
+// precision of lower: the next day for dates, the next second for
+// timestamps, and the next value in the last decimal place of lower for
+// numbers. It falls back to the middle of the interval when that value
+// would pass upper.
+func above(lower, upper float64, featureType string) float64 {
+	var next float64
+	switch featureType {
+	case "date":
+		next = (math.Floor(lower/secondsPerDay) + 1) * secondsPerDay
+	case "timestamp":
+		next = math.Floor(lower) + 1
+	default:
+		decimals := 0
+		if formatted := strconv.FormatFloat(lower, 'f', -1, 64); strings.Contains(formatted, ".") {
+			decimals = len(formatted) - strings.Index(formatted, ".") - 1
+		}
+		if decimals > 6 {
+			decimals = 6
+		}
+		scale := math.Pow(10, float64(decimals))
+		next = (math.Floor(lower*scale) + 1) / scale
+	}
+
+	if next > upper {
+		return (lower + upper) / 2
+	}
+	return next
+}
+
+// intervalTest renders the interval of a continuous constraint
+func intervalTest(feature string, c *constraint, featureType string) string {
+	switch {
+	case math.IsInf(c.lower, -1):
+		return feature + " <= " + formatThreshold(c.upper, featureType)
+	case math.IsInf(c.upper, 1):
+		return feature + " > " + formatThreshold(c.lower, featureType)
+	}
+	return formatThreshold(c.lower, featureType) + " < " + feature + " <= " + formatThreshold(c.upper, featureType)
+}
+
+// applyChanges returns a copy of the instance with the changes applied
+func applyChanges(instance t.Instance, changes []Change) t.Instance {
+	changed := make(t.Instance, len(instance))
+	for key, value := range instance {
+		changed[key] = value
+	}
+	for _, change := range changes {
+		changed[change.Feature] = change.value
+	}
+	return changed
+}
+
+// counterfactualKey identifies a set of changes
+func counterfactualKey(counterfactual Counterfactual) string {
+	parts := make([]string, len(counterfactual.Changes))
+	for i, change := range counterfactual.Changes {
+		parts[i] = fmt.Sprintf("%s=%v", change.Feature, change.To)
+	}
+	return strings.Join(parts, "\x00")
+}
@@ -0,0 +1,161 @@
+package explain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/predict"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounterfactuals(tt *testing.T) {
+	model := creditModel()
+
+	tests := []struct {
+		name     string
+		instance t.Instance
+		options  CounterfactualOptions
+		want     []Change
+		cost     float64
+	}{
+		{
+			name:     "Smallest threshold move",
+			instance: t.Instance{"income": 30000.0, "age": 25.0, "region": "EU"},
+			want:     []Change{{Feature: "income", From: 30000.0, To: 52001.0, Test: "52000 < income <= 80000", Cost: 22001}},
+			cost:     22001,
+		},
+		{
+			name:     "Feature costs",
+			instance: t.Instance{"income": 30000.0, "age": 25.0, "region": "EU"},
+			options:  CounterfactualOptions{Costs: map[string]float64{"income": 0.001}},
+			want:     []Change{{Feature: "income", From: 30000.0, To: 52001.0, Test: "52000 < income <= 80000", Cost: 22.001}},
+			cost:     22.001,
+		},
+		{
+			name:     "Target class",
+			instance: t.Instance{"income": 95000.0, "age": 45.0, "region": "US"},
+			options:  CounterfactualOptions{Target: "reject"},
+			want:     []Change{{Feature: "income", From: 95000.0, To: 80000.0, Test: "52000 < income <= 80000", Cost: 15000}},
+			cost:     15000,
+		},
+		{
+			name:     "Missing value is filled in",
+			instance: t.Instance{"income": 60000.0, "age": 45.0},
+			want:     []Change{{Feature: "region", From: nil, To: "CA", Test: "region in {CA, US}", Cost: 1}},
+			cost:     1,
+		},
+	}
+
+	for _, tc := range tests {
+		tt.Run(tc.name, func(tt *testing.T) {
+			counterfactuals, err := Counterfactuals(model, tc.instance, tc.options)
+			assert.NoError(tt, err)
+			if assert.NotEmpty(tt, counterfactuals) {
+				best := counterfactuals[0]
+				for i := range best.Changes {
+					best.Changes[i].value = nil
+				}
+				assert.Equal(tt, tc.want, best.Changes)
+				assert.InDelta(tt, tc.cost, best.Cost, 1e-9)
+			}
+			for i := 1; i < len(counterfactuals); i++ {
+				assert.LessOrEqual(tt, counterfactuals[i-1].Cost, counterfactuals[i].Cost)
+			}
+		})
+	}
+}
+
+func TestCounterfactuals_ImmutableFeatures(tt *testing.T) {
+	model := creditModel()
+	instance := t.Instance{"income": 30000.0, "age": 25.0, "region": "EU"}
+
+	counterfactuals, err := Counterfactuals(model, instance, CounterfactualOptions{Immutable: []string{"income"}})
+	assert.NoError(tt, err)
+	assert.Empty(tt, counterfactuals)
+
+	counterfactuals, err = Counterfactuals(model, t.Instance{"income": 95000.0, "age": 45.0, "region": "US"}, CounterfactualOptions{Immutable: []string{"age"}, Limit: 1})
+	assert.NoError(tt, err)
+	assert.Len(tt, counterfactuals, 1)
+	for _, change := range counterfactuals[0].Changes {
+		assert.NotEqual(tt, "age", change.Feature)
+	}
+}
+
+func TestCounterfactuals_Dates(tt *testing.T) {
+	threshold := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	model := &t.Model{
+		FeatureTypes: map[string]string{"signup": "date", "plan": "categorical"},
+		Task:         t.Classification,
+		Root: &t.Node{
+			Feature:    "signup",
+			Continuous: true,
+			Threshold:  float64(threshold.Unix()),
+			Children: []*t.Node{
+				{
+					Feature: "plan",
+					Binary:  true,
+					Children: []*t.Node{
+						{IsLeaf: true, Class: "stay", Values: []string{"silver", "gold"}},
+						{IsLeaf: true, Class: "churn", Values: []string{"basic"}},
+					},
+				},
+				{IsLeaf: true, Class: "stay"},
+			},
+		},
+	}
+	instance := t.Instance{"signup": time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), "plan": "basic"}
+
+	counterfactuals, err := Counterfactuals(model, instance, CounterfactualOptions{})
+	assert.NoError(tt, err)
+	assert.Len(tt, counterfactuals, 2)
+	assert.Equal(tt, "plan in {gold, silver}", counterfactuals[0].Changes[0].Test)
+	assert.Equal(tt, "gold", counterfactuals[0].Changes[0].To)
+	assert.Equal(tt, "signup > 2024-03-01", counterfactuals[1].Changes[0].Test)
+	assert.Equal(tt, "2024-03-02", counterfactuals[1].Changes[0].To)
+	assert.InDelta(tt, 30, counterfactuals[1].Cost, 1e-9)
+	assert.Equal(tt, "stay", counterfactuals[1].Prediction)
+	assert.Equal(tt, "stay", predict.PredictClass(model, applyChanges(instance, counterfactuals[1].Changes)))
+}
+
+func TestCounterfactuals_Ensemble(tt *testing.T) {
+	single := creditModel()
+	second := &t.Node{
+		Feature:    "age",
+		Continuous: true,
+		Threshold:  40,
+		Children: []*t.Node{
+			leaf("reject", map[string]float64{"reject": 30}),
+			leaf("approve", map[string]float64{"approve": 38}),
+		},
+	}
+	model := &t.Model{
+		Task:     t.Classification,
+		Ensemble: &t.Ensemble{Method: t.Boost, Trees: []*t.Node{single.Root, second}, Weights: []float64{1, 0.5}},
+	}
+	instance := t.Instance{"income": 30000.0, "age": 25.0, "region": "EU"}
+
+	counterfactuals, err := Counterfactuals(model, instance, CounterfactualOptions{})
+	assert.NoError(tt, err)
+	assert.NotEmpty(tt, counterfactuals)
+	for _, counterfactual := range counterfactuals {
+		assert.Equal(tt, "approve", predict.PredictClass(model, applyChanges(instance, counterfactual.Changes)))
+	}
+}
+
+func TestCounterfactuals_Errors(tt *testing.T) {
+	model := creditModel()
+	instance := t.Instance{"income": 30000.0, "age": 25.0, "region": "EU"}
+
+	_, err := Counterfactuals(model, instance, CounterfactualOptions{Target: "reject"})
+	assert.ErrorIs(tt, err, ErrAlreadyPredicted)
+	_, err = Counterfactuals(model, instance, CounterfactualOptions{Target: "maybe"})
+	assert.Error(tt, err)
+	assert.NotErrorIs(tt, err, ErrAlreadyPredicted)
+	assert.Error(tt, ValidateCounterfactualOptions(model, CounterfactualOptions{Target: "maybe"}))
+	assert.NoError(tt, ValidateCounterfactualOptions(model, CounterfactualOptions{Target: "reject"}))
+	_, err = Counterfactuals(model, instance, CounterfactualOptions{Costs: map[string]float64{"age": -1}})
+	assert.Error(tt, err)
+	_, err = Counterfactuals(&t.Model{Task: t.Regression, Root: &t.Node{IsLeaf: true}}, instance, CounterfactualOptions{})
+	assert.Error(tt, err)
+}
@@ -0,0 +1,173 @@
+package explain
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/predict"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+)
+
+// NodeStats holds the training statistics of a node
+type NodeStats struct {
+	Samples     float64            `json:"samples"`
+	ClassCounts map[string]float64 `json:"class_counts,omitempty"`
+	Mean        *float64           `json:"mean,omitempty"` // regression trees only
+}
+
+// Step is one test taken on the way down a tree
+type Step struct {
+	Feature string      `json:"feature"`
+	Value   interface{} `json:"value"` // the instance's value of the feature
+	Test    string      `json:"test"`  // for example "income > 52000" or "region = EU"
+	NodeStats
+	// Fallback is "missing" when the value was missing and the path stops
+	// here, or "unknown" when an unseen value took the fallback branch
+	Fallback string `json:"fallback,omitempty"`
+}
+
+// Path is the route of an instance through one tree
+type Path struct {
+	Steps []Step `json:"path"`
+	// Leaf holds the statistics of the node the path ends at, which is a
+	// decision node when a missing value stopped it
+	Leaf     NodeStats `json:"leaf"`
+	Fallback bool      `json:"fallback"` // whether any step used a fallback
+	Weight   float64   `json:"weight,omitempty"`
+}
+
+// Explanation explains the prediction for one instance. Single trees have
+// one path; ensembles list the path through each of their trees.
+type Explanation struct {
+	Prediction string     `json:"prediction"`
+	Steps      []Step     `json:"path,omitempty"`
+	Leaf       *NodeStats `json:"leaf,omitempty"`
+	Fallback   bool       `json:"fallback"`
+	Trees      []Path     `json:"trees,omitempty"`
+}
+
+// Explain predicts an instance and records the path it took through the model
+func Explain(model *t.Model, instance t.Instance) Explanation {
+	explanation := Explanation{Prediction: predict.Predict(model, instance)}
+	if model.Ensemble == nil {
+		path := TreePath(model, model.Root, instance)
+		explanation.Steps = path.Steps
+		explanation.Leaf = &path.Leaf
+		explanation.Fallback = path.Fallback
+		return explanation
+	}
+
+	explanation.Trees = make([]Path, len(model.Ensemble.Trees))
+	for i, tree := range model.Ensemble.Trees {
+		explanation.Trees[i] = TreePath(model, tree, instance)
+		if model.Ensemble.Weights != nil {
+			explanation.Trees[i].Weight = model.Ensemble.Weights[i]
+		}
+		explanation.Fallback = explanation.Fallback || explanation.Trees[i].Fallback
+	}
+	return explanation
+}
+
+// TreePath follows an instance down one tree of the model
+func TreePath(model *t.Model, root *t.Node, instance t.Instance) Path {
+	path := Path{Steps: []Step{}}
+	node := root
+	for !node.IsLeaf {
+		child, fallback := predict.Route(node, instance)
+		step := Step{
+			Feature:   node.Feature,
+			Value:     instance[node.Feature],
+			NodeStats: stats(model, node),
+			Fallback:  fallback,
+		}
+		if child == nil {
+			step.Test = node.Feature + " is missing"
+		} else {
+			step.Test = describe(model, node, child, instance)
+		}
+		path.Steps = append(path.Steps, step)
+		path.Fallback = path.Fallback || fallback != ""
+
+		if child == nil {
+			break
+		}
+		node = child
+	}
+
+	path.Leaf = stats(model, node)
+	return path
+}
+
+// Batch explains the instances in parallel
+func Batch(model *t.Model, instances []t.Instance) []Explanation {
+	explanations := make([]Explanation, len(instances))
+
+	instancesChan := make(chan int, len(instances))
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range instancesChan {
+				explanations[idx] = Explain(model, instances[idx])
+			}
+		}()
+	}
+	for i := range instances {
+		instancesChan <- i
+	}
+	close(instancesChan)
+	wg.Wait()
+
+	return explanations
+}
+
+// stats returns the training statistics of a node
+func stats(model *t.Model, node *t.Node) NodeStats {
+	nodeStats := NodeStats{Samples: node.Samples, ClassCounts: node.ClassCounts}
+	if model.Task == t.Regression {
+		mean := node.Mean
+		nodeStats.Mean = &mean
+	}
+	return nodeStats
+}
+
+// describe renders the test that sent an instance from node to child
+func describe(model *t.Model, node, child *t.Node, instance t.Instance) string {
+	feature := node.Feature
+	if node.Continuous {
+		threshold := formatThreshold(node.Threshold, model.FeatureTypes[feature])
+		if child == node.Children[0] {
+			return feature + " <= " + threshold
+		}
+		return feature + " > " + threshold
+	}
+
+	switch {
+	case node.Binary && child == node.Children[1]:
+		return feature + " not in {" + strings.Join(node.Children[0].Values, ", ") + "}"
+	case len(child.Values) > 1:
+		return feature + " in {" + strings.Join(child.Values, ", ") + "}"
+	case len(child.Values) == 1:
+		return feature + " = " + child.Values[0]
+	}
+	if fmt.Sprintf("%v", child.Value) == "unknown" {
+		return fmt.Sprintf("%s = %v (unseen in training)", feature, instance[feature])
+	}
+	return fmt.Sprintf("%s = %v", feature, child.Value)
+}
+
+// formatThreshold prints a threshold, as a time for date features
+func formatThreshold(threshold float64, featureType string) string {
+	switch featureType {
+	case "date":
+		return time.Unix(int64(threshold), 0).UTC().Format("2006-01-02")
+	case "timestamp":
+		return time.Unix(int64(threshold), 0).UTC().Format(time.RFC3339)
+	}
+	return strconv.FormatFloat(threshold, 'g', -1, 64)
+}
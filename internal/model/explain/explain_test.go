@@ -0,0 +1,213 @@
+package explain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// loanModel splits on income at the root and on region below it
+func loanModel() *t.Model {
+	return &t.Model{
+		FeatureNames: []string{"income", "region", "label"},
+		FeatureTypes: map[string]string{"income": "numerical", "region": "categorical"},
+		TargetName:   "label",
+		Task:         t.Classification,
+		Root: &t.Node{
+			Feature:     "income",
+			Continuous:  true,
+			Threshold:   52000,
+			Samples:     100,
+			ClassCounts: map[string]float64{"approve": 60, "reject": 40},
+			Children: []*t.Node{
+				{IsLeaf: true, Class: "reject", Samples: 40, ClassCounts: map[string]float64{"approve": 5, "reject": 35}},
+				{
+					Feature:     "region",
+					Samples:     60,
+					ClassCounts: map[string]float64{"approve": 55, "reject": 5},
+					Children: []*t.Node{
+						{IsLeaf: true, Class: "approve", Value: "EU", Samples: 30, ClassCounts: map[string]float64{"approve": 30}},
+						{IsLeaf: true, Class: "approve", Values: []string{"US", "CA"}, Samples: 20, ClassCounts: map[string]float64{"approve": 18, "reject": 2}},
+						{IsLeaf: true, Class: "reject", Value: "unknown", Samples: 10, ClassCounts: map[string]float64{"approve": 7, "reject": 3}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestExplain(tt *testing.T) {
+	model := loanModel()
+
+	tests := []struct {
+		name     string
+		instance t.Instance
+		want     Explanation
+	}{
+		{
+			name:     "Continuous and categorical tests",
+			instance: t.Instance{"income": 60000.0, "region": "EU"},
+			want: Explanation{
+				Prediction: "approve",
+				Steps: []Step{
+					{Feature: "income", Value: 60000.0, Test: "income > 52000", NodeStats: NodeStats{Samples: 100, ClassCounts: map[string]float64{"approve": 60, "reject": 40}}},
+					{Feature: "region", Value: "EU", Test: "region = EU", NodeStats: NodeStats{Samples: 60, ClassCounts: map[string]float64{"approve": 55, "reject": 5}}},
+				},
+				Leaf: &NodeStats{Samples: 30, ClassCounts: map[string]float64{"approve": 30}},
+			},
+		},
+		{
+			name:     "Value group",
+			instance: t.Instance{"income": 60000.0, "region": "CA"},
+			want: Explanation{
+				Prediction: "approve",
+				Steps: []Step{
+					{Feature: "income", Value: 60000.0, Test: "income > 52000", NodeStats: NodeStats{Samples: 100, ClassCounts: map[string]float64{"approve": 60, "reject": 40}}},
+					{Feature: "region", Value: "CA", Test: "region in {US, CA}", NodeStats: NodeStats{Samples: 60, ClassCounts: map[string]float64{"approve": 55, "reject": 5}}},
+				},
+				Leaf: &NodeStats{Samples: 20, ClassCounts: map[string]float64{"approve": 18, "reject": 2}},
+			},
+		},
+		{
+			name:     "Unseen value takes the unknown branch",
+			instance: t.Instance{"income": 60000.0, "region": "APAC"},
+			want: Explanation{
+				Prediction: "reject",
+				Steps: []Step{
+					{Feature: "income", Value: 60000.0, Test: "income > 52000", NodeStats: NodeStats{Samples: 100, ClassCounts: map[string]float64{"approve": 60, "reject": 40}}},
+					{Feature: "region", Value: "APAC", Test: "region = APAC (unseen in training)", NodeStats: NodeStats{Samples: 60, ClassCounts: map[string]float64{"approve": 55, "reject": 5}}, Fallback: "unknown"},
+				},
+				Leaf:     &NodeStats{Samples: 10, ClassCounts: map[string]float64{"approve": 7, "reject": 3}},
+				Fallback: true,
+			},
+		},
+		{
+			name:     "Missing value stops the path",
+			instance: t.Instance{"income": 60000.0},
+			want: Explanation{
+				Prediction: "approve",
+				Steps: []Step{
+					{Feature: "income", Value: 60000.0, Test: "income > 52000", NodeStats: NodeStats{Samples: 100, ClassCounts: map[string]float64{"approve": 60, "reject": 40}}},
+					{Feature: "region", Test: "region is missing", NodeStats: NodeStats{Samples: 60, ClassCounts: map[string]float64{"approve": 55, "reject": 5}}, Fallback: "missing"},
+				},
+				Leaf:     &NodeStats{Samples: 60, ClassCounts: map[string]float64{"approve": 55, "reject": 5}},
+				Fallback: true,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tt.Run(tc.name, func(tt *testing.T) {
+			assert.Equal(tt, tc.want, Explain(model, tc.instance))
+		})
+	}
+}
+
+func TestExplain_BinaryAndDateTests(tt *testing.T) {
+	threshold := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	model := &t.Model{
+		FeatureTypes: map[string]string{"signup": "date", "plan": "categorical"},
+		Task:         t.Classification,
+		Root: &t.Node{
+			Feature:    "signup",
+			Continuous: true,
+			Threshold:  float64(threshold.Unix()),
+			Children: []*t.Node{
+				{
+					Feature: "plan",
+					Binary:  true,
+					Children: []*t.Node{
+						{IsLeaf: true, Class: "stay", Values: []string{"gold", "silver"}},
+						{IsLeaf: true, Class: "churn", Values: []string{"basic"}},
+					},
+				},
+				{IsLeaf: true, Class: "stay"},
+			},
+		},
+	}
+
+	explanation := Explain(model, t.Instance{"signup": threshold.AddDate(0, -1, 0), "plan": "basic"})
+	assert.Equal(tt, "churn", explanation.Prediction)
+	assert.Equal(tt, "signup <= 2024-03-01", explanation.Steps[0].Test)
+	assert.Equal(tt, "plan not in {gold, silver}", explanation.Steps[1].Test)
+	assert.False(tt, explanation.Fallback)
+
+	explanation = Explain(model, t.Instance{"signup": threshold.AddDate(0, -1, 0), "plan": "trial"})
+	assert.Equal(tt, "churn", explanation.Prediction)
+	assert.Equal(tt, "unknown", explanation.Steps[1].Fallback)
+	assert.True(tt, explanation.Fallback)
+}
+
+func TestExplain_Regression(tt *testing.T) {
+	model := &t.Model{
+		Task: t.Regression,
+		Root: &t.Node{
+			Feature:    "size",
+			Continuous: true,
+			Threshold:  80.5,
+			Samples:    10,
+			Mean:       250,
+			Children: []*t.Node{
+				{IsLeaf: true, Samples: 6, Mean: 150},
+				{IsLeaf: true, Samples: 4, Mean: 400},
+			},
+		},
+	}
+
+	explanation := Explain(model, t.Instance{"size": 120.0})
+	assert.Equal(tt, "400", explanation.Prediction)
+	assert.Equal(tt, "size > 80.5", explanation.Steps[0].Test)
+	assert.Equal(tt, 250.0, *explanation.Steps[0].Mean)
+	assert.Equal(tt, 400.0, *explanation.Leaf.Mean)
+}
+
+func TestExplain_Ensemble(tt *testing.T) {
+	single := loanModel()
+	model := &t.Model{
+		FeatureTypes: single.FeatureTypes,
+		TargetName:   single.TargetName,
+		Task:         t.Classification,
+		Ensemble: &t.Ensemble{
+			Method:  t.Boost,
+			Trees:   []*t.Node{single.Root, single.Root.Children[1]},
+			Weights: []float64{2, 0.5},
+		},
+	}
+
+	explanation := Explain(model, t.Instance{"income": 60000.0, "region": "US"})
+	assert.Equal(tt, "approve", explanation.Prediction)
+	assert.Nil(tt, explanation.Steps)
+	assert.Nil(tt, explanation.Leaf)
+	assert.Len(tt, explanation.Trees, 2)
+	assert.Len(tt, explanation.Trees[0].Steps, 2)
+	assert.Len(tt, explanation.Trees[1].Steps, 1)
+	assert.Equal(tt, 2.0, explanation.Trees[0].Weight)
+	assert.Equal(tt, 0.5, explanation.Trees[1].Weight)
+	assert.Equal(tt, "region in {US, CA}", explanation.Trees[1].Steps[0].Test)
+}
+
+func TestBatch(tt *testing.T) {
+	model := loanModel()
+	instances := []t.Instance{
+		{"income": 30000.0, "region": "EU"},
+		{"income": 90000.0, "region": "EU"},
+	}
+
+	explanations := Batch(model, instances)
+	assert.Len(tt, explanations, 2)
+	assert.Equal(tt, "reject", explanations[0].Prediction)
+	assert.Equal(tt, "income <= 52000", explanations[0].Steps[0].Test)
+	assert.Equal(tt, "approve", explanations[1].Prediction)
+
+	encoded, err := json.Marshal(explanations[0])
+	assert.NoError(tt, err)
+	assert.JSONEq(tt, `{
+		"prediction": "reject",
+		"path": [{"feature": "income", "value": 30000, "test": "income <= 52000", "samples": 100, "class_counts": {"approve": 60, "reject": 40}}],
+		"leaf": {"samples": 40, "class_counts": {"approve": 5, "reject": 35}},
+		"fallback": false
+	}`, string(encoded))
+}
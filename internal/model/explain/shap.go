@@ -0,0 +1,445 @@
+package explain
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/predict"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+)
+
+// RegressionOutput names the single output of regression models
+const RegressionOutput = "value"
+
+// Attribution holds the SHAP values of one prediction. For every output (a
+// class, or "value" for regression) the base value plus the contributions of
+// all features adds up to the model's output for the instance: the predicted
+// probability of the class, or the predicted value.
+type Attribution struct {
+	Base   map[string]float64            `json:"base"`   // expected output over the training data
+	Values map[string]map[string]float64 `json:"values"` // output -> feature -> contribution
+}
+
+// SHAP computes the exact TreeSHAP values of an instance (Lundberg et al.,
+// "Consistent Individualized Feature Attribution for Tree Ensembles").
+// Classification trees are explained over their node class distributions;
+// ensembles over their averaged votes, so the outputs sum to the
+// probabilities reported by predict.PredictProbabilities. An instance whose
+// value is missing at a decision node takes that node's own output, as in
+// prediction. Linear leaves are explained by their mean.
+func SHAP(model *t.Model, instance t.Instance) Attribution {
+	outputs := Outputs(model)
+	features := ModelFeatures(model)
+	return attribute(model, instance, outputs, features)
+}
+
+// SHAPBatch computes the SHAP values of the instances in parallel
+func SHAPBatch(model *t.Model, instances []t.Instance) []Attribution {
+	outputs := Outputs(model)
+	features := ModelFeatures(model)
+	attributions := make([]Attribution, len(instances))
+
+	instancesChan := make(chan int, len(instances))
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range instancesChan {
+				attributions[idx] = attribute(model, instances[idx], outputs, features)
+			}
+		}()
+	}
+	for i := range instances {
+		instancesChan <- i
+	}
+	close(instancesChan)
+	wg.Wait()
+
+	return attributions
+}
+
+// Outputs returns the outputs explained by SHAP: the sorted classes the
+// model's trees know, or "value" for regression models
+func Outputs(model *t.Model) []string {
+	if model.Task == t.Regression {
+		return []string{RegressionOutput}
+	}
+
+	classSet := make(map[string]bool)
+	var collect func(node *t.Node)
+	collect = func(node *t.Node) {
+		if node == nil {
+			return
+		}
+		for class := range node.ClassCounts {
+			classSet[class] = true
+		}
+		if node.IsLeaf && node.Class != "" {
+			classSet[node.Class] = true
+		}
+		for _, child := range node.Children {
+			collect(child)
+		}
+	}
+	trees, _ := shapTrees(model)
+	for _, tree := range trees {
+		collect(tree)
+	}
+
+	classes := make([]string, 0, len(classSet))
+	for class := range classSet {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	return classes
+}
+
+// ModelFeatures returns the features of a model in training order, followed
+// by any feature its trees split on that is not among them
+func ModelFeatures(model *t.Model) []string {
+	seen := make(map[string]bool)
+	features := make([]string, 0, len(model.FeatureNames))
+	for _, feature := range model.FeatureNames {
+		if feature != model.TargetName && feature != model.WeightColumn && !seen[feature] {
+			seen[feature] = true
+			features = append(features, feature)
+		}
+	}
+
+	var extra []string
+	var collect func(node *t.Node)
+	collect = func(node *t.Node) {
+		if node == nil || node.IsLeaf {
+			return
+		}
+		if !seen[node.Feature] {
+			seen[node.Feature] = true
+			extra = append(extra, node.Feature)
+		}
+		for _, child := range node.Children {
+			collect(child)
+		}
+	}
+	trees, _ := shapTrees(model)
+	for _, tree := range trees {
+		collect(tree)
+	}
+	sort.Strings(extra)
+	return append(features, extra...)
+}
+
+// shapTrees returns the trees of a model and their share of its output.
+// Classification ensembles normalise their votes, so the shares follow the
+// tree weights; regression ensembles average their trees.
+func shapTrees(model *t.Model) ([]*t.Node, []float64) {
+	if model.Ensemble == nil {
+		return []*t.Node{model.Root}, []float64{1}
+	}
+
+	trees := model.Ensemble.Trees
+	shares := make([]float64, len(trees))
+	total := 0.0
+	for i := range trees {
+		shares[i] = 1
+		if model.Task != t.Regression && model.Ensemble.Weights != nil {
+			shares[i] = model.Ensemble.Weights[i]
+		}
+		total += shares[i]
+	}
+	for i := range shares {
+		if total > 0 {
+			shares[i] /= total
+		}
+	}
+	return trees, shares
+}
+
+// attribute computes the SHAP values of an instance over all trees
+func attribute(model *t.Model, instance t.Instance, outputs, features []string) Attribution {
+	outputIndex := make(map[string]int, len(outputs))
+	for i, output := range outputs {
+		outputIndex[output] = i
+	}
+
+	base := make([]float64, len(outputs))
+	phi := make(map[string][]float64, len(features))
+	for _, feature := range features {
+		phi[feature] = make([]float64, len(outputs))
+	}
+
+	trees, shares := shapTrees(model)
+	for i, tree := range trees {
+		if tree == nil {
+			continue
+		}
+		s := treeSHAP{
+			model:       model,
+			instance:    instance,
+			outputIndex: outputIndex,
+			share:       shares[i],
+			phi:         phi,
+		}
+		addScaled(base, s.expectation(tree), 1)
+		s.recurse(tree, nil, 1, 1, "")
+	}
+
+	attribution := Attribution{
+		Base:   make(map[string]float64, len(outputs)),
+		Values: make(map[string]map[string]float64, len(outputs)),
+	}
+	for o, output := range outputs {
+		attribution.Base[output] = base[o]
+		values := make(map[string]float64, len(features))
+		for _, feature := range features {
+			values[feature] = phi[feature][o]
+		}
+		attribution.Values[output] = values
+	}
+	return attribution
+}
+
+// pathElement is one feature on the path from the root to the current node.
+// zero is the fraction of the paths through the node taken when the feature
+// is left out, one whether the instance takes it when the feature is known.
+type pathElement struct {
+	feature string
+	zero    float64
+	one     float64
+	weight  float64
+}
+
+// treeSHAP holds the state of the TreeSHAP recursion over one tree
+type treeSHAP struct {
+	model       *t.Model
+	instance    t.Instance
+	outputIndex map[string]int
+	share       float64
+	phi         map[string][]float64
+}
+
+// recurse walks the tree, extending the path with the split that led to node
+func (s *treeSHAP) recurse(node *t.Node, parent []pathElement, zero, one float64, feature string) {
+	path := extendPath(parent, zero, one, feature)
+
+	if node.IsLeaf {
+		s.addLeaf(node, path)
+		return
+	}
+
+	hot, _ := predict.Route(node, s.instance)
+	covers, total := childCovers(node)
+
+	// A feature split on again above contributes once, so undo its last split
+	incomingZero, incomingOne := 1.0, 1.0
+	for k := 1; k < len(path); k++ {
+		if path[k].feature == node.Feature {
+			incomingZero, incomingOne = path[k].zero, path[k].one
+			path = unwindPath(path, k)
+			break
+		}
+	}
+
+	if hot == nil {
+		// The instance stops here when the feature is known, and the node
+		// acts as a leaf that no path reaches when it is left out
+		s.recurseLeaf(node, path, 0, incomingOne, node.Feature)
+	}
+	for i, child := range node.Children {
+		childZero := incomingZero * covers[i] / total
+		childOne := 0.0
+		if child == hot {
+			childOne = incomingOne
+		}
+		if childZero == 0 && childOne == 0 {
+			continue
+		}
+		s.recurse(child, path, childZero, childOne, node.Feature)
+	}
+}
+
+// recurseLeaf ends a path at a decision node that the instance cannot leave
+func (s *treeSHAP) recurseLeaf(node *t.Node, parent []pathElement, zero, one float64, feature string) {
+	if zero == 0 && one == 0 {
+		return
+	}
+	s.addLeaf(node, extendPath(parent, zero, one, feature))
+}
+
+// addLeaf adds the contributions of every feature on the path to a leaf
+func (s *treeSHAP) addLeaf(node *t.Node, path []pathElement) {
+	value := s.nodeOutput(node)
+	for i := 1; i < len(path); i++ {
+		weight := unwoundPathSum(path, i) * (path[i].one - path[i].zero) * s.share
+		if weight == 0 {
+			continue
+		}
+		addScaled(s.phi[path[i].feature], value, weight)
+	}
+}
+
+// expectation returns the output of a tree averaged over its training
+// instances, weighted by share
+func (s *treeSHAP) expectation(node *t.Node) []float64 {
+	if node.IsLeaf {
+		result := make([]float64, len(s.outputIndex))
+		addScaled(result, s.nodeOutput(node), s.share)
+		return result
+	}
+
+	result := make([]float64, len(s.outputIndex))
+	covers, total := childCovers(node)
+	for i, child := range node.Children {
+		addScaled(result, s.expectation(child), covers[i]/total)
+	}
+	return result
+}
+
+// nodeOutput returns the output of a tree at a node: its class distribution,
+// or a vote for its class in majority voting ensembles, or its value
+func (s *treeSHAP) nodeOutput(node *t.Node) []float64 {
+	output := make([]float64, len(s.outputIndex))
+	if s.model.Task == t.Regression {
+		output[0] = predict.TreeValue(node, t.Instance{})
+		return output
+	}
+
+	if s.model.Ensemble != nil && s.model.Ensemble.Voting != t.ProbabilityVote {
+		if i, ok := s.outputIndex[predict.TreeClass(s.model, node, t.Instance{})]; ok {
+			output[i] = 1
+		}
+		return output
+	}
+	for class, p := range predict.TreeDistribution(s.model, node, t.Instance{}) {
+		if i, ok := s.outputIndex[class]; ok {
+			output[i] = p
+		}
+	}
+	return output
+}
+
+// childCovers returns the training weight reaching each child of a node and
+// their total. Children without any weight are covered equally when all are
+// empty.
+func childCovers(node *t.Node) ([]float64, float64) {
+	covers := make([]float64, len(node.Children))
+	total := 0.0
+	for i, child := range node.Children {
+		covers[i] = child.Samples
+		total += child.Samples
+	}
+	if total == 0 {
+		for i := range covers {
+			covers[i] = 1
+		}
+		total = float64(len(covers))
+	}
+	return covers, total
+}
+
+// extendPath returns a copy of the path with a new feature appended, updating
+// the weights of the subset sizes
+func extendPath(parent []pathElement, zero, one float64, feature string) []pathElement {
+	depth := len(parent)
+	path := make([]pathElement, depth+1, depth+2)
+	copy(path, parent)
+	path[depth] = pathElement{feature: feature, zero: zero, one: one}
+	if depth == 0 {
+		path[depth].weight = 1
+	}
+
+	for i := depth - 1; i >= 0; i-- {
+		path[i+1].weight += one * path[i].weight * float64(i+1) / float64(depth+1)
+		path[i].weight = zero * path[i].weight * float64(depth-i) / float64(depth+1)
+	}
+	return path
+}
+
+// unwindPath returns a copy of the path with the element at index removed,
+// undoing its extension
+func unwindPath(parent []pathElement, index int) []pathElement {
+	path := make([]pathElement, len(parent))
+	copy(path, parent)
+
+	depth := len(path) - 1
+	one, zero := path[index].one, path[index].zero
+	next := path[depth].weight
+	for i := depth - 1; i >= 0; i-- {
+		if one != 0 {
+			weight := path[i].weight
+			path[i].weight = next * float64(depth+1) / (float64(i+1) * one)
+			next = weight - path[i].weight*zero*float64(depth-i)/float64(depth+1)
+		} else {
+			path[i].weight = path[i].weight * float64(depth+1) / (zero * float64(depth-i))
+		}
+	}
+
+	for i := index; i < depth; i++ {
+		path[i].feature = path[i+1].feature
+		path[i].zero = path[i+1].zero
+		path[i].one = path[i+1].one
+	}
+	return path[:depth]
+}
+
+// unwoundPathSum returns the total weight of the path with the element at
+// index removed, without building it
+func unwoundPathSum(path []pathElement, index int) float64 {
+	depth := len(path) - 1
+	one, zero := path[index].one, path[index].zero
+	next := path[depth].weight
+	total := 0.0
+	for i := depth - 1; i >= 0; i-- {
+		if one != 0 {
+			weight := next * float64(depth+1) / (float64(i+1) * one)
+			total += weight
+			next = path[i].weight - weight*zero*float64(depth-i)/float64(depth+1)
+		} else if zero != 0 {
+			total += path[i].weight / zero * float64(depth+1) / float64(depth-i)
+		}
+	}
+	return total
+}
+
+// addScaled adds scale times values to total
+func addScaled(total, values []float64, scale float64) {
+	for i, value := range values {
+		total[i] += scale * value
+	}
+}
+
+// SHAPColumns formats attributions as CSV columns: a shap_base_<output>
+// column per output followed by a shap_<output>_<feature> column per output
+// and feature
+func SHAPColumns(model *t.Model, attributions []Attribution) ([]string, [][]string) {
+	outputs := Outputs(model)
+	features := ModelFeatures(model)
+
+	var header []string
+	var columns [][]string
+	addColumn := func(name string, value func(attribution Attribution) float64) {
+		column := make([]string, len(attributions))
+		for i, attribution := range attributions {
+			column[i] = strconv.FormatFloat(value(attribution), 'g', -1, 64)
+		}
+		header = append(header, name)
+		columns = append(columns, column)
+	}
+
+	for _, output := range outputs {
+		addColumn(fmt.Sprintf("shap_base_%s", output), func(attribution Attribution) float64 {
+			return attribution.Base[output]
+		})
+	}
+	for _, output := range outputs {
+		for _, feature := range features {
+			addColumn(fmt.Sprintf("shap_%s_%s", output, feature), func(attribution Attribution) float64 {
+				return attribution.Values[output][feature]
+			})
+		}
+	}
+	return header, columns
+}
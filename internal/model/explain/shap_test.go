@@ -0,0 +1,314 @@
+package explain
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/predict"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func leaf(class string, counts map[string]float64) *t.Node {
+	samples := 0.0
+	for _, count := range counts {
+		samples += count
+	}
+	return &t.Node{IsLeaf: true, Class: class, Samples: samples, ClassCounts: counts}
+}
+
+// creditModel splits on income twice, so TreeSHAP has to unwind a feature
+func creditModel() *t.Model {
+	return &t.Model{
+		FeatureNames: []string{"income", "age", "region", "label"},
+		FeatureTypes: map[string]string{"income": "numerical", "age": "numerical", "region": "categorical"},
+		TargetName:   "label",
+		Task:         t.Classification,
+		Root: &t.Node{
+			Feature:     "income",
+			Continuous:  true,
+			Threshold:   52000,
+			Samples:     100,
+			ClassCounts: map[string]float64{"approve": 58, "reject": 42},
+			Children: []*t.Node{
+				{
+					Feature:     "age",
+					Continuous:  true,
+					Threshold:   30,
+					Samples:     40,
+					ClassCounts: map[string]float64{"approve": 13, "reject": 27},
+					Children: []*t.Node{
+						leaf("reject", map[string]float64{"approve": 3, "reject": 12}),
+						leaf("reject", map[string]float64{"approve": 10, "reject": 15}),
+					},
+				},
+				{
+					Feature:     "income",
+					Continuous:  true,
+					Threshold:   80000,
+					Samples:     60,
+					ClassCounts: map[string]float64{"approve": 45, "reject": 15},
+					Children: []*t.Node{
+						{
+							Feature:     "region",
+							Samples:     25,
+							ClassCounts: map[string]float64{"approve": 12, "reject": 13},
+							Children: []*t.Node{
+								{IsLeaf: true, Class: "approve", Value: "EU", Samples: 10, ClassCounts: map[string]float64{"approve": 8, "reject": 2}},
+								{IsLeaf: true, Class: "reject", Values: []string{"US", "CA"}, Samples: 10, ClassCounts: map[string]float64{"approve": 1, "reject": 9}},
+								{IsLeaf: true, Class: "approve", Value: "unknown", Samples: 5, ClassCounts: map[string]float64{"approve": 3, "reject": 2}},
+							},
+						},
+						leaf("approve", map[string]float64{"approve": 33, "reject": 2}),
+					},
+				},
+			},
+		},
+	}
+}
+
+// conditional returns the path-dependent expectation of a tree's output
+// given only the features in known
+func conditional(s *treeSHAP, node *t.Node, known map[string]bool) []float64 {
+	if node.IsLeaf {
+		return s.nodeOutput(node)
+	}
+	if known[node.Feature] {
+		child, _ := predict.Route(node, s.instance)
+		if child == nil {
+			return s.nodeOutput(node)
+		}
+		return conditional(s, child, known)
+	}
+
+	result := make([]float64, len(s.outputIndex))
+	covers, total := childCovers(node)
+	for i, child := range node.Children {
+		addScaled(result, conditional(s, child, known), covers[i]/total)
+	}
+	return result
+}
+
+// bruteForceSHAP computes Shapley values by enumerating every feature subset
+func bruteForceSHAP(model *t.Model, instance t.Instance) map[string][]float64 {
+	outputs := Outputs(model)
+	features := ModelFeatures(model)
+	outputIndex := make(map[string]int)
+	for i, output := range outputs {
+		outputIndex[output] = i
+	}
+
+	value := func(known map[string]bool) []float64 {
+		result := make([]float64, len(outputs))
+		trees, shares := shapTrees(model)
+		for i, tree := range trees {
+			s := &treeSHAP{model: model, instance: instance, outputIndex: outputIndex, share: shares[i]}
+			addScaled(result, conditional(s, tree, known), shares[i])
+		}
+		return result
+	}
+
+	factorial := func(n int) float64 {
+		result := 1.0
+		for i := 2; i <= n; i++ {
+			result *= float64(i)
+		}
+		return result
+	}
+
+	m := len(features)
+	phi := make(map[string][]float64)
+	for i, feature := range features {
+		phi[feature] = make([]float64, len(outputs))
+		for mask := 0; mask < 1<<m; mask++ {
+			if mask&(1<<i) != 0 {
+				continue
+			}
+			known := make(map[string]bool)
+			size := 0
+			for j := range features {
+				if mask&(1<<j) != 0 {
+					known[features[j]] = true
+					size++
+				}
+			}
+			without := value(known)
+			known[feature] = true
+			with := value(known)
+
+			weight := factorial(size) * factorial(m-size-1) / factorial(m)
+			for o := range outputs {
+				phi[feature][o] += weight * (with[o] - without[o])
+			}
+		}
+	}
+	return phi
+}
+
+func assertMatchesBruteForce(tt *testing.T, model *t.Model, instance t.Instance) {
+	attribution := SHAP(model, instance)
+	expected := bruteForceSHAP(model, instance)
+	for o, output := range Outputs(model) {
+		for feature, values := range expected {
+			assert.InDelta(tt, values[o], attribution.Values[output][feature], 1e-9, "%s %s", output, feature)
+		}
+	}
+}
+
+func TestSHAP_MatchesShapleyValues(tt *testing.T) {
+	model := creditModel()
+	instances := []t.Instance{
+		{"income": 30000.0, "age": 25.0, "region": "EU"},
+		{"income": 60000.0, "age": 45.0, "region": "EU"},
+		{"income": 60000.0, "age": 45.0, "region": "CA"},
+		{"income": 60000.0, "age": 45.0, "region": "APAC"},
+		{"income": 95000.0, "age": 45.0, "region": "US"},
+		{"income": 60000.0, "age": 45.0},
+		{"age": 45.0, "region": "EU"},
+	}
+
+	for _, instance := range instances {
+		assertMatchesBruteForce(tt, model, instance)
+	}
+}
+
+func TestSHAP_AddsUpToPrediction(tt *testing.T) {
+	model := creditModel()
+	instances := []t.Instance{
+		{"income": 60000.0, "age": 45.0, "region": "EU"},
+		{"income": 60000.0, "age": 45.0},
+	}
+
+	for _, instance := range instances {
+		attribution := SHAP(model, instance)
+		probabilities := predict.PredictProbabilities(model, instance)
+		for _, class := range []string{"approve", "reject"} {
+			total := attribution.Base[class]
+			for _, value := range attribution.Values[class] {
+				total += value
+			}
+			assert.InDelta(tt, probabilities[class], total, 1e-9)
+		}
+		assert.InDelta(tt, 0.58, attribution.Base["approve"], 1e-9)
+	}
+
+	// Features the model never splits on get nothing
+	model.FeatureNames = append(model.FeatureNames, "noise")
+	attribution := SHAP(model, t.Instance{"income": 95000.0, "age": 45.0, "region": "US", "noise": 1.0})
+	assert.Equal(tt, 0.0, attribution.Values["approve"]["noise"])
+	assert.Equal(tt, 0.0, attribution.Values["reject"]["noise"])
+}
+
+func TestSHAP_Ensembles(tt *testing.T) {
+	single := creditModel()
+	second := &t.Node{
+		Feature:     "age",
+		Continuous:  true,
+		Threshold:   40,
+		Samples:     100,
+		ClassCounts: map[string]float64{"approve": 58, "reject": 42},
+		Children: []*t.Node{
+			leaf("reject", map[string]float64{"approve": 20, "reject": 30}),
+			leaf("approve", map[string]float64{"approve": 38, "reject": 12}),
+		},
+	}
+	instance := t.Instance{"income": 60000.0, "age": 45.0, "region": "CA"}
+
+	for _, voting := range []string{t.MajorityVote, t.ProbabilityVote} {
+		model := &t.Model{
+			FeatureNames: single.FeatureNames,
+			TargetName:   single.TargetName,
+			Task:         t.Classification,
+			Ensemble: &t.Ensemble{
+				Method:  t.Boost,
+				Voting:  voting,
+				Trees:   []*t.Node{single.Root, second},
+				Weights: []float64{1.5, 0.5},
+			},
+		}
+		assertMatchesBruteForce(tt, model, instance)
+
+		attribution := SHAP(model, instance)
+		probabilities := predict.PredictProbabilities(model, instance)
+		for _, class := range []string{"approve", "reject"} {
+			total := attribution.Base[class]
+			for _, value := range attribution.Values[class] {
+				total += value
+			}
+			assert.InDelta(tt, probabilities[class], total, 1e-9, voting)
+		}
+	}
+}
+
+func TestSHAP_Regression(tt *testing.T) {
+	model := &t.Model{
+		FeatureNames: []string{"size", "rooms", "price"},
+		TargetName:   "price",
+		Task:         t.Regression,
+		Root: &t.Node{
+			Feature:    "size",
+			Continuous: true,
+			Threshold:  80,
+			Samples:    10,
+			Mean:       250,
+			Children: []*t.Node{
+				{IsLeaf: true, Samples: 6, Mean: 150},
+				{
+					Feature:    "rooms",
+					Continuous: true,
+					Threshold:  3,
+					Samples:    4,
+					Mean:       400,
+					Children: []*t.Node{
+						{IsLeaf: true, Samples: 1, Mean: 340},
+						{IsLeaf: true, Samples: 3, Mean: 420},
+					},
+				},
+			},
+		},
+	}
+
+	instance := t.Instance{"size": 120.0, "rooms": 4.0}
+	attribution := SHAP(model, instance)
+	assert.Equal(tt, []string{RegressionOutput}, Outputs(model))
+	assert.InDelta(tt, 250, attribution.Base[RegressionOutput], 1e-9)
+	assert.InDelta(tt, 420-250, attribution.Values[RegressionOutput]["size"]+attribution.Values[RegressionOutput]["rooms"], 1e-9)
+	assertMatchesBruteForce(tt, model, instance)
+}
+
+func TestSHAPColumnsAndSummary(tt *testing.T) {
+	model := creditModel()
+	instances := []t.Instance{
+		{"income": 30000.0, "age": 25.0, "region": "EU"},
+		{"income": 95000.0, "age": 45.0, "region": "US"},
+	}
+	attributions := SHAPBatch(model, instances)
+	assert.Equal(tt, SHAP(model, instances[1]), attributions[1])
+
+	header, columns := SHAPColumns(model, attributions)
+	assert.Equal(tt, []string{
+		"shap_base_approve", "shap_base_reject",
+		"shap_approve_income", "shap_approve_age", "shap_approve_region",
+		"shap_reject_income", "shap_reject_age", "shap_reject_region",
+	}, header)
+	assert.Len(tt, columns, len(header))
+	base, err := strconv.ParseFloat(columns[0][0], 64)
+	assert.NoError(tt, err)
+	assert.InDelta(tt, 0.58, base, 1e-9)
+
+	summary := Summarise(model, attributions)
+	assert.Equal(tt, "income", summary[0].Feature)
+	for _, item := range summary {
+		mean := (math.Abs(attributions[0].Values["approve"][item.Feature]) + math.Abs(attributions[1].Values["approve"][item.Feature])) / 2
+		assert.InDelta(tt, mean, item.MeanAbs["approve"], 1e-9)
+		assert.InDelta(tt, item.MeanAbs["approve"]+item.MeanAbs["reject"], item.Total, 1e-9)
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(tt, WriteSummary(&buf, summary, "csv"))
+	assert.True(tt, strings.HasPrefix(buf.String(), "feature,total,mean_abs_approve,mean_abs_reject\nincome,"))
+	assert.Error(tt, WriteSummary(&buf, summary, "xml"))
+}
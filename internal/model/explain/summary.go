@@ -0,0 +1,140 @@
+package explain
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+)
+
+// SummaryFormats lists the names accepted by WriteSummary
+var SummaryFormats = []string{"table", "csv", "json"}
+
+// FeatureSHAP summarises the SHAP values of one feature over a dataset
+type FeatureSHAP struct {
+	Feature string `json:"feature"`
+	// MeanAbs is the mean absolute contribution to each output
+	MeanAbs map[string]float64 `json:"mean_abs"`
+	// Total adds up MeanAbs over the outputs
+	Total float64 `json:"total"`
+}
+
+// Summarise aggregates the attributions of a dataset into the mean absolute
+// SHAP value of every feature for every output, sorted by decreasing total
+func Summarise(model *t.Model, attributions []Attribution) []FeatureSHAP {
+	outputs := Outputs(model)
+	features := ModelFeatures(model)
+
+	summary := make([]FeatureSHAP, len(features))
+	for i, feature := range features {
+		item := FeatureSHAP{Feature: feature, MeanAbs: make(map[string]float64, len(outputs))}
+		for _, output := range outputs {
+			sum := 0.0
+			for _, attribution := range attributions {
+				sum += math.Abs(attribution.Values[output][feature])
+			}
+			if len(attributions) > 0 {
+				item.MeanAbs[output] = sum / float64(len(attributions))
+			}
+			item.Total += item.MeanAbs[output]
+		}
+		summary[i] = item
+	}
+
+	sort.SliceStable(summary, func(i, j int) bool {
+		if summary[i].Total != summary[j].Total {
+			return summary[i].Total > summary[j].Total
+		}
+		return summary[i].Feature < summary[j].Feature
+	})
+	return summary
+}
+
+// WriteSummary writes a SHAP summary in the given format: an aligned table,
+// CSV or a JSON array
+func WriteSummary(w io.Writer, summary []FeatureSHAP, format string) error {
+	switch format {
+	case "table":
+		return writeSummaryTable(w, summary)
+	case "csv":
+		return writeSummaryCSV(w, summary)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(summary)
+	default:
+		return fmt.Errorf("unknown format '%s', expected one of %v", format, SummaryFormats)
+	}
+}
+
+// summaryOutputs returns the sorted outputs of a summary
+func summaryOutputs(summary []FeatureSHAP) []string {
+	if len(summary) == 0 {
+		return nil
+	}
+	outputs := make([]string, 0, len(summary[0].MeanAbs))
+	for output := range summary[0].MeanAbs {
+		outputs = append(outputs, output)
+	}
+	sort.Strings(outputs)
+	return outputs
+}
+
+// summaryHeader returns the column names
+func summaryHeader(outputs []string) []string {
+	columns := []string{"feature", "total"}
+	for _, output := range outputs {
+		columns = append(columns, "mean_abs_"+output)
+	}
+	return columns
+}
+
+// summaryRow formats the columns of one feature
+func summaryRow(item FeatureSHAP, outputs []string) []string {
+	columns := []string{item.Feature, strconv.FormatFloat(item.Total, 'g', 6, 64)}
+	for _, output := range outputs {
+		columns = append(columns, strconv.FormatFloat(item.MeanAbs[output], 'g', 6, 64))
+	}
+	return columns
+}
+
+func writeSummaryTable(w io.Writer, summary []FeatureSHAP) error {
+	outputs := summaryOutputs(summary)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	rows := [][]string{summaryHeader(outputs)}
+	for _, item := range summary {
+		rows = append(rows, summaryRow(item, outputs))
+	}
+	for _, row := range rows {
+		for i, column := range row {
+			if i > 0 {
+				fmt.Fprint(tw, "\t")
+			}
+			fmt.Fprint(tw, column)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+func writeSummaryCSV(w io.Writer, summary []FeatureSHAP) error {
+	outputs := summaryOutputs(summary)
+	writer := csv.NewWriter(w)
+	if err := writer.Write(summaryHeader(outputs)); err != nil {
+		return fmt.Errorf("error writing SHAP summary: %v", err)
+	}
+	for _, item := range summary {
+		if err := writer.Write(summaryRow(item, outputs)); err != nil {
+			return fmt.Errorf("error writing SHAP summary: %v", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
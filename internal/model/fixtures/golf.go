@@ -0,0 +1,53 @@
+// Package fixtures holds the datasets the tests of the model packages share
+package fixtures
+
+import t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+
+// GolfTarget is the class column of the golf dataset
+const GolfTarget = "play"
+
+// GolfFeatures are the feature columns of the golf dataset
+var GolfFeatures = []string{"outlook", "temperature", "humidity", "windy"}
+
+// GolfTypes returns the feature types of the golf dataset, target included
+func GolfTypes() map[string]string {
+	return map[string]string{
+		"outlook":     "categorical",
+		"temperature": "numerical",
+		"humidity":    "numerical",
+		"windy":       "categorical",
+		"play":        "categorical",
+	}
+}
+
+// Golf returns Quinlan's weather dataset with numeric temperature and humidity
+func Golf() []t.Instance {
+	rows := [][]interface{}{
+		{"sunny", 85.0, 85.0, "false", "no"},
+		{"sunny", 80.0, 90.0, "true", "no"},
+		{"overcast", 83.0, 86.0, "false", "yes"},
+		{"rainy", 70.0, 96.0, "false", "yes"},
+		{"rainy", 68.0, 80.0, "false", "yes"},
+		{"rainy", 65.0, 70.0, "true", "no"},
+		{"overcast", 64.0, 65.0, "true", "yes"},
+		{"sunny", 72.0, 95.0, "false", "no"},
+		{"sunny", 69.0, 70.0, "false", "yes"},
+		{"rainy", 75.0, 80.0, "false", "yes"},
+		{"sunny", 75.0, 70.0, "true", "yes"},
+		{"overcast", 72.0, 90.0, "true", "yes"},
+		{"overcast", 81.0, 75.0, "false", "yes"},
+		{"rainy", 71.0, 91.0, "true", "no"},
+	}
+
+	instances := make([]t.Instance, 0, len(rows))
+	for _, row := range rows {
+		instances = append(instances, t.Instance{
+			"outlook":     row[0],
+			"temperature": row[1],
+			"humidity":    row[2],
+			"windy":       row[3],
+			"play":        row[4],
+		})
+	}
+	return instances
+}
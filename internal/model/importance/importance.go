@@ -0,0 +1,239 @@
+package importance
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/evaluate"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+)
+
+// FeatureImportance summarises how much a model relies on one feature
+type FeatureImportance struct {
+	Feature string `json:"feature"`
+	// Gain adds up the gain of every split on the feature, each weighted by
+	// the share of the training weight that reached it
+	Gain float64 `json:"gain"`
+	// GainShare is Gain as a fraction of the total over all features
+	GainShare float64 `json:"gain_share"`
+	// Splits counts the decision nodes testing the feature
+	Splits int `json:"splits"`
+	// DepthWeighted counts the splits with weight 1/2^depth, so a split at
+	// the root counts 1 and one at its children counts 1/2
+	DepthWeighted float64 `json:"depth_weighted"`
+	// Permutation is the mean drop in accuracy (R² for regression) when the
+	// feature is shuffled in a labelled dataset, nil when not computed
+	Permutation *float64 `json:"permutation,omitempty"`
+}
+
+// FromModel computes the gain, split count and depth-weighted usage of every
+// feature of a model. Ensembles average their trees, weighted by the trees'
+// votes. The result is sorted by decreasing gain.
+func FromModel(model *t.Model) []FeatureImportance {
+	byFeature := make(map[string]*FeatureImportance)
+	for _, feature := range Features(model) {
+		byFeature[feature] = &FeatureImportance{Feature: feature}
+	}
+
+	trees, weights := modelTrees(model)
+	totalWeight := 0.0
+	for _, weight := range weights {
+		totalWeight += weight
+	}
+
+	for i, tree := range trees {
+		share := weights[i] / totalWeight
+		rootSamples := tree.Samples
+		walk(tree, 0, func(node *t.Node, depth int) {
+			item, ok := byFeature[node.Feature]
+			if !ok {
+				item = &FeatureImportance{Feature: node.Feature}
+				byFeature[node.Feature] = item
+			}
+
+			reach := 1.0
+			if rootSamples > 0 {
+				reach = node.Samples / rootSamples
+			}
+			item.Gain += share * reach * node.Gain
+			item.Splits++
+			item.DepthWeighted += share * math.Pow(2, -float64(depth))
+		})
+	}
+
+	result := make([]FeatureImportance, 0, len(byFeature))
+	for _, item := range byFeature {
+		result = append(result, *item)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Gain != result[j].Gain {
+			return result[i].Gain > result[j].Gain
+		}
+		return result[i].Feature < result[j].Feature
+	})
+
+	total := 0.0
+	for _, item := range result {
+		total += item.Gain
+	}
+	for i := range result {
+		if total > 0 {
+			result[i].GainShare = result[i].Gain / total
+		}
+	}
+	return result
+}
+
+// walk calls visit for every decision node of a tree with its depth
+func walk(node *t.Node, depth int, visit func(node *t.Node, depth int)) {
+	if node == nil || node.IsLeaf {
+		return
+	}
+	visit(node, depth)
+	for _, child := range node.Children {
+		walk(child, depth+1, visit)
+	}
+}
+
+// modelTrees returns the trees of a model and the weight of their votes
+func modelTrees(model *t.Model) ([]*t.Node, []float64) {
+	if model.Ensemble == nil {
+		return []*t.Node{model.Root}, []float64{1}
+	}
+
+	weights := model.Ensemble.Weights
+	if weights == nil {
+		weights = make([]float64, len(model.Ensemble.Trees))
+		for i := range weights {
+			weights[i] = 1
+		}
+	}
+	return model.Ensemble.Trees, weights
+}
+
+// Features returns the features a model may split on
+func Features(model *t.Model) []string {
+	features := make([]string, 0, len(model.FeatureNames))
+	for _, feature := range model.FeatureNames {
+		if feature != model.TargetName && feature != model.WeightColumn {
+			features = append(features, feature)
+		}
+	}
+	return features
+}
+
+// Permutation computes the permutation importance of every feature on a
+// labelled dataset: the mean drop in score over repeats shuffles of the
+// feature's column. Features are evaluated in parallel and every feature
+// draws from its own source seeded from seed, so results are reproducible.
+func Permutation(model *t.Model, instances []t.Instance, repeats int, seed int64) (map[string]float64, error) {
+	if repeats <= 0 {
+		return nil, fmt.Errorf("permutation importance needs at least one repeat, got %d", repeats)
+	}
+
+	baseline, err := score(model, instances)
+	if err != nil {
+		return nil, err
+	}
+
+	features := Features(model)
+	drops := make([]float64, len(features))
+	errs := make([]error, len(features))
+
+	featuresChan := make(chan int, len(features))
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range featuresChan {
+				rng := rand.New(rand.NewPCG(uint64(seed+int64(i)), 0))
+				total := 0.0
+				for r := 0; r < repeats; r++ {
+					permuted, err := score(model, shuffleColumn(instances, features[i], rng))
+					if err != nil {
+						errs[i] = err
+						break
+					}
+					total += baseline - permuted
+				}
+				drops[i] = total / float64(repeats)
+			}
+		}()
+	}
+	for i := range features {
+		featuresChan <- i
+	}
+	close(featuresChan)
+	wg.Wait()
+
+	result := make(map[string]float64, len(features))
+	for i, feature := range features {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		result[feature] = drops[i]
+	}
+	return result, nil
+}
+
+// score returns the accuracy of a classification model or the R² of a
+// regression model on labelled instances
+func score(model *t.Model, instances []t.Instance) (float64, error) {
+	metrics, err := evaluate.Model(model, instances)
+	if err != nil {
+		return 0, err
+	}
+	switch metrics := metrics.(type) {
+	case evaluate.RegressionMetrics:
+		return metrics.R2, nil
+	case evaluate.ClassificationMetrics:
+		return metrics.Accuracy, nil
+	}
+	return 0, fmt.Errorf("unexpected metrics %T", metrics)
+}
+
+// shuffleColumn returns copies of the instances with the values of one
+// feature randomly permuted between them
+func shuffleColumn(instances []t.Instance, feature string, rng *rand.Rand) []t.Instance {
+	perm := rng.Perm(len(instances))
+	shuffled := make([]t.Instance, len(instances))
+	for i, instance := range instances {
+		copied := make(t.Instance, len(instance))
+		for key, value := range instance {
+			copied[key] = value
+		}
+		value, ok := instances[perm[i]][feature]
+		if ok {
+			copied[feature] = value
+		} else {
+			delete(copied, feature)
+		}
+		shuffled[i] = copied
+	}
+	return shuffled
+}
+
+// Report computes the importances of a model, including permutation
+// importance when labelled instances are given
+func Report(model *t.Model, instances []t.Instance, repeats int, seed int64) ([]FeatureImportance, error) {
+	importances := FromModel(model)
+	if len(instances) == 0 {
+		return importances, nil
+	}
+
+	drops, err := Permutation(model, instances, repeats, seed)
+	if err != nil {
+		return nil, err
+	}
+	for i := range importances {
+		if drop, ok := drops[importances[i].Feature]; ok {
+			importances[i].Permutation = &drop
+		}
+	}
+	return importances, nil
+}
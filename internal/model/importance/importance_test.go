@@ -0,0 +1,124 @@
+package importance
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// sampleModel splits on income at the root and on region below it
+func sampleModel() *t.Model {
+	return &t.Model{
+		FeatureNames: []string{"income", "region", "noise", "label"},
+		TargetName:   "label",
+		Root: &t.Node{
+			Feature:    "income",
+			Continuous: true,
+			Threshold:  50,
+			Samples:    100,
+			Gain:       0.5,
+			Children: []*t.Node{
+				{IsLeaf: true, Class: "no", Samples: 60},
+				{
+					Feature: "region",
+					Samples: 40,
+					Gain:    0.25,
+					Children: []*t.Node{
+						{IsLeaf: true, Class: "yes", Value: "EU", Samples: 30},
+						{IsLeaf: true, Class: "no", Value: "US", Samples: 10},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestFromModel(tt *testing.T) {
+	importances := FromModel(sampleModel())
+	assert.Len(tt, importances, 3)
+
+	assert.Equal(tt, "income", importances[0].Feature)
+	assert.InDelta(tt, 0.5, importances[0].Gain, 1e-12)
+	assert.Equal(tt, 1, importances[0].Splits)
+	assert.InDelta(tt, 1.0, importances[0].DepthWeighted, 1e-12)
+
+	// region is reached by 40% of the weight at depth 1
+	assert.Equal(tt, "region", importances[1].Feature)
+	assert.InDelta(tt, 0.1, importances[1].Gain, 1e-12)
+	assert.InDelta(tt, 0.5, importances[1].DepthWeighted, 1e-12)
+	assert.InDelta(tt, 0.1/0.6, importances[1].GainShare, 1e-12)
+
+	assert.Equal(tt, "noise", importances[2].Feature)
+	assert.Zero(tt, importances[2].Splits)
+}
+
+func TestFromModel_Ensemble(tt *testing.T) {
+	single := sampleModel()
+	model := &t.Model{
+		FeatureNames: single.FeatureNames,
+		TargetName:   "label",
+		Ensemble: &t.Ensemble{
+			Trees:   []*t.Node{single.Root, {IsLeaf: true, Class: "no"}},
+			Weights: []float64{3, 1},
+		},
+	}
+
+	importances := FromModel(model)
+	assert.InDelta(tt, 0.75*0.5, importances[0].Gain, 1e-12)
+	assert.Equal(tt, 1, importances[0].Splits)
+}
+
+func TestPermutation(tt *testing.T) {
+	model := sampleModel()
+	instances := make([]t.Instance, 0, 40)
+	for i := 0; i < 40; i++ {
+		income, region := float64(i%2)*100, []string{"EU", "US"}[i/2%2]
+		label := "no"
+		if income > 50 && region == "EU" {
+			label = "yes"
+		}
+		instances = append(instances, t.Instance{"income": income, "region": region, "noise": i, "label": label})
+	}
+
+	drops, err := Permutation(model, instances, 3, 1)
+	assert.NoError(tt, err)
+	assert.Greater(tt, drops["income"], 0.0)
+	assert.Greater(tt, drops["region"], 0.0)
+	assert.Zero(tt, drops["noise"])
+
+	again, err := Permutation(model, instances, 3, 1)
+	assert.NoError(tt, err)
+	assert.Equal(tt, drops, again)
+
+	_, err = Permutation(model, instances, 0, 1)
+	assert.Error(tt, err)
+}
+
+func TestWrite(tt *testing.T) {
+	importances := FromModel(sampleModel())
+	drop := 0.25
+	importances[0].Permutation = &drop
+
+	var table bytes.Buffer
+	assert.NoError(tt, Write(&table, importances, "table"))
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	assert.Len(tt, lines, 4)
+	assert.True(tt, strings.HasPrefix(lines[0], "feature"))
+	assert.Contains(tt, lines[0], "permutation")
+
+	var csv bytes.Buffer
+	assert.NoError(tt, Write(&csv, importances, "csv"))
+	assert.True(tt, strings.HasPrefix(csv.String(), "feature,gain,gain_share,splits,depth_weighted,permutation\nincome,0.5,0.8333,1,1,0.2500\n"))
+
+	var encoded bytes.Buffer
+	assert.NoError(tt, Write(&encoded, importances, "json"))
+	var decoded []FeatureImportance
+	assert.NoError(tt, json.Unmarshal(encoded.Bytes(), &decoded))
+	assert.Equal(tt, importances, decoded)
+
+	assert.Error(tt, Write(&encoded, importances, "xml"))
+}
@@ -0,0 +1,107 @@
+package importance
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+// Formats lists the names accepted by Write
+var Formats = []string{"table", "csv", "json"}
+
+// Write writes the importances in the given format: an aligned table, CSV
+// or a JSON array
+func Write(w io.Writer, importances []FeatureImportance, format string) error {
+	switch format {
+	case "table":
+		return writeTable(w, importances)
+	case "csv":
+		return writeCSV(w, importances)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(importances)
+	default:
+		return fmt.Errorf("unknown format '%s', expected one of %v", format, Formats)
+	}
+}
+
+// hasPermutation reports whether permutation importance was computed
+func hasPermutation(importances []FeatureImportance) bool {
+	for _, item := range importances {
+		if item.Permutation != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// row formats the columns of one feature
+func row(item FeatureImportance, permutation bool) []string {
+	columns := []string{
+		item.Feature,
+		strconv.FormatFloat(item.Gain, 'g', 6, 64),
+		strconv.FormatFloat(item.GainShare, 'f', 4, 64),
+		strconv.Itoa(item.Splits),
+		strconv.FormatFloat(item.DepthWeighted, 'g', 6, 64),
+	}
+	if permutation {
+		value := ""
+		if item.Permutation != nil {
+			value = strconv.FormatFloat(*item.Permutation, 'f', 4, 64)
+		}
+		columns = append(columns, value)
+	}
+	return columns
+}
+
+// header returns the column names
+func header(permutation bool) []string {
+	columns := []string{"feature", "gain", "gain_share", "splits", "depth_weighted"}
+	if permutation {
+		columns = append(columns, "permutation")
+	}
+	return columns
+}
+
+func writeTable(w io.Writer, importances []FeatureImportance) error {
+	permutation := hasPermutation(importances)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	for i, column := range header(permutation) {
+		if i > 0 {
+			fmt.Fprint(tw, "\t")
+		}
+		fmt.Fprint(tw, column)
+	}
+	fmt.Fprintln(tw)
+
+	for _, item := range importances {
+		for i, column := range row(item, permutation) {
+			if i > 0 {
+				fmt.Fprint(tw, "\t")
+			}
+			fmt.Fprint(tw, column)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+func writeCSV(w io.Writer, importances []FeatureImportance) error {
+	permutation := hasPermutation(importances)
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header(permutation)); err != nil {
+		return fmt.Errorf("error writing importance: %v", err)
+	}
+	for _, item := range importances {
+		if err := writer.Write(row(item, permutation)); err != nil {
+			return fmt.Errorf("error writing importance: %v", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,203 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/predict"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
+)
+
+// boostWeightFeature is the column holding the boosting weights of the
+// training instances while the trees are grown
+const boostWeightFeature = "__boost_weight__"
+
+// minBoostError keeps the alpha of a tree without training errors finite
+const minBoostError = 1e-10
+
+// BoostOptions holds the parameters of boosting
+type BoostOptions struct {
+	// Rounds is the maximum number of trees
+	Rounds int
+	// MaxDepth limits the depth of every tree
+	MaxDepth int
+	// ValidationFraction of the instances is held out for early stopping.
+	// All rounds are kept when it is 0.
+	ValidationFraction float64
+	// Patience is the number of rounds without a lower validation error
+	// before boosting stops
+	Patience int
+}
+
+// DefaultBoostOptions returns the options used by the CLI
+func DefaultBoostOptions() BoostOptions {
+	return BoostOptions{
+		Rounds:             50,
+		MaxDepth:           3,
+		ValidationFraction: 0.2,
+		Patience:           5,
+	}
+}
+
+// TrainBoosted trains a sequence of shallow C4.5 trees with AdaBoost, using
+// SAMME for more than two classes. Every round reweights the instances the
+// last tree misclassified, and the trees vote with their alpha weights. The
+// ensemble is cut back to the round with the lowest validation error.
+func TrainBoosted(instances []t.Instance, headers []string, targetFeature string, featureTypes map[string]string, excludeColumns []string, options TrainOptions, boost BoostOptions) (*t.Model, error) {
+	if boost.Rounds <= 0 {
+		return nil, fmt.Errorf("boosting needs at least one round, got %d", boost.Rounds)
+	}
+	if boost.ValidationFraction < 0 || boost.ValidationFraction >= 1 {
+		return nil, fmt.Errorf("validation fraction must be in [0, 1), got %v", boost.ValidationFraction)
+	}
+
+	seed := ResolveSeed(options.Seed)
+	train, validation := holdOut(instances, boost.ValidationFraction, rand.New(rand.NewPCG(uint64(seed), 0)))
+
+	context, task, err := newTreeContext(train, headers, targetFeature, featureTypes, excludeColumns, options)
+	if err != nil {
+		return nil, err
+	}
+	if task != t.Classification {
+		return nil, fmt.Errorf("boosting only supports classification")
+	}
+	context.SplitOptions.WeightFeature = boostWeightFeature
+
+	// Copy the instances so their weights can be updated between rounds
+	weighted := make([]t.Instance, len(train))
+	classSet := make(map[string]bool)
+	for i, instance := range train {
+		weighted[i] = make(t.Instance, len(instance)+1)
+		for key, value := range instance {
+			weighted[i][key] = value
+		}
+		weighted[i][boostWeightFeature] = utils.InstanceWeight(instance, options.WeightColumn)
+		classSet[fmt.Sprintf("%v", instance[targetFeature])] = true
+	}
+	normaliseBoostWeights(weighted)
+	classes := len(classSet)
+
+	model := newModel(headers, targetFeature, featureTypes, task, options, context.ClassOrder)
+	model.Ensemble = &t.Ensemble{Method: t.Boost, Seed: seed}
+
+	validationVotes := make([]map[string]float64, len(validation))
+	for j := range validationVotes {
+		validationVotes[j] = make(map[string]float64)
+	}
+	bestRounds, bestError := 0, math.Inf(1)
+
+	for round := 0; round < boost.Rounds; round++ {
+		tree := context.C45(weighted, boost.MaxDepth)
+
+		// Weighted training error of the new tree
+		misclassified := make([]bool, len(weighted))
+		errorWeight, totalWeight := 0.0, 0.0
+		for i, instance := range weighted {
+			weight := instance[boostWeightFeature].(float64)
+			totalWeight += weight
+			if predict.TreeClass(model, tree, instance) != fmt.Sprintf("%v", instance[targetFeature]) {
+				misclassified[i] = true
+				errorWeight += weight
+			}
+		}
+		trainError := errorWeight / totalWeight
+
+		// A tree no better than chance ends boosting, unless it is the only one
+		if trainError >= 1-1/float64(classes) {
+			if round == 0 {
+				model.Ensemble.Trees = []*t.Node{tree}
+				model.Ensemble.Weights = []float64{1}
+				bestRounds = 1
+			}
+			break
+		}
+
+		alpha := math.Log((1-math.Max(trainError, minBoostError))/math.Max(trainError, minBoostError)) + math.Log(float64(classes)-1)
+		model.Ensemble.Trees = append(model.Ensemble.Trees, tree)
+		model.Ensemble.Weights = append(model.Ensemble.Weights, alpha)
+
+		if len(validation) == 0 {
+			bestRounds = len(model.Ensemble.Trees)
+		} else {
+			wrong := 0
+			for j, instance := range validation {
+				validationVotes[j][predict.TreeClass(model, tree, instance)] += alpha
+				if predict.BestClass(validationVotes[j]) != fmt.Sprintf("%v", instance[targetFeature]) {
+					wrong++
+				}
+			}
+			validationError := float64(wrong) / float64(len(validation))
+			if validationError < bestError {
+				bestError = validationError
+				bestRounds = len(model.Ensemble.Trees)
+			} else if len(model.Ensemble.Trees)-bestRounds >= boost.Patience {
+				break
+			}
+		}
+
+		// A perfect tree leaves nothing to reweight
+		if trainError == 0 {
+			break
+		}
+
+		for i, instance := range weighted {
+			if misclassified[i] {
+				instance[boostWeightFeature] = instance[boostWeightFeature].(float64) * math.Exp(alpha)
+			}
+		}
+		normaliseBoostWeights(weighted)
+	}
+
+	model.Ensemble.Trees = model.Ensemble.Trees[:bestRounds]
+	model.Ensemble.Weights = model.Ensemble.Weights[:bestRounds]
+	if len(validation) > 0 && bestRounds > 0 {
+		model.Ensemble.ValidationError = bestError
+	}
+
+	return model, nil
+}
+
+// holdOut randomly splits off the given fraction of the instances
+func holdOut(instances []t.Instance, fraction float64, rng *rand.Rand) ([]t.Instance, []t.Instance) {
+	size := int(float64(len(instances)) * fraction)
+	if size == 0 {
+		return instances, nil
+	}
+
+	perm := rng.Perm(len(instances))
+	held := perm[:size]
+	sort.Ints(held)
+
+	train := make([]t.Instance, 0, len(instances)-size)
+	validation := make([]t.Instance, 0, size)
+	next := 0
+	for i, instance := range instances {
+		if next < len(held) && held[next] == i {
+			validation = append(validation, instance)
+			next++
+			continue
+		}
+		train = append(train, instance)
+	}
+	return train, validation
+}
+
+// normaliseBoostWeights scales the boosting weights to sum to the number of
+// instances, so the minimum instances per leaf keeps its meaning
+func normaliseBoostWeights(instances []t.Instance) {
+	total := 0.0
+	for _, instance := range instances {
+		total += instance[boostWeightFeature].(float64)
+	}
+	if total == 0 {
+		return
+	}
+
+	scale := float64(len(instances)) / total
+	for _, instance := range instances {
+		instance[boostWeightFeature] = instance[boostWeightFeature].(float64) * scale
+	}
+}
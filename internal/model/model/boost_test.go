@@ -0,0 +1,105 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/predict"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// diagonalInstances labels points by x + y > 1, which axis-parallel stumps
+// can only approximate together
+func diagonalInstances(n int, seed int64) []t.Instance {
+	rng := rand.New(rand.NewPCG(uint64(seed), 0))
+	instances := make([]t.Instance, 0, n)
+	for i := 0; i < n; i++ {
+		x, y := rng.Float64(), rng.Float64()
+		label := "below"
+		if x+y > 1 {
+			label = "above"
+		}
+		instances = append(instances, t.Instance{"x": x, "y": y, "label": label})
+	}
+	return instances
+}
+
+func accuracy(model *t.Model, instances []t.Instance) float64 {
+	correct := 0
+	for _, instance := range instances {
+		if predict.PredictClass(model, instance) == fmt.Sprintf("%v", instance["label"]) {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(instances))
+}
+
+func TestTrainBoosted(tc *testing.T) {
+	instances := diagonalInstances(400, 1)
+	test := diagonalInstances(200, 2)
+	headers := []string{"x", "y", "label"}
+	featureTypes := map[string]string{"x": "numerical", "y": "numerical", "label": "categorical"}
+
+	boost := DefaultBoostOptions()
+	boost.MaxDepth = 1
+	boost.Rounds = 40
+	boost.ValidationFraction = 0
+
+	stump, err := TrainBoosted(instances, headers, "label", featureTypes, nil, DefaultTrainOptions(), BoostOptions{Rounds: 1, MaxDepth: 1})
+	assert.NoError(tc, err)
+	assert.Len(tc, stump.Ensemble.Trees, 1)
+
+	model, err := TrainBoosted(instances, headers, "label", featureTypes, nil, DefaultTrainOptions(), boost)
+	assert.NoError(tc, err)
+	assert.Equal(tc, t.Boost, model.Ensemble.Method)
+	// Boosting stops early once a stump is no better than chance
+	assert.Greater(tc, len(model.Ensemble.Trees), 10)
+	assert.LessOrEqual(tc, len(model.Ensemble.Trees), 40)
+	assert.Len(tc, model.Ensemble.Weights, len(model.Ensemble.Trees))
+	for _, alpha := range model.Ensemble.Weights {
+		assert.Greater(tc, alpha, 0.0)
+	}
+	assert.Greater(tc, accuracy(model, test), accuracy(stump, test)+0.1)
+
+	_, err = TrainBoosted(instances, headers, "label", featureTypes, nil, DefaultTrainOptions(), BoostOptions{})
+	assert.Error(tc, err)
+}
+
+func TestTrainBoosted_EarlyStopping(tc *testing.T) {
+	instances := diagonalInstances(400, 3)
+	headers := []string{"x", "y", "label"}
+	featureTypes := map[string]string{"x": "numerical", "y": "numerical", "label": "categorical"}
+
+	boost := DefaultBoostOptions()
+	boost.MaxDepth = 1
+	boost.Rounds = 500
+	boost.Patience = 3
+
+	options := DefaultTrainOptions()
+	options.Seed = 1
+	model, err := TrainBoosted(instances, headers, "label", featureTypes, nil, options, boost)
+	assert.NoError(tc, err)
+	assert.Less(tc, len(model.Ensemble.Trees), 500)
+	assert.Less(tc, model.Ensemble.ValidationError, 0.2)
+}
+
+func TestTrainBoosted_SAMMEAlpha(tc *testing.T) {
+	// Three classes separated by one feature: a depth one tree can only
+	// isolate one class, so the first round has error 1/3 and SAMME adds log(2)
+	instances := make([]t.Instance, 0, 30)
+	for i := 0; i < 30; i++ {
+		instances = append(instances, t.Instance{"x": float64(i), "label": fmt.Sprintf("c%d", i/10)})
+	}
+	headers := []string{"x", "label"}
+	featureTypes := map[string]string{"x": "numerical", "label": "categorical"}
+
+	model, err := TrainBoosted(instances, headers, "label", featureTypes, nil, DefaultTrainOptions(), BoostOptions{Rounds: 1, MaxDepth: 1})
+	assert.NoError(tc, err)
+	assert.InDelta(tc, math.Log(2)+math.Log(2), model.Ensemble.Weights[0], 1e-9)
+
+	_, err = TrainBoosted(instances, headers, "x", featureTypes, nil, TrainOptions{Task: t.Regression}, BoostOptions{Rounds: 1})
+	assert.Error(tc, err)
+}
@@ -2,71 +2,95 @@ package model
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/nyunja/c4.5-decision-tree/internal/model/cache"
 	"github.com/nyunja/c4.5-decision-tree/internal/model/counter"
-	ndp "github.com/nyunja/c4.5-decision-tree/internal/model/node"
 	"github.com/nyunja/c4.5-decision-tree/internal/model/split"
 	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
 	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
 )
 
+// TreeContext holds the data shared by every recursive call while growing a tree
+type TreeContext struct {
+	Features            []string
+	TargetFeature       string
+	FeatureTypes        map[string]string
+	ExcludedFeatures    map[string]bool
+	MinInstancesPerLeaf int
+	Cache               *cache.FeatureCache
+	SplitOptions        split.Options
+	Costs               t.CostMatrix
+	LinearLeaves        bool
+
+	// ClassOrder breaks ties between classes at leaves, usually the classes
+	// from most to least frequent in the training data. Unlisted classes
+	// are ordered alphabetically.
+	ClassOrder []string
+}
+
 // C45 implements the C4.5 algorithm with optimizations for large datasets
 func C45(instances []t.Instance, features []string, targetFeature string, featureTypes map[string]string, excludedFeatures map[string]bool, minInstancesPerLeaf int, maxDepth int, cache *cache.FeatureCache) *t.Node {
+	context := TreeContext{
+		Features:            features,
+		TargetFeature:       targetFeature,
+		FeatureTypes:        featureTypes,
+		ExcludedFeatures:    excludedFeatures,
+		MinInstancesPerLeaf: minInstancesPerLeaf,
+		Cache:               cache,
+		SplitOptions:        split.DefaultOptions(),
+	}
+	return context.C45(instances, maxDepth)
+}
+
+// C45 grows a (sub)tree from the instances using the settings of the context
+func (tc TreeContext) C45(instances []t.Instance, maxDepth int) *t.Node {
+	features := tc.Features
+	targetFeature := tc.TargetFeature
+
 	// Base case 1: If there are no instances, return a leaf node
 	if len(instances) == 0 {
 		return &t.Node{IsLeaf: true}
 	}
 
+	// Summarise the (weighted) target values
+	leaf, pure, weight := tc.summarise(instances)
+
 	// Base case 2: If maximum depth reached, return a leaf node
 	if maxDepth <= 0 {
-		return &t.Node{
-			IsLeaf: true,
-			Class:  ndp.GetMajorityClass(instances, targetFeature),
-		}
-	}
-
-	// Check if all instances have the same target value
-	counter := counter.NewClassCounter()
-	for _, instance := range instances {
-		targetVal := fmt.Sprintf("%v", instance[targetFeature])
-		counter.Add(targetVal)
+		return tc.finishLeaf(leaf, instances)
 	}
 
-	// Base case 3: If all instances belong to the same class
-	if len(counter.Counts) == 1 {
-		return &t.Node{
-			IsLeaf: true,
-			Class:  counter.GetMajorityClass(),
-		}
+	// Base case 3: If all instances belong to the same class (or share one target value)
+	if pure {
+		return tc.finishLeaf(leaf, instances)
 	}
 
-	// Base case 4: If there are no features left or fewer than minInstancesPerLeaf
-	if len(features) == 0 || len(instances) < minInstancesPerLeaf {
-		return &t.Node{
-			IsLeaf: true,
-			Class:  counter.GetMajorityClass(),
-		}
+	// Base case 4: If there are no features left or less weight than minInstancesPerLeaf
+	if len(features) == 0 || weight < float64(tc.MinInstancesPerLeaf) {
+		return tc.finishLeaf(leaf, instances)
 	}
 
 	// Find the best feature to split on
-	bestFeature, _, isContinuous, threshold := split.FindBestSplit(instances, features, targetFeature, featureTypes, excludedFeatures, cache)
+	best := split.FindBestSplitWithOptions(instances, features, targetFeature, tc.FeatureTypes, tc.ExcludedFeatures, tc.Cache, tc.SplitOptions)
+	bestFeature, isContinuous, threshold := best.Feature, best.IsContinuous, best.Threshold
 
 	// If no good split found, return a leaf node
 	if bestFeature == "" {
-		return &t.Node{
-			IsLeaf: true,
-			Class:  counter.GetMajorityClass(),
-		}
+		return tc.finishLeaf(leaf, instances)
 	}
 
 	// Create a decision node
 	node := &t.Node{
-		Feature:    bestFeature,
-		IsLeaf:     false,
-		Continuous: isContinuous,
-		Threshold:  threshold,
+		Feature:     bestFeature,
+		IsLeaf:      false,
+		Continuous:  isContinuous,
+		Threshold:   threshold,
+		ClassCounts: leaf.ClassCounts,
+		Samples:     leaf.Samples,
+		Mean:        leaf.Mean,
+		Gain:        best.Gain,
 	}
 
 	if isContinuous {
@@ -94,12 +118,35 @@ func C45(instances []t.Instance, features []string, targetFeature string, featur
 		}
 
 		// Create the child nodes
-		leftNode := C45(leftInstances, features, targetFeature, featureTypes, excludedFeatures, minInstancesPerLeaf, maxDepth-1, cache)
-		rightNode := C45(rightInstances, features, targetFeature, featureTypes, excludedFeatures, minInstancesPerLeaf, maxDepth-1, cache)
+		leftNode := tc.C45(leftInstances, maxDepth-1)
+		rightNode := tc.C45(rightInstances, maxDepth-1)
 
 		// Add the children to the node
 		node.Children = []*t.Node{leftNode, rightNode}
 		node.Value = threshold
+	} else if best.Binary {
+		// Split into values in the subset and everything else
+		inNode := tc.C45(utils.FilterInstancesInSet(instances, bestFeature, best.Groups[0], true), maxDepth-1)
+		outNode := tc.C45(utils.FilterInstancesInSet(instances, bestFeature, best.Groups[0], false), maxDepth-1)
+		inNode.Values = best.Groups[0]
+		outNode.Values = best.Groups[1]
+
+		node.Children = []*t.Node{inNode, outNode}
+		node.Binary = true
+	} else if best.Groups != nil {
+		// Create a child node for each group of values
+		children := make([]*t.Node, 0, len(best.Groups)+1)
+		for _, group := range best.Groups {
+			subsetInstances := utils.FilterInstancesInSet(instances, bestFeature, group, true)
+			childNode := tc.C45(subsetInstances, maxDepth-1)
+			childNode.Values = group
+			children = append(children, childNode)
+		}
+
+		// Values unseen at this node fall back to the majority class
+		children = append(children, fallbackLeaf(leaf))
+
+		node.Children = children
 	} else {
 		// Get all unique values for the feature
 		featureValues := make(map[string]bool)
@@ -108,12 +155,18 @@ func C45(instances []t.Instance, features []string, targetFeature string, featur
 			featureValues[val] = true
 		}
 
-		// Create a child node for each value
-		children := make([]*t.Node, 0, len(featureValues))
+		// Create a child node for each value, in sorted order
+		values := make([]string, 0, len(featureValues))
 		for value := range featureValues {
+			values = append(values, value)
+		}
+		sort.Strings(values)
+
+		children := make([]*t.Node, 0, len(featureValues))
+		for _, value := range values {
 			subsetInstances := utils.FilterInstances(instances, bestFeature, value, false, 0)
 			if len(subsetInstances) > 0 {
-				childNode := C45(subsetInstances, features, targetFeature, featureTypes, excludedFeatures, minInstancesPerLeaf, maxDepth-1, cache)
+				childNode := tc.C45(subsetInstances, maxDepth-1)
 				childNode.Value = value
 				children = append(children, childNode)
 			}
@@ -121,13 +174,7 @@ func C45(instances []t.Instance, features []string, targetFeature string, featur
 
 		// Handle missing values by adding a majority class child
 		if len(children) < len(featureValues) {
-			majorityClass := counter.GetMajorityClass()
-			missingValueNode := &t.Node{
-				IsLeaf: true,
-				Class:  majorityClass,
-				Value:  "unknown",
-			}
-			children = append(children, missingValueNode)
+			children = append(children, fallbackLeaf(leaf))
 		}
 
 		node.Children = children
@@ -135,3 +182,73 @@ func C45(instances []t.Instance, features []string, targetFeature string, featur
 
 	return node
 }
+
+// summarise builds the leaf this node would become from the (weighted) target
+// values. It also reports whether the target is pure and the total weight.
+func (tc TreeContext) summarise(instances []t.Instance) (*t.Node, bool, float64) {
+	weightFeature := tc.SplitOptions.WeightFeature
+
+	if tc.SplitOptions.Regression != nil {
+		stats := counter.NewTargetStats()
+		for _, instance := range instances {
+			if target, ok := split.TargetValue(instance, tc.TargetFeature); ok {
+				stats.Add(target, utils.InstanceWeight(instance, weightFeature))
+			}
+		}
+
+		mean := stats.Mean()
+		leaf := &t.Node{IsLeaf: true, Mean: mean, Samples: stats.Weight}
+		return leaf, stats.Variance() <= 1e-12*(1+mean*mean), stats.Weight
+	}
+
+	counter := counter.NewClassCounter()
+	for _, instance := range instances {
+		targetVal := fmt.Sprintf("%v", instance[tc.TargetFeature])
+		counter.AddWeighted(targetVal, utils.InstanceWeight(instance, weightFeature))
+	}
+
+	leaf := &t.Node{
+		IsLeaf:      true,
+		Class:       tc.leafClass(counter),
+		ClassCounts: counter.Counts,
+		Samples:     counter.Total,
+	}
+	return leaf, len(counter.Counts) == 1, counter.Total
+}
+
+// finishLeaf fits the leaf's linear model when regression trees use them
+func (tc TreeContext) finishLeaf(leaf *t.Node, instances []t.Instance) *t.Node {
+	if tc.SplitOptions.Regression != nil && tc.LinearLeaves {
+		leaf.LinearModel = FitLinearModel(instances, tc.TargetFeature, tc.numericFeatures(), tc.SplitOptions.WeightFeature)
+	}
+	return leaf
+}
+
+// fallbackLeaf creates the "unknown" child for values unseen at a decision node
+func fallbackLeaf(leaf *t.Node) *t.Node {
+	return &t.Node{
+		IsLeaf: true,
+		Class:  leaf.Class,
+		Mean:   leaf.Mean,
+		Value:  "unknown",
+	}
+}
+
+// numericFeatures returns the splitting features with a numerical type
+func (tc TreeContext) numericFeatures() []string {
+	numeric := make([]string, 0, len(tc.Features))
+	for _, feature := range tc.Features {
+		if tc.FeatureTypes[feature] == "numerical" && !tc.ExcludedFeatures[feature] {
+			numeric = append(numeric, feature)
+		}
+	}
+	return numeric
+}
+
+// leafClass picks the majority class, or the cheapest class when costs are set
+func (tc TreeContext) leafClass(counter *counter.ClassCounter) string {
+	if tc.Costs == nil {
+		return counter.GetMajorityClassInOrder(tc.ClassOrder)
+	}
+	return tc.Costs.MinExpectedCostClass(counter.Counts)
+}
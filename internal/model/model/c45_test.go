@@ -1,9 +1,14 @@
 package model
 
 import (
+	"encoding/json"
+	"fmt"
+	"math"
 	"testing"
 
 	"github.com/nyunja/c4.5-decision-tree/internal/model/cache"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/fixtures"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/split"
 	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
 	"github.com/stretchr/testify/assert"
 )
@@ -62,3 +67,335 @@ func TestC45_CategoricalSplit(tc *testing.T) {
 	assert.NotNil(tc, tree)
 	assert.Equal(tc, "", tree.Feature)
 }
+
+func TestC45_GolfDatasetMatchesReference(tc *testing.T) {
+	instances := fixtures.Golf()
+	features := fixtures.GolfFeatures
+	featureTypes := fixtures.GolfTypes()
+	cache := cache.NewFeatureCache()
+	cache.PrecomputeFeatureValues(instances, features, "play", featureTypes)
+
+	tree := C45(instances, features, "play", featureTypes, map[string]bool{}, 2, 5, cache)
+
+	// Quinlan's tree splits on outlook first and overcast is always played
+	assert.Equal(tc, "outlook", tree.Feature)
+	for _, child := range tree.Children {
+		if child.Value == "overcast" {
+			assert.True(tc, child.IsLeaf)
+			assert.Equal(tc, "yes", child.Class)
+		}
+	}
+}
+
+func TestC45_SubsetGroupingLimitsChildren(tc *testing.T) {
+	instances := []t.Instance{}
+	for i := 0; i < 60; i++ {
+		class := "A"
+		if i%30 >= 15 {
+			class = "B"
+		}
+		instances = append(instances, t.Instance{"product": fmt.Sprintf("p%d", i%30), "category": class})
+	}
+	features := []string{"product"}
+	featureTypes := map[string]string{"product": "categorical", "category": "categorical"}
+	cache := cache.NewFeatureCache()
+	cache.PrecomputeFeatureValues(instances, features, "category", featureTypes)
+
+	options := split.DefaultOptions()
+	options.SubsetGrouping = true
+	context := TreeContext{
+		Features:            features,
+		TargetFeature:       "category",
+		FeatureTypes:        featureTypes,
+		ExcludedFeatures:    map[string]bool{},
+		MinInstancesPerLeaf: 2,
+		Cache:               cache,
+		SplitOptions:        options,
+	}
+	tree := context.C45(instances, 5)
+
+	// Two value groups plus the fallback for unseen values instead of 30 children
+	assert.Equal(tc, "product", tree.Feature)
+	assert.Len(tc, tree.Children, 3)
+	assert.Len(tc, tree.Children[0].Values, 15)
+	assert.True(tc, tree.Children[0].IsLeaf)
+}
+
+func TestC45_BinaryCategoricalSplit(tc *testing.T) {
+	instances := []t.Instance{
+		{"colour": "red", "label": "yes"},
+		{"colour": "red", "label": "yes"},
+		{"colour": "pink", "label": "yes"},
+		{"colour": "blue", "label": "no"},
+		{"colour": "blue", "label": "no"},
+		{"colour": "navy", "label": "no"},
+	}
+	features := []string{"colour"}
+	featureTypes := map[string]string{"colour": "categorical", "label": "categorical"}
+	cache := cache.NewFeatureCache()
+	cache.PrecomputeFeatureValues(instances, features, "label", featureTypes)
+
+	context := TreeContext{
+		Features:            features,
+		TargetFeature:       "label",
+		FeatureTypes:        featureTypes,
+		ExcludedFeatures:    map[string]bool{},
+		MinInstancesPerLeaf: 1,
+		Cache:               cache,
+		SplitOptions:        split.Options{BinaryCategorical: true},
+	}
+	tree := context.C45(instances, 5)
+
+	assert.True(tc, tree.Binary)
+	assert.Len(tc, tree.Children, 2)
+	assert.ElementsMatch(tc, []string{"pink", "red"}, tree.Children[0].Values)
+	assert.Equal(tc, "yes", tree.Children[0].Class)
+	assert.Equal(tc, "no", tree.Children[1].Class)
+}
+
+// imbalancedInstances returns 8 legit and 2 fraud instances with a weight column
+func imbalancedInstances(fraudWeight float64) []t.Instance {
+	instances := []t.Instance{}
+	for i := 0; i < 10; i++ {
+		label, weight := "legit", 1.0
+		if i < 2 {
+			label, weight = "fraud", fraudWeight
+		}
+		instances = append(instances, t.Instance{"amount": "any", "label": label, "w": weight})
+	}
+	return instances
+}
+
+func TestTrainWithOptions_InstanceWeights(tc *testing.T) {
+	featureTypes := map[string]string{"amount": "categorical", "label": "categorical", "w": "numerical"}
+	headers := []string{"amount", "label", "w"}
+
+	options := DefaultTrainOptions()
+	model, err := TrainWithOptions(imbalancedInstances(1), headers, "label", featureTypes, nil, options)
+	assert.NoError(tc, err)
+	assert.Equal(tc, "legit", model.Root.Class)
+
+	// Upweighting the rare class flips the leaf
+	options.WeightColumn = "w"
+	model, err = TrainWithOptions(imbalancedInstances(10), headers, "label", featureTypes, nil, options)
+	assert.NoError(tc, err)
+	assert.Equal(tc, "fraud", model.Root.Class)
+	assert.Equal(tc, map[string]float64{"fraud": 20, "legit": 8}, model.Root.ClassCounts)
+	assert.Equal(tc, "w", model.WeightColumn)
+
+	options.WeightColumn = "missing"
+	_, err = TrainWithOptions(imbalancedInstances(1), headers, "label", featureTypes, nil, options)
+	assert.Error(tc, err)
+
+	options.WeightColumn = "amount"
+	_, err = TrainWithOptions(imbalancedInstances(1), headers, "label", featureTypes, nil, options)
+	assert.ErrorContains(tc, err, "must be numerical")
+}
+
+func TestTrainWithOptions_InvalidWeights(tc *testing.T) {
+	featureTypes := map[string]string{"amount": "categorical", "label": "categorical", "w": "numerical"}
+	headers := []string{"amount", "label", "w"}
+	options := DefaultTrainOptions()
+	options.WeightColumn = "w"
+
+	for _, weight := range []interface{}{-1.0, math.NaN(), math.Inf(1), "heavy"} {
+		instances := imbalancedInstances(1)
+		instances[3]["w"] = weight
+		_, err := TrainWithOptions(instances, headers, "label", featureTypes, nil, options)
+		assert.ErrorContains(tc, err, "row 4", "weight %v", weight)
+	}
+
+	// Missing weights count as 1
+	instances := imbalancedInstances(1)
+	instances[3]["w"] = nil
+	_, err := TrainWithOptions(instances, headers, "label", featureTypes, nil, options)
+	assert.NoError(tc, err)
+}
+
+func TestTrainWithOptions_WeightedLeafSize(tc *testing.T) {
+	featureTypes := map[string]string{"colour": "categorical", "label": "categorical", "w": "numerical"}
+	headers := []string{"colour", "label", "w"}
+	instances := []t.Instance{}
+	for i := 0; i < 8; i++ {
+		colour, label := "red", "yes"
+		if i%2 == 1 {
+			colour, label = "blue", "no"
+		}
+		instances = append(instances, t.Instance{"colour": colour, "label": label, "w": 0.25})
+	}
+
+	// 8 instances weighing 2 in total are too few to split with a minimum of 4
+	options := DefaultTrainOptions()
+	options.MinInstancesPerLeaf = 4
+	options.WeightColumn = "w"
+	model, err := TrainWithOptions(instances, headers, "label", featureTypes, nil, options)
+	assert.NoError(tc, err)
+	assert.True(tc, model.Root.IsLeaf)
+	assert.Equal(tc, 2.0, model.Root.Samples)
+
+	options.WeightColumn = ""
+	model, err = TrainWithOptions(instances, headers, "label", featureTypes, nil, options)
+	assert.NoError(tc, err)
+	assert.Equal(tc, "colour", model.Root.Feature)
+}
+
+func TestTrainWithOptions_CostSensitiveLeaves(tc *testing.T) {
+	featureTypes := map[string]string{"amount": "categorical", "label": "categorical"}
+	headers := []string{"amount", "label"}
+
+	// Missing a fraud costs 100x a false alarm, so 2 frauds in 10 are enough
+	options := DefaultTrainOptions()
+	options.Costs = t.CostMatrix{"fraud": {"legit": 100}}
+	model, err := TrainWithOptions(imbalancedInstances(1), headers, "label", featureTypes, nil, options)
+	assert.NoError(tc, err)
+	assert.Equal(tc, "fraud", model.Root.Class)
+	assert.Equal(tc, options.Costs, model.Costs)
+}
+
+func TestResolveTask(tc *testing.T) {
+	featureTypes := map[string]string{"y": "numerical", "label": "categorical"}
+	binary := []t.Instance{{"y": 0.0}, {"y": 1.0}, {"y": 1.0}}
+	continuous := []t.Instance{{"y": 0.5}, {"y": 1.0}}
+
+	tests := []struct {
+		name      string
+		task      string
+		instances []t.Instance
+		target    string
+		want      string
+	}{
+		{"categorical target", "", binary, "label", t.Classification},
+		{"integer 0/1 target", "auto", binary, "y", t.Classification},
+		{"fractional target", "auto", continuous, "y", t.Regression},
+		{"explicit regression", t.Regression, binary, "y", t.Regression},
+		{"explicit classification", t.Classification, continuous, "y", t.Classification},
+	}
+	for _, tt := range tests {
+		tc.Run(tt.name, func(tc *testing.T) {
+			got, err := ResolveTask(tt.task, tt.instances, tt.target, featureTypes)
+			assert.NoError(tc, err)
+			assert.Equal(tc, tt.want, got)
+		})
+	}
+
+	_, err := ResolveTask("clustering", binary, "y", featureTypes)
+	assert.Error(tc, err)
+}
+
+func TestTrainWithOptions_RegressionTree(tc *testing.T) {
+	instances := make([]t.Instance, 0, 20)
+	for i := 0; i < 20; i++ {
+		price := 10.0 + 0.1*float64(i)
+		if i >= 10 {
+			price += 40
+		}
+		instances = append(instances, t.Instance{"size": float64(i), "price": price})
+	}
+	featureTypes := map[string]string{"size": "numerical", "price": "numerical"}
+	headers := []string{"size", "price"}
+
+	options := DefaultTrainOptions()
+	options.MaxDepth = 1
+	model, err := TrainWithOptions(instances, headers, "price", featureTypes, nil, options)
+	assert.NoError(tc, err)
+	assert.Equal(tc, t.Regression, model.Task)
+	assert.Equal(tc, "size", model.Root.Feature)
+	assert.Equal(tc, 9.5, model.Root.Threshold)
+	assert.InDelta(tc, 10.45, model.Root.Children[0].Mean, 1e-9)
+	assert.InDelta(tc, 51.45, model.Root.Children[1].Mean, 1e-9)
+	assert.Nil(tc, model.Root.Children[0].LinearModel)
+
+	// Linear leaves recover the slope within each segment
+	options.LinearLeaves = true
+	model, err = TrainWithOptions(instances, headers, "price", featureTypes, nil, options)
+	assert.NoError(tc, err)
+	right := model.Root.Children[1].LinearModel
+	if assert.NotNil(tc, right) {
+		assert.InDelta(tc, 0.1, right.Coefficients["size"], 1e-4)
+		assert.InDelta(tc, 50.0, right.Intercept, 1e-3)
+	}
+}
+
+func TestFitLinearModel(tc *testing.T) {
+	instances := []t.Instance{
+		{"x": 0.0, "z": 1.0, "y": 1.0},
+		{"x": 1.0, "z": 0.0, "y": 4.0},
+		{"x": 2.0, "z": 2.0, "y": 4.0},
+		{"x": 3.0, "z": 1.0, "y": 7.0},
+		{"x": 4.0, "z": 3.0, "y": 7.0},
+	}
+	// y = 2x - z + 2
+	linear := FitLinearModel(instances, "y", []string{"x", "z"}, "")
+	if assert.NotNil(tc, linear) {
+		assert.InDelta(tc, 2.0, linear.Intercept, 1e-4)
+		assert.InDelta(tc, 2.0, linear.Coefficients["x"], 1e-4)
+		assert.InDelta(tc, -1.0, linear.Coefficients["z"], 1e-4)
+	}
+
+	// Too few instances for three parameters
+	assert.Nil(tc, FitLinearModel(instances[:3], "y", []string{"x", "z"}, ""))
+}
+
+func TestTrainWithOptions_Deterministic(tc *testing.T) {
+	// Many values per feature, equally good features and tied leaves
+	instances := make([]t.Instance, 0, 60)
+	for i := 0; i < 60; i++ {
+		label := []string{"yes", "no", "maybe"}[i%3]
+		if i%7 == 0 {
+			label = "yes"
+		}
+		instances = append(instances, t.Instance{
+			"colour": fmt.Sprintf("c%d", i%9),
+			"shade":  fmt.Sprintf("s%d", i%9),
+			"size":   float64(i % 5),
+			"label":  label,
+		})
+	}
+	headers := []string{"colour", "shade", "size", "label"}
+	featureTypes := map[string]string{"colour": "categorical", "shade": "categorical", "size": "numerical", "label": "categorical"}
+
+	options := DefaultTrainOptions()
+	options.MinInstancesPerLeaf = 2
+	var first []byte
+	for i := 0; i < 10; i++ {
+		model, err := TrainWithOptions(instances, headers, "label", featureTypes, nil, options)
+		assert.NoError(tc, err)
+		encoded, err := json.Marshal(model)
+		assert.NoError(tc, err)
+		if first == nil {
+			first = encoded
+			continue
+		}
+		assert.Equal(tc, string(first), string(encoded))
+	}
+
+	// colour and shade tie, so the feature listed first wins and the
+	// children follow the sorted values
+	model, _ := TrainWithOptions(instances, headers, "label", featureTypes, nil, options)
+	assert.Equal(tc, "colour", model.Root.Feature)
+	for i := 1; i < len(model.Root.Children); i++ {
+		assert.Less(tc, fmt.Sprint(model.Root.Children[i-1].Value), fmt.Sprint(model.Root.Children[i].Value))
+	}
+}
+
+func TestLeafClassTiesFollowClassFrequency(tc *testing.T) {
+	// "b" is the more frequent class overall, so it wins the tied leaf
+	instances := []t.Instance{
+		{"f": "x", "label": "a"},
+		{"f": "x", "label": "b"},
+		{"f": "y", "label": "b"},
+		{"f": "y", "label": "b"},
+	}
+	featureTypes := map[string]string{"f": "categorical", "label": "categorical"}
+
+	options := DefaultTrainOptions()
+	options.MaxDepth = 0
+	model, err := TrainWithOptions(instances[:2], []string{"f", "label"}, "label", featureTypes, nil, options)
+	assert.NoError(tc, err)
+	assert.Equal(tc, "a", model.Root.Class)
+
+	context, _, err := newTreeContext(instances, []string{"f", "label"}, "label", featureTypes, nil, options)
+	assert.NoError(tc, err)
+	assert.Equal(tc, []string{"b", "a"}, context.ClassOrder)
+	assert.Equal(tc, "b", context.C45(instances[:2], 0).Class)
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+)
+
+// LoadCostMatrix reads misclassification costs from a CSV file with the
+// header actual,predicted,cost and one row per class pair
+func LoadCostMatrix(filename string) (t.CostMatrix, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error opening cost matrix: %v", err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading cost matrix: %v", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("cost matrix %s is empty", filename)
+	}
+
+	header := records[0]
+	if len(header) != 3 || strings.TrimSpace(header[0]) != "actual" || strings.TrimSpace(header[1]) != "predicted" || strings.TrimSpace(header[2]) != "cost" {
+		return nil, fmt.Errorf("cost matrix header must be actual,predicted,cost, got %v", header)
+	}
+
+	costs := make(t.CostMatrix)
+	for i, record := range records[1:] {
+		if len(record) != 3 {
+			return nil, fmt.Errorf("cost matrix line %d: expected 3 fields, got %d", i+2, len(record))
+		}
+		cost, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if err != nil || cost < 0 {
+			return nil, fmt.Errorf("cost matrix line %d: invalid cost '%s'", i+2, record[2])
+		}
+
+		actual, predicted := strings.TrimSpace(record[0]), strings.TrimSpace(record[1])
+		if costs[actual] == nil {
+			costs[actual] = make(map[string]float64)
+		}
+		costs[actual][predicted] = cost
+	}
+
+	return costs, nil
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadCostMatrix(tc *testing.T) {
+	dir := tc.TempDir()
+
+	valid := filepath.Join(dir, "costs.csv")
+	os.WriteFile(valid, []byte("actual,predicted,cost\nfraud,legit,100\nlegit,fraud,1\n"), 0o644)
+	costs, err := LoadCostMatrix(valid)
+	assert.NoError(tc, err)
+	assert.Equal(tc, t.CostMatrix{"fraud": {"legit": 100}, "legit": {"fraud": 1}}, costs)
+	assert.Equal(tc, 0.0, costs.Cost("fraud", "fraud"))
+
+	badHeader := filepath.Join(dir, "bad_header.csv")
+	os.WriteFile(badHeader, []byte("from,to,cost\nfraud,legit,100\n"), 0o644)
+	_, err = LoadCostMatrix(badHeader)
+	assert.Error(tc, err)
+
+	badCost := filepath.Join(dir, "bad_cost.csv")
+	os.WriteFile(badCost, []byte("actual,predicted,cost\nfraud,legit,-3\n"), 0o644)
+	_, err = LoadCostMatrix(badCost)
+	assert.Error(tc, err)
+
+	_, err = LoadCostMatrix(filepath.Join(dir, "missing.csv"))
+	assert.Error(tc, err)
+}
@@ -0,0 +1,240 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/predict"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/split"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+)
+
+// ForestOptions holds the parameters of a random forest or bagging ensemble
+type ForestOptions struct {
+	// Trees is the number of trees to grow
+	Trees int
+	// MaxFeatures is the number of features drawn at each node of a forest.
+	// When it is 0, sqrt(p) features are drawn for classification and p/3
+	// for regression. Bagging ignores it.
+	MaxFeatures int
+	// Voting combines the trees by majority (default) or probability voting
+	Voting string
+	// Workers is the number of trees grown concurrently, runtime.NumCPU() when 0
+	Workers int
+
+	// BagSize is the size of each tree's sample as a fraction of the
+	// instances, 1 when 0
+	BagSize float64
+	// WithoutReplacement draws each sample without replacement instead of
+	// bootstrapping; it needs a BagSize below 1 to leave instances out of bag
+	WithoutReplacement bool
+}
+
+// DefaultForestOptions returns the options used by the CLI
+func DefaultForestOptions() ForestOptions {
+	return ForestOptions{
+		Trees:   100,
+		Voting:  t.MajorityVote,
+		BagSize: 1,
+	}
+}
+
+// TrainForest trains a random forest: every tree is grown by C4.5 on a
+// bootstrap sample, choosing each split among a random subset of the features.
+// The out-of-bag error is estimated from the instances each tree did not see.
+func TrainForest(instances []t.Instance, headers []string, targetFeature string, featureTypes map[string]string, excludeColumns []string, options TrainOptions, forest ForestOptions) (*t.Model, error) {
+	return trainEnsemble(t.Forest, instances, headers, targetFeature, featureTypes, excludeColumns, options, forest)
+}
+
+// TrainBagging trains a bagging ensemble: every tree is grown by C4.5 on its
+// own sample of the instances, considering all features at each node
+func TrainBagging(instances []t.Instance, headers []string, targetFeature string, featureTypes map[string]string, excludeColumns []string, options TrainOptions, bagging ForestOptions) (*t.Model, error) {
+	return trainEnsemble(t.Bag, instances, headers, targetFeature, featureTypes, excludeColumns, options, bagging)
+}
+
+// trainEnsemble grows the trees of a forest or bagging ensemble in parallel.
+// Every tree draws from its own source seeded from options.Seed, so the
+// result does not depend on scheduling.
+func trainEnsemble(method string, instances []t.Instance, headers []string, targetFeature string, featureTypes map[string]string, excludeColumns []string, options TrainOptions, forest ForestOptions) (*t.Model, error) {
+	if forest.Trees <= 0 {
+		return nil, fmt.Errorf("an ensemble needs at least one tree, got %d", forest.Trees)
+	}
+	switch forest.Voting {
+	case "":
+		forest.Voting = t.MajorityVote
+	case t.MajorityVote, t.ProbabilityVote:
+	default:
+		return nil, fmt.Errorf("unknown voting '%s', expected majority or probability", forest.Voting)
+	}
+	if forest.BagSize == 0 {
+		forest.BagSize = 1
+	}
+	if forest.BagSize < 0 || (forest.WithoutReplacement && forest.BagSize > 1) {
+		return nil, fmt.Errorf("invalid bag size %v", forest.BagSize)
+	}
+
+	context, task, err := newTreeContext(instances, headers, targetFeature, featureTypes, excludeColumns, options)
+	if err != nil {
+		return nil, err
+	}
+	if method == t.Forest {
+		if forest.MaxFeatures <= 0 {
+			forest.MaxFeatures = defaultMaxFeatures(len(context.Features), task)
+		}
+		context.SplitOptions.FeatureSubset = forest.MaxFeatures
+	} else {
+		forest.MaxFeatures = 0
+	}
+
+	bagSize := int(math.Round(forest.BagSize * float64(len(instances))))
+	if bagSize < 1 {
+		bagSize = 1
+	}
+
+	// Draw the tree seeds up front so they do not depend on scheduling
+	seed := ResolveSeed(options.Seed)
+	source := rand.New(rand.NewPCG(uint64(seed), 0))
+	seeds := make([]int64, forest.Trees)
+	for i := range seeds {
+		seeds[i] = source.Int64()
+	}
+
+	trees := make([]*t.Node, forest.Trees)
+	inBag := make([][]bool, forest.Trees)
+
+	workers := forest.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	treesChan := make(chan int, forest.Trees)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range treesChan {
+				rng := rand.New(rand.NewPCG(uint64(seeds[i]), 0))
+				var sample []t.Instance
+				sample, inBag[i] = Sample(instances, bagSize, !forest.WithoutReplacement, rng)
+
+				treeContext := context
+				treeContext.SplitOptions.Rand = rng
+				trees[i] = treeContext.C45(sample, options.MaxDepth)
+			}
+		}()
+	}
+	for i := 0; i < forest.Trees; i++ {
+		treesChan <- i
+	}
+	close(treesChan)
+	wg.Wait()
+
+	model := newModel(headers, targetFeature, featureTypes, task, options, context.ClassOrder)
+	model.Ensemble = &t.Ensemble{
+		Method:      method,
+		Voting:      forest.Voting,
+		Trees:       trees,
+		MaxFeatures: forest.MaxFeatures,
+		Seed:        seed,
+	}
+	model.Ensemble.OOBError = OOBError(model, instances, inBag)
+
+	return model, nil
+}
+
+// defaultMaxFeatures returns Breiman's default number of features per node
+func defaultMaxFeatures(features int, task string) int {
+	n := int(math.Sqrt(float64(features)))
+	if task == t.Regression {
+		n = features / 3
+	}
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+// Bootstrap draws len(instances) instances with replacement. It also returns
+// which of the instances were drawn at least once.
+func Bootstrap(instances []t.Instance, rng *rand.Rand) ([]t.Instance, []bool) {
+	return Sample(instances, len(instances), true, rng)
+}
+
+// Sample draws size instances with or without replacement. It also returns
+// which of the instances were drawn at least once. Without replacement the
+// sample keeps the order of the instances.
+func Sample(instances []t.Instance, size int, replacement bool, rng *rand.Rand) ([]t.Instance, []bool) {
+	inBag := make([]bool, len(instances))
+	if !replacement {
+		if size > len(instances) {
+			size = len(instances)
+		}
+		for _, index := range rng.Perm(len(instances))[:size] {
+			inBag[index] = true
+		}
+
+		sample := make([]t.Instance, 0, size)
+		for i, instance := range instances {
+			if inBag[i] {
+				sample = append(sample, instance)
+			}
+		}
+		return sample, inBag
+	}
+
+	sample := make([]t.Instance, size)
+	for i := range sample {
+		index := rng.IntN(len(instances))
+		sample[i] = instances[index]
+		inBag[index] = true
+	}
+	return sample, inBag
+}
+
+// OOBError estimates the error of an ensemble by predicting every instance
+// with only the trees whose sample did not contain it. inBag[i][j] tells
+// whether tree i was trained on instance j. Instances that were in every
+// sample are skipped.
+func OOBError(model *t.Model, instances []t.Instance, inBag [][]bool) float64 {
+	trees := model.Ensemble.Trees
+	errors := 0.0
+	count := 0
+
+	for j, instance := range instances {
+		outOfBag := make([]*t.Node, 0, len(trees))
+		for i, tree := range trees {
+			if !inBag[i][j] {
+				outOfBag = append(outOfBag, tree)
+			}
+		}
+		if len(outOfBag) == 0 {
+			continue
+		}
+
+		if model.Task == t.Regression {
+			actual, ok := split.TargetValue(instance, model.TargetName)
+			if !ok {
+				continue
+			}
+			sum := 0.0
+			for _, tree := range outOfBag {
+				sum += predict.TreeValue(tree, instance)
+			}
+			residual := actual - sum/float64(len(outOfBag))
+			errors += residual * residual
+		} else {
+			if predict.BestClass(predict.Votes(model, outOfBag, nil, instance)) != fmt.Sprintf("%v", instance[model.TargetName]) {
+				errors++
+			}
+		}
+		count++
+	}
+
+	if count == 0 {
+		return 0
+	}
+	return errors / float64(count)
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/predict"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// churnInstances labels customers by a rule on two of four features
+func churnInstances(n int) []t.Instance {
+	rng := rand.New(rand.NewPCG(42, 0))
+	instances := make([]t.Instance, 0, n)
+	for i := 0; i < n; i++ {
+		tenure := float64(rng.IntN(60))
+		calls := float64(rng.IntN(10))
+		label := "stay"
+		if tenure < 12 && calls > 4 {
+			label = "churn"
+		}
+		instances = append(instances, t.Instance{
+			"tenure": tenure,
+			"calls":  calls,
+			"noise":  rng.Float64(),
+			"plan":   fmt.Sprintf("p%d", rng.IntN(3)),
+			"label":  label,
+		})
+	}
+	return instances
+}
+
+func TestTrainForest(tc *testing.T) {
+	instances := churnInstances(300)
+	headers := []string{"tenure", "calls", "noise", "plan", "label"}
+	featureTypes := map[string]string{"tenure": "numerical", "calls": "numerical", "noise": "numerical", "plan": "categorical", "label": "categorical"}
+
+	forest := DefaultForestOptions()
+	forest.Trees = 15
+	model, err := TrainForest(instances, headers, "label", featureTypes, nil, DefaultTrainOptions(), forest)
+	assert.NoError(tc, err)
+	assert.Nil(tc, model.Root)
+	assert.Equal(tc, t.Forest, model.Ensemble.Method)
+	assert.Len(tc, model.Ensemble.Trees, 15)
+	assert.Equal(tc, 2, model.Ensemble.MaxFeatures)
+	assert.Less(tc, model.Ensemble.OOBError, 0.1)
+
+	correct := 0
+	for _, instance := range instances {
+		if predict.PredictClass(model, instance) == instance["label"] {
+			correct++
+		}
+	}
+	assert.Greater(tc, correct, 285)
+
+	forest.Voting = "unanimous"
+	_, err = TrainForest(instances, headers, "label", featureTypes, nil, DefaultTrainOptions(), forest)
+	assert.Error(tc, err)
+}
+
+func TestBootstrap(tc *testing.T) {
+	instances := []t.Instance{{"id": 0}, {"id": 1}, {"id": 2}, {"id": 3}}
+	sample, inBag := Bootstrap(instances, rand.New(rand.NewPCG(7, 0)))
+	assert.Len(tc, sample, len(instances))
+
+	drawn := make([]bool, len(instances))
+	for _, instance := range sample {
+		drawn[instance["id"].(int)] = true
+	}
+	assert.Equal(tc, drawn, inBag)
+}
+
+func TestOOBError(tc *testing.T) {
+	yes := &t.Node{IsLeaf: true, Class: "yes"}
+	no := &t.Node{IsLeaf: true, Class: "no"}
+	model := &t.Model{TargetName: "label", Ensemble: &t.Ensemble{Trees: []*t.Node{yes, no}}}
+	instances := []t.Instance{{"label": "yes"}, {"label": "yes"}, {"label": "no"}}
+
+	// Instance 0 is only out of tree "no"'s bag, instance 1 only out of
+	// tree "yes"'s bag and instance 2 is in both bags
+	inBag := [][]bool{{true, false, true}, {false, true, true}}
+	assert.Equal(tc, 0.5, OOBError(model, instances, inBag))
+}
@@ -0,0 +1,117 @@
+package model
+
+import (
+	"math"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/split"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
+)
+
+// ridge is the penalty added to the normal equations to keep them solvable
+// when features are collinear within a leaf
+const ridge = 1e-6
+
+// FitLinearModel fits a weighted least-squares model of the target on the
+// numeric features, as M5 does at its leaves. Instances missing any of the
+// features are skipped. It returns nil when there are too few instances.
+func FitLinearModel(instances []t.Instance, targetFeature string, features []string, weightFeature string) *t.LinearModel {
+	p := len(features) + 1 // one column per feature plus the intercept
+	xtx := make([][]float64, p)
+	for i := range xtx {
+		xtx[i] = make([]float64, p)
+	}
+	xty := make([]float64, p)
+	row := make([]float64, p)
+	used := 0
+
+	for _, instance := range instances {
+		target, ok := split.TargetValue(instance, targetFeature)
+		if !ok {
+			continue
+		}
+
+		row[0] = 1
+		complete := true
+		for j, feature := range features {
+			value, ok := split.ExtractNumericValue(instance[feature])
+			if !ok {
+				complete = false
+				break
+			}
+			row[j+1] = value
+		}
+		if !complete {
+			continue
+		}
+
+		weight := utils.InstanceWeight(instance, weightFeature)
+		for i := 0; i < p; i++ {
+			xty[i] += weight * row[i] * target
+			for j := 0; j < p; j++ {
+				xtx[i][j] += weight * row[i] * row[j]
+			}
+		}
+		used++
+	}
+
+	if used < p+1 {
+		return nil
+	}
+
+	// Penalise the coefficients, but not the intercept, relative to their scale
+	for i := 1; i < p; i++ {
+		xtx[i][i] += ridge * (xtx[i][i] + 1)
+	}
+
+	coefficients, ok := solveLinearSystem(xtx, xty)
+	if !ok {
+		return nil
+	}
+
+	model := &t.LinearModel{
+		Intercept:    coefficients[0],
+		Coefficients: make(map[string]float64, len(features)),
+	}
+	for j, feature := range features {
+		model.Coefficients[feature] = coefficients[j+1]
+	}
+	return model
+}
+
+// solveLinearSystem solves a x = b by Gaussian elimination with partial pivoting.
+// It modifies a and b and reports false when the system is singular.
+func solveLinearSystem(a [][]float64, b []float64) ([]float64, bool) {
+	n := len(b)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for r := col + 1; r < n; r++ {
+			if math.Abs(a[r][col]) > math.Abs(a[pivot][col]) {
+				pivot = r
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return nil, false
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		b[col], b[pivot] = b[pivot], b[col]
+
+		for r := col + 1; r < n; r++ {
+			factor := a[r][col] / a[col][col]
+			for c := col; c < n; c++ {
+				a[r][c] -= factor * a[col][c]
+			}
+			b[r] -= factor * b[col]
+		}
+	}
+
+	x := make([]float64, n)
+	for r := n - 1; r >= 0; r-- {
+		sum := b[r]
+		for c := r + 1; c < n; c++ {
+			sum -= a[r][c] * x[c]
+		}
+		x[r] = sum / a[r][r]
+	}
+	return x, true
+}
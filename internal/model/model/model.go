@@ -2,19 +2,131 @@ package model
 
 import (
 	"fmt"
+	"math"
+	"time"
 
 	"github.com/nyunja/c4.5-decision-tree/internal/model/cache"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/counter"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/split"
 	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
 )
 
+// TrainOptions holds the tunable parameters of training
+type TrainOptions struct {
+	MaxDepth            int
+	MinInstancesPerLeaf int
+	Split               split.Options
+
+	// WeightColumn names a numeric column of instance weights, unused when empty
+	WeightColumn string
+	// Costs holds misclassification costs, majority voting when nil
+	Costs t.CostMatrix
+
+	// Task is classification or regression; empty picks it from the target
+	Task string
+	// LinearLeaves fits a linear model at each regression leaf instead of the mean
+	LinearLeaves bool
+
+	// Seed makes the random draws of ensembles reproducible. A random seed
+	// is picked when it is 0.
+	Seed int64
+}
+
+// maxNumericClasses is the most distinct integer values a numerical target may
+// have and still be treated as class labels when the task is picked automatically
+const maxNumericClasses = 20
+
+// ResolveTask returns the task to train. Unless one is given, numerical targets
+// are regressed, except integer targets with few distinct values such as 0/1.
+func ResolveTask(task string, instances []t.Instance, targetFeature string, featureTypes map[string]string) (string, error) {
+	switch task {
+	case t.Classification, t.Regression:
+		return task, nil
+	case "", "auto":
+	default:
+		return "", fmt.Errorf("unknown task '%s', expected classification, regression or auto", task)
+	}
+
+	if featureTypes[targetFeature] != "numerical" {
+		return t.Classification, nil
+	}
+
+	distinct := make(map[float64]bool)
+	for _, instance := range instances {
+		value, ok := split.TargetValue(instance, targetFeature)
+		if !ok {
+			continue
+		}
+		if value != math.Trunc(value) {
+			return t.Regression, nil
+		}
+		distinct[value] = true
+		if len(distinct) > maxNumericClasses {
+			return t.Regression, nil
+		}
+	}
+	return t.Classification, nil
+}
+
+// DefaultTrainOptions returns the options used by the CLI, matching reference C4.5 splitting
+func DefaultTrainOptions() TrainOptions {
+	return TrainOptions{
+		MaxDepth:            20,
+		MinInstancesPerLeaf: 5,
+		Split:               split.DefaultOptions(),
+	}
+}
+
 // TrainModel trains a C4.5 decision tree model with optimizations for large datasets
 func Train(instances []t.Instance, headers []string, targetFeature string, featureTypes map[string]string, excludeColumns []string, maxDepth int) (*t.Model, error) {
+	options := DefaultTrainOptions()
+	options.MaxDepth = maxDepth
+	return TrainWithOptions(instances, headers, targetFeature, featureTypes, excludeColumns, options)
+}
+
+// TrainWithOptions trains a C4.5 decision tree model using the given options
+func TrainWithOptions(instances []t.Instance, headers []string, targetFeature string, featureTypes map[string]string, excludeColumns []string, options TrainOptions) (*t.Model, error) {
+	context, task, err := newTreeContext(instances, headers, targetFeature, featureTypes, excludeColumns, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Train the decision tree
+	model := newModel(headers, targetFeature, featureTypes, task, options, context.ClassOrder)
+	model.Root = context.C45(instances, options.MaxDepth)
+
+	return model, nil
+}
+
+// validateWeights checks that every weight in weightColumn is a finite,
+// non-negative number. Missing weights count as 1.
+func validateWeights(instances []t.Instance, weightColumn string) error {
+	for i, instance := range instances {
+		value := instance[weightColumn]
+		if value == nil {
+			continue
+		}
+		weight, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("row %d: weight %v in column '%s' is not a number", i+1, value, weightColumn)
+		}
+		if math.IsNaN(weight) || math.IsInf(weight, 0) || weight < 0 {
+			return fmt.Errorf("row %d: weight %v in column '%s' must be finite and not negative", i+1, weight, weightColumn)
+		}
+	}
+	return nil
+}
+
+// newTreeContext validates the training inputs and prepares the context shared
+// by every tree grown from them. It also returns the resolved task.
+func newTreeContext(instances []t.Instance, headers []string, targetFeature string, featureTypes map[string]string, excludeColumns []string, options TrainOptions) (TreeContext, string, error) {
 	// Validate inputs
 	if len(instances) == 0 {
-		return nil, fmt.Errorf("no instances provided for training")
+		return TreeContext{}, "", fmt.Errorf("no instances provided for training")
 	}
 	if _, ok := featureTypes[targetFeature]; !ok {
-		return nil, fmt.Errorf("target feature '%s' not found in feature types", targetFeature)
+		return TreeContext{}, "", fmt.Errorf("target feature '%s' not found in feature types", targetFeature)
 	}
 
 	// Create a map of excluded features for faster lookup
@@ -23,10 +135,34 @@ func Train(instances []t.Instance, headers []string, targetFeature string, featu
 		excludedFeatures[feature] = true
 	}
 
-	// Remove target feature from the list of features used for splitting
+	task, err := ResolveTask(options.Task, instances, targetFeature, featureTypes)
+	if err != nil {
+		return TreeContext{}, "", err
+	}
+	if task == t.Regression && options.Split.Regression == nil {
+		options.Split.Regression = split.VarianceReduction{}
+	}
+	if task == t.Classification {
+		options.Split.Regression = nil
+	}
+
+	if options.WeightColumn != "" {
+		featureType, ok := featureTypes[options.WeightColumn]
+		if !ok {
+			return TreeContext{}, "", fmt.Errorf("weight column '%s' not found in feature types", options.WeightColumn)
+		}
+		if featureType != "numerical" {
+			return TreeContext{}, "", fmt.Errorf("weight column '%s' must be numerical, not %s", options.WeightColumn, featureType)
+		}
+		if err := validateWeights(instances, options.WeightColumn); err != nil {
+			return TreeContext{}, "", err
+		}
+	}
+
+	// Remove target and weight features from the list of features used for splitting
 	features := make([]string, 0, len(headers))
 	for _, feature := range headers {
-		if feature != targetFeature && !excludedFeatures[feature] {
+		if feature != targetFeature && feature != options.WeightColumn && !excludedFeatures[feature] {
 			features = append(features, feature)
 		}
 	}
@@ -35,16 +171,52 @@ func Train(instances []t.Instance, headers []string, targetFeature string, featu
 	cache := cache.NewFeatureCache()
 	cache.PrecomputeFeatureValues(instances, features, targetFeature, featureTypes)
 
-	// Train the decision tree
-	root := C45(instances, features, targetFeature, featureTypes, excludedFeatures, 5, maxDepth, cache) // minInstancesPerLeaf = 5
+	context := TreeContext{
+		Features:            features,
+		TargetFeature:       targetFeature,
+		FeatureTypes:        featureTypes,
+		ExcludedFeatures:    excludedFeatures,
+		MinInstancesPerLeaf: options.MinInstancesPerLeaf,
+		Cache:               cache,
+		SplitOptions:        options.Split,
+		Costs:               options.Costs,
+		LinearLeaves:        options.LinearLeaves,
+	}
+	context.SplitOptions.WeightFeature = options.WeightColumn
+	if task == t.Classification {
+		context.ClassOrder = classFrequencyOrder(instances, targetFeature, options.WeightColumn)
+	}
+
+	return context, task, nil
+}
+
+// classFrequencyOrder returns the classes from most to least frequent in the
+// training data, which breaks ties between classes at the leaves
+func classFrequencyOrder(instances []t.Instance, targetFeature string, weightColumn string) []string {
+	classCounter := counter.NewClassCounter()
+	for _, instance := range instances {
+		classCounter.AddWeighted(fmt.Sprintf("%v", instance[targetFeature]), utils.InstanceWeight(instance, weightColumn))
+	}
+	return classCounter.ByFrequency()
+}
 
-	// Create and return the model
-	model := &t.Model{
-		Root:         root,
+// ResolveSeed returns the seed to draw from, picking one when it is 0
+func ResolveSeed(seed int64) int64 {
+	if seed != 0 {
+		return seed
+	}
+	return time.Now().UnixNano()
+}
+
+// newModel creates a model without trees from the training settings
+func newModel(headers []string, targetFeature string, featureTypes map[string]string, task string, options TrainOptions, classOrder []string) *t.Model {
+	return &t.Model{
 		FeatureTypes: featureTypes,
 		FeatureNames: headers,
 		TargetName:   targetFeature,
+		Task:         task,
+		WeightColumn: options.WeightColumn,
+		Costs:        options.Costs,
+		ClassOrder:   classOrder,
 	}
-
-	return model, nil
 }
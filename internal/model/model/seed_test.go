@@ -0,0 +1,88 @@
+package model
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"testing"
+
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeededEnsemblesAreReproducible(tc *testing.T) {
+	instances := churnInstances(200)
+	headers := []string{"tenure", "calls", "noise", "plan", "label"}
+	featureTypes := map[string]string{"tenure": "numerical", "calls": "numerical", "noise": "numerical", "plan": "categorical", "label": "categorical"}
+
+	trainers := map[string]func(options TrainOptions) (*t.Model, error){
+		"forest": func(options TrainOptions) (*t.Model, error) {
+			return TrainForest(instances, headers, "label", featureTypes, nil, options, ForestOptions{Trees: 8})
+		},
+		"bagging without replacement": func(options TrainOptions) (*t.Model, error) {
+			return TrainBagging(instances, headers, "label", featureTypes, nil, options, ForestOptions{Trees: 8, BagSize: 0.5, WithoutReplacement: true})
+		},
+		"boosting": func(options TrainOptions) (*t.Model, error) {
+			return TrainBoosted(instances, headers, "label", featureTypes, nil, options, BoostOptions{Rounds: 8, MaxDepth: 2, ValidationFraction: 0.25, Patience: 8})
+		},
+	}
+
+	encode := func(train func(TrainOptions) (*t.Model, error), seed int64) string {
+		options := DefaultTrainOptions()
+		options.Seed = seed
+		model, err := train(options)
+		assert.NoError(tc, err)
+		encoded, err := json.Marshal(model)
+		assert.NoError(tc, err)
+		return string(encoded)
+	}
+
+	for name, train := range trainers {
+		tc.Run(name, func(tc *testing.T) {
+			first := encode(train, 7)
+			for i := 0; i < 3; i++ {
+				assert.Equal(tc, first, encode(train, 7))
+			}
+			assert.NotEqual(tc, first, encode(train, 8))
+		})
+	}
+}
+
+func TestSample(tc *testing.T) {
+	instances := make([]t.Instance, 10)
+	for i := range instances {
+		instances[i] = t.Instance{"id": i}
+	}
+	rng := rand.New(rand.NewPCG(3, 0))
+
+	sample, inBag := Sample(instances, 4, false, rng)
+	assert.Len(tc, sample, 4)
+	drawn := 0
+	for i, in := range inBag {
+		if in {
+			assert.Equal(tc, i, sample[drawn]["id"])
+			drawn++
+		}
+	}
+	assert.Equal(tc, 4, drawn)
+
+	sample, _ = Sample(instances, 25, true, rng)
+	assert.Len(tc, sample, 25)
+}
+
+func TestTrainBagging(tc *testing.T) {
+	instances := churnInstances(200)
+	headers := []string{"tenure", "calls", "noise", "plan", "label"}
+	featureTypes := map[string]string{"tenure": "numerical", "calls": "numerical", "noise": "numerical", "plan": "categorical", "label": "categorical"}
+
+	options := DefaultTrainOptions()
+	options.Seed = 1
+	model, err := TrainBagging(instances, headers, "label", featureTypes, nil, options, ForestOptions{Trees: 5, BagSize: 0.6})
+	assert.NoError(tc, err)
+	assert.Equal(tc, t.Bag, model.Ensemble.Method)
+	assert.Equal(tc, int64(1), model.Ensemble.Seed)
+	assert.Zero(tc, model.Ensemble.MaxFeatures)
+	assert.Len(tc, model.Ensemble.Trees, 5)
+
+	_, err = TrainBagging(instances, headers, "label", featureTypes, nil, options, ForestOptions{Trees: 5, BagSize: 2, WithoutReplacement: true})
+	assert.Error(tc, err)
+}
@@ -8,19 +8,26 @@ import (
 	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
 )
 
+// modelDir is the directory models are saved to and loaded from
+const modelDir = "./decision_model"
+
+// ModelPath returns the path of the file a model with the given file name is
+// saved to and loaded from
+func ModelPath(filename string) string {
+	return fmt.Sprintf("%s/%s", modelDir, filename)
+}
+
 // SaveModel saves a model to a file
 func SaveModel(model *t.Model, filename string) error {
 	modelJSON, err := json.MarshalIndent(model, "", "  ")
 	if err != nil {
 		return fmt.Errorf("error marshaling model to JSON: %v", err)
 	}
-	modelDir := "./decision_model"
 	if _, err := os.Stat(modelDir); err != nil {
 		os.MkdirAll(modelDir, 0o755)
 	}
 
-	filePath := fmt.Sprintf("%s/%s", modelDir, filename)
-	err = os.WriteFile(filePath, modelJSON, 0o644)
+	err = os.WriteFile(ModelPath(filename), modelJSON, 0o644)
 	if err != nil {
 		return fmt.Errorf("error writing model to file: %v", err)
 	}
@@ -30,9 +37,7 @@ func SaveModel(model *t.Model, filename string) error {
 
 // LoadModel loads a model from a file
 func LoadModel(filename string) (*t.Model, error) {
-	modelDir := "./decision_model"
-	filePath := fmt.Sprintf("%s/%s", modelDir, filename)
-	modelJSON, err := os.ReadFile(filePath)
+	modelJSON, err := os.ReadFile(ModelPath(filename))
 	if err != nil {
 		return nil, fmt.Errorf("error reading model from file: %v", err)
 	}
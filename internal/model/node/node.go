@@ -18,25 +18,17 @@ func GetMajorityClass(instances []t.Instance, targetFeature string) string {
 	return counter.GetMajorityClass()
 }
 
-// GetMajorityClassFromNode gets the majority class from a node's children
-func GetMajorityClassFromNode(node *t.Node) string {
-	classCounts := make(map[string]int)
+// GetMajorityClassFromNode gets the majority class from a node's children.
+// Ties go to the class listed first in order, the order leaves were given
+// their class in, then to the alphabetically first class.
+func GetMajorityClassFromNode(node *t.Node, order []string) string {
+	counter := counter.NewClassCounter()
 
 	for _, child := range node.Children {
 		if child.IsLeaf {
-			classCounts[child.Class]++
-		}
-	}
-
-	majorityClass := ""
-	maxCount := 0
-
-	for class, count := range classCounts {
-		if count > maxCount {
-			maxCount = count
-			majorityClass = class
+			counter.Add(child.Class)
 		}
 	}
 
-	return majorityClass
+	return counter.GetMajorityClassInOrder(order)
 }
@@ -102,7 +102,8 @@ func TestGetMajorityClass(t *testing.T) {
 
 func TestGetMajorityClassFromNode(t *testing.T) {
 	type args struct {
-		node *test.Node
+		node  *test.Node
+		order []string
 	}
 	tests := []struct {
 		name string
@@ -133,6 +134,31 @@ func TestGetMajorityClassFromNode(t *testing.T) {
 			},
 			want: "A",
 		},
+		{
+			name: "Tie between leaf classes",
+			args: args{
+				node: &test.Node{
+					Children: []*test.Node{
+						{IsLeaf: true, Class: "B"},
+						{IsLeaf: true, Class: "A"},
+					},
+				},
+			},
+			want: "A",
+		},
+		{
+			name: "Tie broken by the training class order",
+			args: args{
+				node: &test.Node{
+					Children: []*test.Node{
+						{IsLeaf: true, Class: "A"},
+						{IsLeaf: true, Class: "B"},
+					},
+				},
+				order: []string{"B", "A"},
+			},
+			want: "B",
+		},
 		{
 			name: "No leaf nodes",
 			args: args{
@@ -155,7 +181,7 @@ func TestGetMajorityClassFromNode(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := GetMajorityClassFromNode(tt.args.node); got != tt.want {
+			if got := GetMajorityClassFromNode(tt.args.node, tt.args.order); got != tt.want {
 				t.Errorf("GetMajorityClassFromNode() = %v, want %v", got, tt.want)
 			}
 		})
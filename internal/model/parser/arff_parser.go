@@ -0,0 +1,35 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/arff"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+)
+
+// ARFFParser parses a Weka ARFF file. Feature types are taken from the
+// attribute declarations rather than inferred from the values. Instances
+// without a value for targetColumn are dropped, unless it is empty. A
+// targetColumn the file does not declare is an error.
+func ARFFParser(file string, targetColumn string) ([]t.Instance, []string, map[string]string, error) {
+	header, instances, err := arff.Load(file)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if targetColumn != "" && !slices.Contains(header.Headers(), targetColumn) {
+		return nil, nil, nil, fmt.Errorf("target '%s' not in the attributes of %s", targetColumn, file)
+	}
+
+	if targetColumn != "" {
+		labelled := instances[:0]
+		for _, instance := range instances {
+			if instance[targetColumn] != nil {
+				labelled = append(labelled, instance)
+			}
+		}
+		instances = labelled
+	}
+
+	return instances, header.Headers(), header.FeatureTypes(), nil
+}
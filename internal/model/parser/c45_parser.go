@@ -0,0 +1,48 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/c45"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/nyunja/c4.5-decision-tree/internal/source"
+)
+
+// C45Parser parses a dataset in Quinlan's C4.5 format: the .names file next
+// to file declares the attributes, and the cases are read from file, or from
+// the .data file when file is the .names file. Every case is loaded, so
+// results compare with published C4.5 runs. Cases without a value for
+// targetColumn are dropped, as C4.5 cannot learn from them, unless it is empty.
+// A targetColumn the .names file does not declare is an error.
+func C45Parser(file string, targetColumn string) ([]t.Instance, []string, map[string]string, error) {
+	if source.IsStdin(file) {
+		return nil, nil, nil, fmt.Errorf("C4.5 datasets are read from files, next to their .names file")
+	}
+
+	names := c45.NamesFile(file)
+	schema, err := c45.ReadNames(names)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if targetColumn != "" && !slices.Contains(schema.Headers(), targetColumn) {
+		return nil, nil, nil, fmt.Errorf("target '%s' not in .names file %s", targetColumn, names)
+	}
+
+	instances, err := c45.LoadData(c45.DataFile(file), schema)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if targetColumn != "" {
+		labelled := instances[:0]
+		for _, instance := range instances {
+			if instance[targetColumn] != nil {
+				labelled = append(labelled, instance)
+			}
+		}
+		instances = labelled
+	}
+
+	return instances, schema.Headers(), schema.FeatureTypes(), nil
+}
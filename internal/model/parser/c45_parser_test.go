@@ -0,0 +1,26 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestC45Parser(tt *testing.T) {
+	dir := tt.TempDir()
+	names := filepath.Join(dir, "golf.names")
+	require.NoError(tt, os.WriteFile(names, []byte("Play, Don't Play.\n\noutlook: sunny, rain.\nhumidity: continuous.\n"), 0o644))
+	require.NoError(tt, os.WriteFile(filepath.Join(dir, "golf.data"), []byte("sunny, 85, Don't Play\nrain, 70, ?\n"), 0o644))
+
+	instances, headers, _, err := C45Parser(names, "class")
+	require.NoError(tt, err)
+	assert.Equal(tt, []string{"outlook", "humidity", "class"}, headers)
+	require.Len(tt, instances, 1)
+	assert.Equal(tt, "Don't Play", instances[0]["class"])
+
+	_, _, _, err = C45Parser(names, "play")
+	assert.ErrorContains(tt, err, "target 'play' not in .names")
+}
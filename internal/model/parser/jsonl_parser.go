@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"io"
+	"math/rand/v2"
+
+	tcsv "github.com/nyunja/c4.5-decision-tree/internal/csv"
+	"github.com/nyunja/c4.5-decision-tree/internal/jsonl"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/nyunja/c4.5-decision-tree/internal/source"
+)
+
+// StreamingJSONLParser parses a JSON Lines file in the two passes CSV files
+// go through. Rows of very large files are sampled reproducibly from the
+// seed, or randomly when it is 0. Files read in a single pass take their
+// keys and column types from the first objects and are not sampled.
+func StreamingJSONLParser(file string, targetColumn string, options Options) ([]t.Instance, []string, map[string]string, error) {
+	f, records, headers, featureTypes, rowCount, err := collectJSONL(file, options)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer f.Close()
+
+	// Second pass: read and convert data
+	var sampler *rand.Rand
+	if options.Seed != 0 {
+		sampler = rand.New(rand.NewPCG(uint64(options.Seed), 0))
+	}
+	instances, err := tcsv.LoadRecordInstances(records, headers, featureTypes, targetColumn, rowCount, options.ChunkSize, sampler, tcsv.NewCoercions(options.Strict))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return instances, headers, featureTypes, nil
+}
+
+// PredictionJSONLParser parses a JSON Lines file for prediction (target column may not exist)
+func PredictionJSONLParser(file string, targetColumn string, options Options) ([]t.Instance, []string, map[string]string, error) {
+	f, records, headers, featureTypes, rowCount, err := collectJSONL(file, options)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer f.Close()
+
+	// Second pass: read and convert data
+	instances, err := tcsv.LoadPredictionRecordInstances(records, headers, featureTypes, targetColumn, rowCount, options.ChunkSize, tcsv.NewCoercions(options.Strict))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return instances, headers, featureTypes, nil
+}
+
+// collectJSONL reads the keys of a JSON Lines file and performs the first
+// pass to determine the column types. It returns the open file with a
+// reader of its records for the second pass: the file read again, or the
+// rest of it after the first objects when it is read in a single pass.
+func collectJSONL(file string, options Options) (io.Closer, tcsv.RecordReader, []string, map[string]string, int, error) {
+	f, err := source.Open(file)
+	if err != nil {
+		return nil, nil, nil, nil, 0, err
+	}
+
+	if options.singlePass(file) {
+		headers, reader, err := jsonl.ReadHead(f, tcsv.SampleSize)
+		if err != nil {
+			f.Close()
+			return nil, nil, nil, nil, 0, err
+		}
+		stats, records, err := tcsv.CollectHeadStatistics(options.Schema.Records(reader, headers), headers)
+		if err != nil {
+			f.Close()
+			return nil, nil, nil, nil, 0, err
+		}
+		return f, records, headers, options.Schema.FeatureTypes(tcsv.DetermineColumnTypes(stats)), stats.RowCount, nil
+	}
+
+	headers, err := jsonl.ReadHeaders(f)
+	f.Close()
+	if err != nil {
+		return nil, nil, nil, nil, 0, err
+	}
+
+	// First pass: collect statistics about the data
+	if f, err = source.Open(file); err != nil {
+		return nil, nil, nil, nil, 0, err
+	}
+	stats, err := tcsv.CollectRecordStatistics(options.Schema.Records(jsonl.NewReader(f, headers), headers), headers)
+	f.Close()
+	if err != nil {
+		return nil, nil, nil, nil, 0, err
+	}
+
+	if f, err = source.Open(file); err != nil {
+		return nil, nil, nil, nil, 0, err
+	}
+	records := options.Schema.Records(jsonl.NewReader(f, headers), headers)
+	return f, records, headers, options.Schema.FeatureTypes(tcsv.DetermineColumnTypes(stats)), stats.RowCount, nil
+}
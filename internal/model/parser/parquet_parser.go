@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
+	"github.com/nyunja/c4.5-decision-tree/internal/parquet"
+)
+
+// ParquetParser parses a Parquet file with the feature types of its schema.
+// Only the named columns are read, or every column when none are named.
+// Row groups are read one at a time, rows of very large files are sampled
+// as CSV rows are, reproducibly from seed or randomly when it is 0, and
+// reading stops after chunkSize instances. Instances without a value for
+// targetColumn are dropped, unless it is empty.
+func ParquetParser(file string, columns []string, chunkSize int, targetColumn string, seed int64) ([]t.Instance, []string, map[string]string, error) {
+	reader, err := parquet.Open(file)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer reader.Close()
+
+	if len(columns) > 0 {
+		reader.Select(columns)
+	}
+
+	// Determine if we should use sampling for very large datasets
+	totalRows := int(reader.NumRows())
+	useSampling := totalRows > 100000
+	samplingRate := 1.0
+	if useSampling {
+		samplingRate = float64(100000) / float64(totalRows)
+		fmt.Printf("Using sampling rate of %.2f%% for large dataset (%d rows)\n", samplingRate*100, totalRows)
+	}
+
+	draw := rand.Float64
+	if seed != 0 {
+		draw = rand.New(rand.NewPCG(uint64(seed), 0)).Float64
+	}
+
+	instances := make([]t.Instance, 0, utils.Min(totalRows, 100000))
+	rowCount := 0
+	err = reader.ReadRowGroups(func(rows []t.Instance) error {
+		for _, instance := range rows {
+			rowCount++
+			if useSampling && draw() > samplingRate {
+				continue
+			}
+			if targetColumn == "" || instance[targetColumn] != nil {
+				instances = append(instances, instance)
+			}
+			if len(instances) >= chunkSize {
+				return parquet.ErrStop
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	fmt.Printf("Loaded %d instances from %d rows\n", len(instances), rowCount)
+	if len(reader.Skipped) > 0 {
+		fmt.Print(skippedColumns(reader.Skipped))
+	}
+	return instances, reader.Headers(), reader.FeatureTypes(), nil
+}
+
+// skippedColumns formats the columns a Parquet file holds but that cannot
+// be read as features, one line per column as the conversion report of CSV
+// files is
+func skippedColumns(skipped map[string]string) string {
+	columns := make([]string, 0, len(skipped))
+	for column := range skipped {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	var report strings.Builder
+	fmt.Fprintf(&report, "%d columns cannot be read as features and were skipped:\n", len(columns))
+	for _, column := range columns {
+		fmt.Fprintf(&report, "  %s: %s\n", column, skipped[column])
+	}
+	return report.String()
+}
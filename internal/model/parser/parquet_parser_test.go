@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	pq "github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type visit struct {
+	Spend *float64 `parquet:"spend,optional"`
+	Tags  []string `parquet:"tags,list"`
+	Churn *string  `parquet:"churn,optional"`
+}
+
+func writeVisits(tt *testing.T, visits []visit) string {
+	file := filepath.Join(tt.TempDir(), "visits.parquet")
+	f, err := os.Create(file)
+	require.NoError(tt, err)
+	defer f.Close()
+
+	writer := pq.NewGenericWriter[visit](f)
+	_, err = writer.Write(visits)
+	require.NoError(tt, err)
+	require.NoError(tt, writer.Close())
+	return file
+}
+
+func ptr[V any](v V) *V { return &v }
+
+func TestParquetParser(tt *testing.T) {
+	file := writeVisits(tt, []visit{
+		{Spend: ptr(12.5), Tags: []string{"a"}, Churn: ptr("no")},
+		{Spend: ptr(3.0)},
+		{Churn: ptr("yes")},
+	})
+
+	instances, headers, featureTypes, err := ParquetParser(file, nil, 100, "churn", 1)
+	require.NoError(tt, err)
+	assert.Equal(tt, []string{"spend", "churn"}, headers)
+	assert.Equal(tt, map[string]string{"spend": "numerical", "churn": "categorical"}, featureTypes)
+	require.Len(tt, instances, 2, "the row without a churn value is dropped")
+	assert.Equal(tt, "no", instances[0]["churn"])
+	assert.Equal(tt, "yes", instances[1]["churn"])
+	assert.Nil(tt, instances[1]["spend"])
+
+	instances, _, _, err = ParquetParser(file, []string{"spend"}, 100, "", 0)
+	require.NoError(tt, err)
+	assert.Len(tt, instances, 3, "every row is kept without a target")
+}
+
+func TestSkippedColumns(tt *testing.T) {
+	report := skippedColumns(map[string]string{"tags": "repeated values", "blob": "unsupported type"})
+	assert.Equal(tt, "2 columns cannot be read as features and were skipped:\n  blob: unsupported type\n  tags: repeated values\n", report)
+}
@@ -0,0 +1,158 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/arff"
+	"github.com/nyunja/c4.5-decision-tree/internal/c45"
+	tcsv "github.com/nyunja/c4.5-decision-tree/internal/csv"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/nyunja/c4.5-decision-tree/internal/schema"
+	"github.com/nyunja/c4.5-decision-tree/internal/source"
+)
+
+// File formats recognised by their extension
+const (
+	formatCSV     = "csv"
+	formatC45     = "c4.5"
+	formatARFF    = "arff"
+	formatJSONL   = "jsonl"
+	formatParquet = "parquet"
+)
+
+// Formats lists the formats Options.Format accepts
+var Formats = []string{formatCSV, formatC45, formatARFF, formatJSONL, formatParquet}
+
+// Options control how input files are read
+type Options struct {
+	Dialect   tcsv.Dialect // dialect of CSV files
+	ChunkSize int          // most instances loaded
+	Seed      int64        // seed of the row sampling of very large files, random when 0
+	// Format overrides the format named by the extension, which standard
+	// input does not have
+	Format string
+	// SinglePass reads the input once, inferring column types from the
+	// first rows, as standard input always is
+	SinglePass bool
+	// Schema declares the types and values of CSV and JSON Lines columns
+	// in place of the inferred ones, when set
+	Schema *schema.Schema
+	// Strict fails on CSV and JSON Lines values that do not convert to the
+	// type of their column, rather than loading them as missing
+	Strict bool
+}
+
+// DefaultOptions reads comma separated CSV files in two passes
+func DefaultOptions() Options {
+	return Options{Dialect: tcsv.DefaultDialect(), ChunkSize: 10000}
+}
+
+// singlePass reports whether file is read in a single pass
+func (o Options) singlePass(file string) bool {
+	return o.SinglePass || source.IsStdin(file)
+}
+
+// format returns the format file is read in
+func (o Options) format(file string) string {
+	if o.Format != "" {
+		return o.Format
+	}
+	return fileFormat(file)
+}
+
+// fileFormat returns the format of file from its extension, CSV by default.
+// The extension of a compression format is ignored, so data.jsonl.gz is
+// JSON Lines.
+func fileFormat(file string) string {
+	switch strings.ToLower(filepath.Ext(source.BaseName(file))) {
+	case ".names", ".data", ".test":
+		return formatC45
+	case ".arff":
+		return formatARFF
+	case ".jsonl", ".ndjson":
+		return formatJSONL
+	case ".parquet":
+		return formatParquet
+	}
+	return formatCSV
+}
+
+// ParseTrainingFile parses a training file in the format its extension
+// names, CSV by default. The file may be compressed, or "-" for standard
+// input.
+func ParseTrainingFile(file string, targetColumn string, options Options) ([]t.Instance, []string, map[string]string, error) {
+	switch options.format(file) {
+	case formatC45:
+		return C45Parser(file, targetColumn)
+	case formatARFF:
+		return ARFFParser(file, targetColumn)
+	case formatJSONL:
+		return StreamingJSONLParser(file, targetColumn, options)
+	case formatParquet:
+		return ParquetParser(file, nil, options.ChunkSize, targetColumn, options.Seed)
+	case formatCSV:
+		return StreamingCSVParser(file, targetColumn, options)
+	}
+	return nil, nil, nil, fmt.Errorf("unknown input format '%s'", options.Format)
+}
+
+// ParsePredictionFile parses a file a model predicts in the format its
+// extension names, CSV by default. The columns the model knows are read
+// with the types it was trained on rather than those inferred from the
+// file, and the values read as missing when training are missing again.
+// Formats stored by column, such as Parquet, only read the model's features.
+func ParsePredictionFile(file string, model *t.Model, options Options) ([]t.Instance, []string, map[string]string, error) {
+	options.Schema = schema.ForModel(model, options.Schema)
+	return parseFile(file, model.TargetName, model.FeatureNames, options)
+}
+
+// ParseFile parses a file whose target column may be missing, such as one
+// a schema template is inferred from, in the format its extension names,
+// CSV by default
+func ParseFile(file string, targetColumn string, options Options) ([]t.Instance, []string, map[string]string, error) {
+	return parseFile(file, targetColumn, nil, options)
+}
+
+// parseFile parses a file in the format its extension names, keeping rows
+// without a target. Formats stored by column only read the columns named,
+// or every column when none are.
+func parseFile(file string, targetColumn string, columns []string, options Options) ([]t.Instance, []string, map[string]string, error) {
+	switch options.format(file) {
+	case formatC45:
+		return C45Parser(file, "")
+	case formatARFF:
+		return ARFFParser(file, "")
+	case formatJSONL:
+		return PredictionJSONLParser(file, targetColumn, options)
+	case formatParquet:
+		return ParquetParser(file, columns, options.ChunkSize, "", 0)
+	case formatCSV:
+		return PredictionCSVParser(file, targetColumn, options)
+	}
+	return nil, nil, nil, fmt.Errorf("unknown input format '%s'", options.Format)
+}
+
+// DefaultTarget returns the target a file declares: the class of C4.5
+// datasets or the last attribute of ARFF files. It is empty for formats
+// that do not declare one, and for standard input, which cannot be read
+// twice.
+func DefaultTarget(file string) string {
+	if source.IsStdin(file) {
+		return ""
+	}
+	switch fileFormat(file) {
+	case formatC45:
+		schema, err := c45.ReadNames(c45.NamesFile(file))
+		if err == nil {
+			return schema.Target
+		}
+	case formatARFF:
+		header, err := arff.LoadHeader(file)
+		if err == nil {
+			return header.Target()
+		}
+	}
+	return ""
+}
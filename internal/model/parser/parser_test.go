@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	m "github.com/nyunja/c4.5-decision-tree/internal/model/model"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/predict"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/nyunja/c4.5-decision-tree/internal/schema"
+)
+
+func writeFile(tt *testing.T, name, content string) string {
+	file := filepath.Join(tt.TempDir(), name)
+	require.NoError(tt, os.WriteFile(file, []byte(content), 0o644))
+	return file
+}
+
+func TestParsePredictionFile_ModelTypes(tt *testing.T) {
+	train := "zip,visits,bought\n"
+	for i := 0; i < 6; i++ {
+		train += "02134,3,yes\n10001,3,no\n"
+	}
+	options := DefaultOptions()
+	options.Schema = &schema.Schema{Missing: []string{"NA"}, Columns: []schema.Column{{Name: "zip", Type: "categorical"}}}
+	instances, headers, featureTypes, err := ParseTrainingFile(writeFile(tt, "train.csv", train), "bought", options)
+	require.NoError(tt, err)
+	require.Equal(tt, "02134", instances[0]["zip"])
+
+	trained, err := m.TrainWithOptions(instances, headers, "bought", featureTypes, nil, m.DefaultTrainOptions())
+	require.NoError(tt, err)
+	trained.Reading = options.Schema.Reading()
+
+	// The model is read back as it is saved
+	encoded, err := json.Marshal(trained)
+	require.NoError(tt, err)
+	model := &t.Model{}
+	require.NoError(tt, json.Unmarshal(encoded, model))
+
+	// Without the schema, the codes would be inferred as numbers and NA
+	// as a category
+	file := writeFile(tt, "predict.csv", "zip,visits\n02134,3\n10001,4\nNA,3\n")
+	instances, _, featureTypes, err = ParsePredictionFile(file, model, DefaultOptions())
+	require.NoError(tt, err)
+	assert.Equal(tt, "categorical", featureTypes["zip"])
+	assert.Equal(tt, "02134", instances[0]["zip"])
+	assert.Nil(tt, instances[2]["zip"])
+	assert.Equal(tt, []string{"yes", "no"}, predict.BatchPredict(model, instances)[:2])
+}
+
+func TestParsePredictionFile_Strict(tt *testing.T) {
+	model := &t.Model{
+		FeatureTypes: map[string]string{"income": "numerical", "label": "categorical"},
+		FeatureNames: []string{"income"},
+		TargetName:   "label",
+	}
+	// Read on its own, the file would take income for a categorical column
+	file := writeFile(tt, "predict.csv", "income\n52000\nabc\n")
+
+	options := DefaultOptions()
+	options.Strict = true
+	_, _, _, err := ParsePredictionFile(file, model, options)
+	require.Error(tt, err)
+	assert.Contains(tt, err.Error(), "income")
+	assert.Contains(tt, err.Error(), "abc")
+
+	// Without --strict the value is loaded as missing
+	instances, _, _, err := ParsePredictionFile(file, model, DefaultOptions())
+	require.NoError(tt, err)
+	assert.Equal(tt, 52000.0, instances[0]["income"])
+	assert.Nil(tt, instances[1]["income"])
+}
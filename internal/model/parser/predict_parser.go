@@ -1,39 +1,81 @@
 package parser
 
 import (
+	"io"
+
 	tcsv "github.com/nyunja/c4.5-decision-tree/internal/csv"
 	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
 )
 
 // PredictionCSVParser efficiently parses a CSV file for prediction (target column may not exist)
-func PredictionCSVParser(file string, hasHeader bool, chunkSize int, targetColumn string) ([]t.Instance, []string, map[string]string, error) {
+func PredictionCSVParser(file string, targetColumn string, options Options) ([]t.Instance, []string, map[string]string, error) {
 	// Open file and create CSV reader
-	f, csvReader, err := tcsv.OpenCSVFile(file)
+	f, csvReader, err := tcsv.OpenCSVFile(file, options.Dialect)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 	defer f.Close()
 
 	// Read headers
-	headers, err := tcsv.ReadCSVHeaders(csvReader, hasHeader)
+	headers, err := tcsv.ReadCSVHeaders(csvReader, options.Dialect)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
+	if options.singlePass(file) {
+		stats, records, err := tcsv.CollectHeadStatistics(options.Schema.Records(csvReader, headers), headers)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		featureTypes := options.Schema.FeatureTypes(tcsv.DetermineColumnTypes(stats))
+		instances, err := tcsv.LoadPredictionRecordInstances(records, headers, featureTypes, targetColumn, stats.RowCount, options.ChunkSize, tcsv.NewCoercions(options.Strict))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return instances, headers, featureTypes, nil
+	}
+
 	// First pass: collect statistics about the data
-	stats, err := tcsv.CollectDatasetStatistics(f, headers, hasHeader)
+	stats, err := collectCSV(file, headers, options)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
 	// Determine column types and ID columns
-	featureTypes := tcsv.DetermineColumnTypes(stats)
+	featureTypes := options.Schema.FeatureTypes(tcsv.DetermineColumnTypes(stats))
 
 	// Second pass: read and convert data
-	instances, err := tcsv.LoadPredictionInstances(file, headers, featureTypes, targetColumn, stats.RowCount, chunkSize, hasHeader)
+	second, records, err := openCSVRecords(file, headers, options)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer second.Close()
+	instances, err := tcsv.LoadPredictionRecordInstances(records, headers, featureTypes, targetColumn, stats.RowCount, options.ChunkSize, tcsv.NewCoercions(options.Strict))
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
 	return instances, headers, featureTypes, nil
 }
+
+// collectCSV performs the first pass through a CSV file, with the values
+// the schema declares applied
+func collectCSV(file string, headers []string, options Options) (*t.DatasetStats, error) {
+	f, records, err := openCSVRecords(file, headers, options)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return tcsv.CollectRecordStatistics(records, headers)
+}
+
+// openCSVRecords opens a CSV file for a pass through its rows, with the
+// values the schema declares applied
+func openCSVRecords(file string, headers []string, options Options) (io.Closer, tcsv.RecordReader, error) {
+	f, csvReader, err := tcsv.OpenCSVRecords(file, options.Dialect)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, options.Schema.Records(csvReader, headers), nil
+}
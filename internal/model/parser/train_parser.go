@@ -1,38 +1,63 @@
 package parser
 
 import (
+	"math/rand/v2"
+
 	tcsv "github.com/nyunja/c4.5-decision-tree/internal/csv"
 	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
 )
 
-// StreamingCSVParser efficiently parses a CSV file in chunks
-func StreamingCSVParser(file string, hasHeader bool, chunkSize int, targetColumn string) ([]t.Instance, []string, map[string]string, error) {
+// StreamingCSVParser efficiently parses a CSV file in chunks. Rows of very
+// large files are sampled reproducibly from the seed, or randomly when it
+// is 0. Files read in a single pass take their column types from the first
+// rows and are not sampled.
+func StreamingCSVParser(file string, targetColumn string, options Options) ([]t.Instance, []string, map[string]string, error) {
 	// Open file and create CSV reader
-	f, csvReader, err := tcsv.OpenCSVFile(file)
+	f, csvReader, err := tcsv.OpenCSVFile(file, options.Dialect)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 	defer f.Close()
 
 	// Read headers
-	headers, err := tcsv.ReadCSVHeaders(csvReader, hasHeader)
+	headers, err := tcsv.ReadCSVHeaders(csvReader, options.Dialect)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
+	if options.singlePass(file) {
+		stats, records, err := tcsv.CollectHeadStatistics(options.Schema.Records(csvReader, headers), headers)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		featureTypes := options.Schema.FeatureTypes(tcsv.DetermineColumnTypes(stats))
+		instances, err := tcsv.LoadRecordInstances(records, headers, featureTypes, targetColumn, stats.RowCount, options.ChunkSize, nil, tcsv.NewCoercions(options.Strict))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return instances, headers, featureTypes, nil
+	}
+
 	// First pass: collect statistics about the data
-	stats, err := tcsv.CollectDatasetStatistics(f, headers, hasHeader)
+	stats, err := collectCSV(file, headers, options)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
-	// Determine column types and ID columns
-	featureTypes := tcsv.DetermineColumnTypes(stats)
-	// idColumns := utils.DetectIDColumns(stats, headers)
-	// fmt.Printf("Detected ID columns: %v\n", idColumns)
+	// Determine column types. ID columns are detected by --auto-exclude
+	featureTypes := options.Schema.FeatureTypes(tcsv.DetermineColumnTypes(stats))
 
 	// Second pass: read and convert data
-	instances, err := tcsv.LoadInstances(file, headers, featureTypes, targetColumn, stats.RowCount, chunkSize, hasHeader)
+	var sampler *rand.Rand
+	if options.Seed != 0 {
+		sampler = rand.New(rand.NewPCG(uint64(options.Seed), 0))
+	}
+	second, records, err := openCSVRecords(file, headers, options)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer second.Close()
+	instances, err := tcsv.LoadRecordInstances(records, headers, featureTypes, targetColumn, stats.RowCount, options.ChunkSize, sampler, tcsv.NewCoercions(options.Strict))
 	if err != nil {
 		return nil, nil, nil, err
 	}
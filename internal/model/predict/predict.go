@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"strconv"
 	"sync"
 
 	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
@@ -25,7 +26,7 @@ func BatchPredict(model *t.Model, instances []t.Instance) []string {
 		go func() {
 			defer wg.Done()
 			for idx := range instancesChan {
-				predictions[idx] = PredictClass(model, instances[idx])
+				predictions[idx] = Predict(model, instances[idx])
 			}
 		}()
 	}
@@ -44,8 +45,23 @@ func BatchPredict(model *t.Model, instances []t.Instance) []string {
 	return predictions
 }
 
+// Predict returns the prediction for an instance as it is written to the output:
+// the class, or the formatted value for regression models
+func Predict(model *t.Model, instance t.Instance) string {
+	if model.Task == t.Regression {
+		return strconv.FormatFloat(PredictValue(model, instance), 'g', -1, 64)
+	}
+	return PredictClass(model, instance)
+}
+
 // SavePredictions saves predictions to a CSV file
 func SavePredictions(instances []t.Instance, predictions []string, filename string, headers []string) error {
+	return SaveColumns(filename, []string{"prediction"}, [][]string{predictions[:len(instances)]})
+}
+
+// SaveColumns saves columns of per-instance values, such as predictions and
+// their explanations, to a CSV file
+func SaveColumns(filename string, header []string, columns [][]string) error {
 	file, err := os.Create(filename)
 	if err != nil {
 		return fmt.Errorf("error creating file: %v", err)
@@ -56,16 +72,21 @@ func SavePredictions(instances []t.Instance, predictions []string, filename stri
 	defer writer.Flush()
 
 	// Write the header
-	row := make([]string, 1)
-	row[0] = "prediction"
-	err = writer.Write(row)
+	err = writer.Write(header)
 	if err != nil {
 		return fmt.Errorf("error writing prediction: %v", err)
 	}
 
-	// Write the predictions
-	for i := 0; i < len(instances); i++ { // CAUTION: do not mordernize this for loop to range over an int - other go versions do not support it
-		row[0] = predictions[i]
+	// Write the rows
+	rows := 0
+	if len(columns) > 0 {
+		rows = len(columns[0])
+	}
+	row := make([]string, len(columns))
+	for i := 0; i < rows; i++ { // CAUTION: do not mordernize this for loop to range over an int - other go versions do not support it
+		for j, column := range columns {
+			row[j] = column[i]
+		}
 
 		err = writer.Write(row)
 		if err != nil {
@@ -2,88 +2,257 @@ package predict
 
 import (
 	"fmt"
+	"math"
+	"sort"
 	"strconv"
 	"time"
 
 	ndp "github.com/nyunja/c4.5-decision-tree/internal/model/node"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/split"
 	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
 )
 
 // PredictClass predicts the class of an instance
 func PredictClass(model *t.Model, instance t.Instance) string {
-	node := model.Root
+	if model.Ensemble != nil {
+		votes := Votes(model, model.Ensemble.Trees, model.Ensemble.Weights, instance)
+		if model.Costs != nil && model.Ensemble.Voting == t.ProbabilityVote {
+			return model.Costs.MinExpectedCostClass(votes)
+		}
+		return BestClass(votes)
+	}
+	return TreeClass(model, model.Root, instance)
+}
+
+// TreeClass predicts the class of an instance with one tree of the model
+func TreeClass(model *t.Model, root *t.Node, instance t.Instance) string {
+	node, reachedLeaf := FindLeaf(root, instance)
+
+	// Minimise the expected cost over the training distribution when costs are known
+	if model.Costs != nil && node.ClassCounts != nil {
+		return model.Costs.MinExpectedCostClass(node.ClassCounts)
+	}
+
+	if !reachedLeaf {
+		// Find the most common class among children
+		return ndp.GetMajorityClassFromNode(node, model.ClassOrder)
+	}
+	return node.Class
+}
+
+// PredictProbabilities returns the predicted class distribution of an instance.
+// Forests with majority voting report the share of trees voting for each class.
+func PredictProbabilities(model *t.Model, instance t.Instance) map[string]float64 {
+	if model.Ensemble != nil {
+		return normalise(Votes(model, model.Ensemble.Trees, model.Ensemble.Weights, instance))
+	}
+	return TreeDistribution(model, model.Root, instance)
+}
+
+// TreeDistribution returns the normalised training class distribution of the
+// node an instance reaches in a tree of the model
+func TreeDistribution(model *t.Model, root *t.Node, instance t.Instance) map[string]float64 {
+	node, reachedLeaf := FindLeaf(root, instance)
+	if len(node.ClassCounts) > 0 {
+		return normalise(node.ClassCounts)
+	}
+
+	class := node.Class
+	if !reachedLeaf {
+		class = ndp.GetMajorityClassFromNode(node, model.ClassOrder)
+	}
+	return map[string]float64{class: 1}
+}
+
+// Votes adds up the votes of the trees for each class. With majority voting
+// every tree votes for its class; with probability voting it adds its class
+// distribution. Each vote is scaled by the tree's weight, 1 when weights is nil.
+func Votes(model *t.Model, trees []*t.Node, weights []float64, instance t.Instance) map[string]float64 {
+	probability := model.Ensemble != nil && model.Ensemble.Voting == t.ProbabilityVote
+
+	votes := make(map[string]float64)
+	for i, tree := range trees {
+		weight := 1.0
+		if weights != nil {
+			weight = weights[i]
+		}
+
+		if !probability {
+			votes[TreeClass(model, tree, instance)] += weight
+			continue
+		}
+		for class, p := range TreeDistribution(model, tree, instance) {
+			votes[class] += weight * p
+		}
+	}
+	return votes
+}
+
+// BestClass returns the class with the most votes. Ties go to the
+// alphabetically first class.
+func BestClass(votes map[string]float64) string {
+	classes := sortedKeys(votes)
+
+	bestClass := ""
+	bestVotes := math.Inf(-1)
+	for _, class := range classes {
+		if votes[class] > bestVotes {
+			bestVotes = votes[class]
+			bestClass = class
+		}
+	}
+	return bestClass
+}
+
+// normalise scales a distribution to sum to one
+func normalise(distribution map[string]float64) map[string]float64 {
+	total := 0.0
+	for _, class := range sortedKeys(distribution) {
+		total += distribution[class]
+	}
+
+	normalised := make(map[string]float64, len(distribution))
+	for class, weight := range distribution {
+		if total > 0 {
+			normalised[class] = weight / total
+		}
+	}
+	return normalised
+}
+
+// PredictValue predicts the numeric target of an instance with a regression
+// model. Forests average the predictions of their trees.
+func PredictValue(model *t.Model, instance t.Instance) float64 {
+	if model.Ensemble == nil {
+		return TreeValue(model.Root, instance)
+	}
+
+	sum := 0.0
+	for _, tree := range model.Ensemble.Trees {
+		sum += TreeValue(tree, instance)
+	}
+	if len(model.Ensemble.Trees) == 0 {
+		return 0
+	}
+	return sum / float64(len(model.Ensemble.Trees))
+}
+
+// TreeValue predicts the numeric target of an instance with one regression tree
+func TreeValue(root *t.Node, instance t.Instance) float64 {
+	node, reachedLeaf := FindLeaf(root, instance)
+	if !reachedLeaf || node.LinearModel == nil {
+		// Decision nodes keep the mean of the instances that reached them
+		return node.Mean
+	}
+
+	value := node.LinearModel.Intercept
+	for _, feature := range sortedKeys(node.LinearModel.Coefficients) {
+		x, ok := split.ExtractNumericValue(instance[feature])
+		if !ok {
+			return node.Mean
+		}
+		value += node.LinearModel.Coefficients[feature] * x
+	}
+	return value
+}
+
+// sortedKeys returns the keys of a map in sorted order, so that sums over
+// the map do not depend on iteration order
+func sortedKeys(values map[string]float64) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// FindLeaf routes an instance down the tree. It returns the leaf reached, or
+// the decision node where the instance could not be routed any further
+// (missing value, unconvertible number or unknown category) and false.
+func FindLeaf(root *t.Node, instance t.Instance) (*t.Node, bool) {
+	node := root
 	for !node.IsLeaf {
-		feature := node.Feature
-		val, ok := instance[feature]
-		if !ok || val == nil {
-			// Handle missing value
-			// Find the most common class among children
-			classCounts := make(map[string]int)
-			for _, child := range node.Children {
-				if child.IsLeaf {
-					classCounts[child.Class]++
-				}
-			}
-			majorityClass := ""
-			maxCount := 0
-			for class, count := range classCounts {
-				if count > maxCount {
-					maxCount = count
-					majorityClass = class
-				}
-			}
-			return majorityClass
+		child := NextNode(node, instance)
+		if child == nil {
+			return node, false
 		}
+		node = child
+	}
+	return node, true
+}
 
-		if node.Continuous {
-			var floatVal float64
-			switch v := val.(type) {
-			case float64:
-				floatVal = v
-			case int:
-				floatVal = float64(v)
-			case time.Time:
-				floatVal = float64(v.Unix())
-			default:
-				strVal := fmt.Sprintf("%v", val)
-				parsedVal, err := strconv.ParseFloat(strVal, 64)
-				if err != nil {
-					// Can't convert to float, use majority class
-					return ndp.GetMajorityClassFromNode(node)
-				}
-				floatVal = parsedVal
-			}
+// NextNode returns the child of a decision node the instance belongs to, or
+// nil when the instance cannot be routed
+func NextNode(node *t.Node, instance t.Instance) *t.Node {
+	child, _ := Route(node, instance)
+	return child
+}
 
-			if floatVal <= node.Threshold {
-				node = node.Children[0] // Left child
-			} else {
-				node = node.Children[1] // Right child
-			}
-		} else {
+// Fallback reasons reported by Route
+const (
+	// FallbackMissing means the value is missing or not a number, so the
+	// instance stops at the decision node
+	FallbackMissing = "missing"
+	// FallbackUnknown means the value was not seen in training, so the
+	// instance follows the node's fallback branch
+	FallbackUnknown = "unknown"
+)
+
+// Route returns the child of a decision node the instance belongs to and
+// why a fallback was used, if any. The child is nil when the instance
+// cannot be routed.
+func Route(node *t.Node, instance t.Instance) (*t.Node, string) {
+	feature := node.Feature
+	val, ok := instance[feature]
+	if !ok || val == nil {
+		// Handle missing value
+		return nil, FallbackMissing
+	}
+
+	if node.Continuous {
+		var floatVal float64
+		switch v := val.(type) {
+		case float64:
+			floatVal = v
+		case int:
+			floatVal = float64(v)
+		case time.Time:
+			floatVal = float64(v.Unix())
+		default:
 			strVal := fmt.Sprintf("%v", val)
-			found := false
-			for _, child := range node.Children {
-				childVal := fmt.Sprintf("%v", child.Value)
-				if childVal == strVal {
-					node = child
-					found = true
-					break
-				}
-			}
-			if !found {
-				// If value not found in any child, use the "unknown" branch or majority class
-				for _, child := range node.Children {
-					if fmt.Sprintf("%v", child.Value) == "unknown" {
-						node = child
-						found = true
-						break
-					}
-				}
-				if !found {
-					return ndp.GetMajorityClassFromNode(node)
-				}
+			parsedVal, err := strconv.ParseFloat(strVal, 64)
+			if err != nil {
+				// Can't convert to float
+				return nil, FallbackMissing
 			}
+			floatVal = parsedVal
+		}
+
+		if floatVal <= node.Threshold {
+			return node.Children[0], "" // Left child
 		}
+		return node.Children[1], "" // Right child
 	}
-	return node.Class
+
+	strVal := fmt.Sprintf("%v", val)
+	for _, child := range node.Children {
+		childVal := fmt.Sprintf("%v", child.Value)
+		if childVal == strVal || utils.Contains(child.Values, strVal) {
+			return child, ""
+		}
+	}
+	if node.Binary {
+		// Anything outside the subset belongs to the second child
+		return node.Children[1], FallbackUnknown
+	}
+
+	// If value not found in any child, use the "unknown" branch
+	for _, child := range node.Children {
+		if fmt.Sprintf("%v", child.Value) == "unknown" {
+			return child, FallbackUnknown
+		}
+	}
+	return nil, FallbackUnknown
 }
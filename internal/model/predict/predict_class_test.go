@@ -1,6 +1,7 @@
 package predict
 
 import (
+	"math"
 	"testing"
 
 	typ "github.com/nyunja/c4.5-decision-tree/internal/model/types"
@@ -25,3 +26,173 @@ func TestPredictClassWithLeafRoot(t *testing.T) {
 		t.Errorf("PredictClass() with leaf root = %v, want 'default'", result)
 	}
 }
+
+// TestPredictClassWithValueGroups tests routing through grouped and binary categorical splits
+func TestPredictClassWithValueGroups(t *testing.T) {
+	model := &typ.Model{
+		Root: &typ.Node{
+			Feature: "colour",
+			Binary:  true,
+			Children: []*typ.Node{
+				{IsLeaf: true, Class: "warm", Values: []string{"red", "orange"}},
+				{IsLeaf: true, Class: "cold", Values: []string{"blue"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		colour string
+		want   string
+	}{
+		{"orange", "warm"},
+		{"blue", "cold"},
+		{"never-seen", "cold"},
+	}
+	for _, tt := range tests {
+		if got := PredictClass(model, typ.Instance{"colour": tt.colour}); got != tt.want {
+			t.Errorf("PredictClass() for %s = %v, want %v", tt.colour, got, tt.want)
+		}
+	}
+}
+
+// TestPredictClassWithMissingValueTie tests that a path ending at a decision
+// node breaks ties between its leaves in the training class order
+func TestPredictClassWithMissingValueTie(t *testing.T) {
+	model := &typ.Model{
+		Root: &typ.Node{
+			Feature: "colour",
+			Children: []*typ.Node{
+				{IsLeaf: true, Class: "no", Value: "blue"},
+				{IsLeaf: true, Class: "yes", Value: "red"},
+			},
+		},
+		ClassOrder: []string{"yes", "no"},
+	}
+
+	if got := PredictClass(model, typ.Instance{}); got != "yes" {
+		t.Errorf("PredictClass() with a missing value = %v, want the first class in order", got)
+	}
+	if got := PredictProbabilities(model, typ.Instance{}); got["yes"] != 1 {
+		t.Errorf("PredictProbabilities() with a missing value = %v, want yes", got)
+	}
+
+	// Models saved without a class order fall back to alphabetical order
+	model.ClassOrder = nil
+	if got := PredictClass(model, typ.Instance{}); got != "no" {
+		t.Errorf("PredictClass() without a class order = %v, want no", got)
+	}
+}
+
+// TestPredictClassWithCosts tests that predictions minimise the expected cost
+func TestPredictClassWithCosts(t *testing.T) {
+	model := &typ.Model{
+		Root: &typ.Node{
+			Feature:     "amount",
+			Continuous:  true,
+			Threshold:   100,
+			ClassCounts: map[string]float64{"legit": 95, "fraud": 5},
+			Children: []*typ.Node{
+				{IsLeaf: true, Class: "legit", ClassCounts: map[string]float64{"legit": 90}},
+				{IsLeaf: true, Class: "legit", ClassCounts: map[string]float64{"legit": 5, "fraud": 5}},
+			},
+		},
+	}
+
+	instance := typ.Instance{"amount": 500.0}
+	if got := PredictClass(model, instance); got != "legit" {
+		t.Errorf("PredictClass() without costs = %v, want legit", got)
+	}
+
+	model.Costs = typ.CostMatrix{"fraud": {"legit": 100}}
+	if got := PredictClass(model, instance); got != "fraud" {
+		t.Errorf("PredictClass() with costs = %v, want fraud", got)
+	}
+	if got := PredictClass(model, typ.Instance{"amount": 50.0}); got != "legit" {
+		t.Errorf("PredictClass() for a pure leaf = %v, want legit", got)
+	}
+
+	// A missing value falls back to the distribution at the decision node
+	if got := PredictClass(model, typ.Instance{}); got != "fraud" {
+		t.Errorf("PredictClass() with missing value = %v, want fraud", got)
+	}
+}
+
+// TestPredictValue tests regression predictions from leaf means and linear models
+func TestPredictValue(t *testing.T) {
+	model := &typ.Model{
+		Task: typ.Regression,
+		Root: &typ.Node{
+			Feature:    "size",
+			Continuous: true,
+			Threshold:  10,
+			Mean:       30,
+			Children: []*typ.Node{
+				{IsLeaf: true, Mean: 12},
+				{IsLeaf: true, Mean: 50, LinearModel: &typ.LinearModel{
+					Intercept:    5,
+					Coefficients: map[string]float64{"size": 3},
+				}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		instance typ.Instance
+		want     float64
+	}{
+		{"leaf mean", typ.Instance{"size": 4.0}, 12},
+		{"linear leaf", typ.Instance{"size": 20.0}, 65},
+		{"missing value uses node mean", typ.Instance{}, 30},
+	}
+	for _, tt := range tests {
+		if got := PredictValue(model, tt.instance); got != tt.want {
+			t.Errorf("PredictValue() for %s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if got := Predict(model, typ.Instance{"size": 4.0}); got != "12" {
+		t.Errorf("Predict() = %v, want 12", got)
+	}
+}
+
+// TestPredictEnsemble tests majority and probability voting of forests
+func TestPredictEnsemble(t *testing.T) {
+	confident := &typ.Node{IsLeaf: true, Class: "yes", ClassCounts: map[string]float64{"yes": 10}}
+	unsure := &typ.Node{IsLeaf: true, Class: "no", ClassCounts: map[string]float64{"no": 6, "yes": 4}}
+	model := &typ.Model{Ensemble: &typ.Ensemble{
+		Method: typ.Forest,
+		Trees:  []*typ.Node{confident, unsure, unsure},
+	}}
+
+	if got := PredictClass(model, typ.Instance{}); got != "no" {
+		t.Errorf("PredictClass() with majority voting = %v, want no", got)
+	}
+
+	model.Ensemble.Voting = typ.ProbabilityVote
+	if got := PredictClass(model, typ.Instance{}); got != "yes" {
+		t.Errorf("PredictClass() with probability voting = %v, want yes", got)
+	}
+	probabilities := PredictProbabilities(model, typ.Instance{})
+	if math.Abs(probabilities["yes"]-0.6) > 1e-12 {
+		t.Errorf("PredictProbabilities() = %v, want yes 0.6", probabilities)
+	}
+
+	// Boosted trees vote with their alpha weights
+	model.Ensemble.Voting = ""
+	model.Ensemble.Weights = []float64{2.5, 1, 1}
+	if got := PredictClass(model, typ.Instance{}); got != "yes" {
+		t.Errorf("PredictClass() with weighted voting = %v, want yes", got)
+	}
+
+	if got := BestClass(map[string]float64{"b": 1, "a": 1}); got != "a" {
+		t.Errorf("BestClass() tie = %v, want a", got)
+	}
+
+	regression := &typ.Model{Task: typ.Regression, Ensemble: &typ.Ensemble{
+		Trees: []*typ.Node{{IsLeaf: true, Mean: 1}, {IsLeaf: true, Mean: 3}},
+	}}
+	if got := PredictValue(regression, typ.Instance{}); got != 2 {
+		t.Errorf("PredictValue() = %v, want 2", got)
+	}
+}
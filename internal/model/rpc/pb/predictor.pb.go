@@ -0,0 +1,689 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: predictor.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type FeatureType int32
+
+const (
+	FeatureType_FEATURE_TYPE_UNSPECIFIED FeatureType = 0
+	FeatureType_FEATURE_TYPE_CATEGORICAL FeatureType = 1
+	FeatureType_FEATURE_TYPE_NUMERICAL   FeatureType = 2
+	FeatureType_FEATURE_TYPE_DATE        FeatureType = 3
+	FeatureType_FEATURE_TYPE_TIMESTAMP   FeatureType = 4
+)
+
+// Enum value maps for FeatureType.
+var (
+	FeatureType_name = map[int32]string{
+		0: "FEATURE_TYPE_UNSPECIFIED",
+		1: "FEATURE_TYPE_CATEGORICAL",
+		2: "FEATURE_TYPE_NUMERICAL",
+		3: "FEATURE_TYPE_DATE",
+		4: "FEATURE_TYPE_TIMESTAMP",
+	}
+	FeatureType_value = map[string]int32{
+		"FEATURE_TYPE_UNSPECIFIED": 0,
+		"FEATURE_TYPE_CATEGORICAL": 1,
+		"FEATURE_TYPE_NUMERICAL":   2,
+		"FEATURE_TYPE_DATE":        3,
+		"FEATURE_TYPE_TIMESTAMP":   4,
+	}
+)
+
+func (x FeatureType) Enum() *FeatureType {
+	p := new(FeatureType)
+	*p = x
+	return p
+}
+
+func (x FeatureType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FeatureType) Descriptor() protoreflect.EnumDescriptor {
+	return file_predictor_proto_enumTypes[0].Descriptor()
+}
+
+func (FeatureType) Type() protoreflect.EnumType {
+	return &file_predictor_proto_enumTypes[0]
+}
+
+func (x FeatureType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FeatureType.Descriptor instead.
+func (FeatureType) EnumDescriptor() ([]byte, []int) {
+	return file_predictor_proto_rawDescGZIP(), []int{0}
+}
+
+// Value is a typed feature value. A value without a kind is missing.
+type Value struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Kind:
+	//
+	//	*Value_Number
+	//	*Value_Text
+	//	*Value_Timestamp
+	Kind          isValue_Kind `protobuf_oneof:"kind"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Value) Reset() {
+	*x = Value{}
+	mi := &file_predictor_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Value) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Value) ProtoMessage() {}
+
+func (x *Value) ProtoReflect() protoreflect.Message {
+	mi := &file_predictor_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Value.ProtoReflect.Descriptor instead.
+func (*Value) Descriptor() ([]byte, []int) {
+	return file_predictor_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Value) GetKind() isValue_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return nil
+}
+
+func (x *Value) GetNumber() float64 {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_Number); ok {
+			return x.Number
+		}
+	}
+	return 0
+}
+
+func (x *Value) GetText() string {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_Text); ok {
+			return x.Text
+		}
+	}
+	return ""
+}
+
+func (x *Value) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_Timestamp); ok {
+			return x.Timestamp
+		}
+	}
+	return nil
+}
+
+type isValue_Kind interface {
+	isValue_Kind()
+}
+
+type Value_Number struct {
+	// number holds numerical features.
+	Number float64 `protobuf:"fixed64,1,opt,name=number,proto3,oneof"`
+}
+
+type Value_Text struct {
+	// text holds categorical features.
+	Text string `protobuf:"bytes,2,opt,name=text,proto3,oneof"`
+}
+
+type Value_Timestamp struct {
+	// timestamp holds date and timestamp features.
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3,oneof"`
+}
+
+func (*Value_Number) isValue_Kind() {}
+
+func (*Value_Text) isValue_Kind() {}
+
+func (*Value_Timestamp) isValue_Kind() {}
+
+type PredictRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// model is the name the model is served under.
+	Model string `protobuf:"bytes,1,opt,name=model,proto3" json:"model,omitempty"`
+	// features maps feature names to their values. Features left out are missing.
+	Features map[string]*Value `protobuf:"bytes,2,rep,name=features,proto3" json:"features,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// probabilities adds the predicted class distribution to the response.
+	Probabilities bool `protobuf:"varint,3,opt,name=probabilities,proto3" json:"probabilities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PredictRequest) Reset() {
+	*x = PredictRequest{}
+	mi := &file_predictor_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PredictRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PredictRequest) ProtoMessage() {}
+
+func (x *PredictRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_predictor_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PredictRequest.ProtoReflect.Descriptor instead.
+func (*PredictRequest) Descriptor() ([]byte, []int) {
+	return file_predictor_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PredictRequest) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *PredictRequest) GetFeatures() map[string]*Value {
+	if x != nil {
+		return x.Features
+	}
+	return nil
+}
+
+func (x *PredictRequest) GetProbabilities() bool {
+	if x != nil {
+		return x.Probabilities
+	}
+	return false
+}
+
+type PredictResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Prediction:
+	//
+	//	*PredictResponse_Class
+	//	*PredictResponse_Value
+	Prediction isPredictResponse_Prediction `protobuf_oneof:"prediction"`
+	// probabilities maps every class to its predicted probability when requested.
+	Probabilities map[string]float64 `protobuf:"bytes,3,rep,name=probabilities,proto3" json:"probabilities,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	// error explains why a PredictBatch request could not be predicted, in
+	// which case the response has no prediction. Predict returns its errors as
+	// the status of the call.
+	Error         string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PredictResponse) Reset() {
+	*x = PredictResponse{}
+	mi := &file_predictor_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PredictResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PredictResponse) ProtoMessage() {}
+
+func (x *PredictResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_predictor_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PredictResponse.ProtoReflect.Descriptor instead.
+func (*PredictResponse) Descriptor() ([]byte, []int) {
+	return file_predictor_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PredictResponse) GetPrediction() isPredictResponse_Prediction {
+	if x != nil {
+		return x.Prediction
+	}
+	return nil
+}
+
+func (x *PredictResponse) GetClass() string {
+	if x != nil {
+		if x, ok := x.Prediction.(*PredictResponse_Class); ok {
+			return x.Class
+		}
+	}
+	return ""
+}
+
+func (x *PredictResponse) GetValue() float64 {
+	if x != nil {
+		if x, ok := x.Prediction.(*PredictResponse_Value); ok {
+			return x.Value
+		}
+	}
+	return 0
+}
+
+func (x *PredictResponse) GetProbabilities() map[string]float64 {
+	if x != nil {
+		return x.Probabilities
+	}
+	return nil
+}
+
+func (x *PredictResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type isPredictResponse_Prediction interface {
+	isPredictResponse_Prediction()
+}
+
+type PredictResponse_Class struct {
+	// class is the predicted class of classification models.
+	Class string `protobuf:"bytes,1,opt,name=class,proto3,oneof"`
+}
+
+type PredictResponse_Value struct {
+	// value is the predicted value of regression models.
+	Value float64 `protobuf:"fixed64,2,opt,name=value,proto3,oneof"`
+}
+
+func (*PredictResponse_Class) isPredictResponse_Prediction() {}
+
+func (*PredictResponse_Value) isPredictResponse_Prediction() {}
+
+type GetModelInfoRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// model is the name the model is served under.
+	Model         string `protobuf:"bytes,1,opt,name=model,proto3" json:"model,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetModelInfoRequest) Reset() {
+	*x = GetModelInfoRequest{}
+	mi := &file_predictor_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetModelInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetModelInfoRequest) ProtoMessage() {}
+
+func (x *GetModelInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_predictor_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetModelInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetModelInfoRequest) Descriptor() ([]byte, []int) {
+	return file_predictor_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetModelInfoRequest) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+type Feature struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type          FeatureType            `protobuf:"varint,2,opt,name=type,proto3,enum=decisiontree.v1.FeatureType" json:"type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Feature) Reset() {
+	*x = Feature{}
+	mi := &file_predictor_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Feature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Feature) ProtoMessage() {}
+
+func (x *Feature) ProtoReflect() protoreflect.Message {
+	mi := &file_predictor_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Feature.ProtoReflect.Descriptor instead.
+func (*Feature) Descriptor() ([]byte, []int) {
+	return file_predictor_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Feature) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Feature) GetType() FeatureType {
+	if x != nil {
+		return x.Type
+	}
+	return FeatureType_FEATURE_TYPE_UNSPECIFIED
+}
+
+type ModelInfo struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	File  string                 `protobuf:"bytes,2,opt,name=file,proto3" json:"file,omitempty"`
+	// task is classification or regression.
+	Task     string     `protobuf:"bytes,3,opt,name=task,proto3" json:"task,omitempty"`
+	Target   string     `protobuf:"bytes,4,opt,name=target,proto3" json:"target,omitempty"`
+	Features []*Feature `protobuf:"bytes,5,rep,name=features,proto3" json:"features,omitempty"`
+	// classes lists the classes of classification models.
+	Classes []string `protobuf:"bytes,6,rep,name=classes,proto3" json:"classes,omitempty"`
+	// ensemble is forest, bag or boost for ensembles and empty for single trees.
+	Ensemble      string                 `protobuf:"bytes,7,opt,name=ensemble,proto3" json:"ensemble,omitempty"`
+	Trees         int32                  `protobuf:"varint,8,opt,name=trees,proto3" json:"trees,omitempty"`
+	LoadedAt      *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=loaded_at,json=loadedAt,proto3" json:"loaded_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModelInfo) Reset() {
+	*x = ModelInfo{}
+	mi := &file_predictor_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModelInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModelInfo) ProtoMessage() {}
+
+func (x *ModelInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_predictor_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModelInfo.ProtoReflect.Descriptor instead.
+func (*ModelInfo) Descriptor() ([]byte, []int) {
+	return file_predictor_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ModelInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ModelInfo) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *ModelInfo) GetTask() string {
+	if x != nil {
+		return x.Task
+	}
+	return ""
+}
+
+func (x *ModelInfo) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *ModelInfo) GetFeatures() []*Feature {
+	if x != nil {
+		return x.Features
+	}
+	return nil
+}
+
+func (x *ModelInfo) GetClasses() []string {
+	if x != nil {
+		return x.Classes
+	}
+	return nil
+}
+
+func (x *ModelInfo) GetEnsemble() string {
+	if x != nil {
+		return x.Ensemble
+	}
+	return ""
+}
+
+func (x *ModelInfo) GetTrees() int32 {
+	if x != nil {
+		return x.Trees
+	}
+	return 0
+}
+
+func (x *ModelInfo) GetLoadedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LoadedAt
+	}
+	return nil
+}
+
+var File_predictor_proto protoreflect.FileDescriptor
+
+const file_predictor_proto_rawDesc = "" +
+	"\n" +
+	"\x0fpredictor.proto\x12\x0fdecisiontree.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"{\n" +
+	"\x05Value\x12\x18\n" +
+	"\x06number\x18\x01 \x01(\x01H\x00R\x06number\x12\x14\n" +
+	"\x04text\x18\x02 \x01(\tH\x00R\x04text\x12:\n" +
+	"\ttimestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampH\x00R\ttimestampB\x06\n" +
+	"\x04kind\"\xec\x01\n" +
+	"\x0ePredictRequest\x12\x14\n" +
+	"\x05model\x18\x01 \x01(\tR\x05model\x12I\n" +
+	"\bfeatures\x18\x02 \x03(\v2-.decisiontree.v1.PredictRequest.FeaturesEntryR\bfeatures\x12$\n" +
+	"\rprobabilities\x18\x03 \x01(\bR\rprobabilities\x1aS\n" +
+	"\rFeaturesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12,\n" +
+	"\x05value\x18\x02 \x01(\v2\x16.decisiontree.v1.ValueR\x05value:\x028\x01\"\x82\x02\n" +
+	"\x0fPredictResponse\x12\x16\n" +
+	"\x05class\x18\x01 \x01(\tH\x00R\x05class\x12\x16\n" +
+	"\x05value\x18\x02 \x01(\x01H\x00R\x05value\x12Y\n" +
+	"\rprobabilities\x18\x03 \x03(\v23.decisiontree.v1.PredictResponse.ProbabilitiesEntryR\rprobabilities\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x1a@\n" +
+	"\x12ProbabilitiesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01B\f\n" +
+	"\n" +
+	"prediction\"+\n" +
+	"\x13GetModelInfoRequest\x12\x14\n" +
+	"\x05model\x18\x01 \x01(\tR\x05model\"O\n" +
+	"\aFeature\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x120\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x1c.decisiontree.v1.FeatureTypeR\x04type\"\x9a\x02\n" +
+	"\tModelInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04file\x18\x02 \x01(\tR\x04file\x12\x12\n" +
+	"\x04task\x18\x03 \x01(\tR\x04task\x12\x16\n" +
+	"\x06target\x18\x04 \x01(\tR\x06target\x124\n" +
+	"\bfeatures\x18\x05 \x03(\v2\x18.decisiontree.v1.FeatureR\bfeatures\x12\x18\n" +
+	"\aclasses\x18\x06 \x03(\tR\aclasses\x12\x1a\n" +
+	"\bensemble\x18\a \x01(\tR\bensemble\x12\x14\n" +
+	"\x05trees\x18\b \x01(\x05R\x05trees\x127\n" +
+	"\tloaded_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\bloadedAt*\x98\x01\n" +
+	"\vFeatureType\x12\x1c\n" +
+	"\x18FEATURE_TYPE_UNSPECIFIED\x10\x00\x12\x1c\n" +
+	"\x18FEATURE_TYPE_CATEGORICAL\x10\x01\x12\x1a\n" +
+	"\x16FEATURE_TYPE_NUMERICAL\x10\x02\x12\x15\n" +
+	"\x11FEATURE_TYPE_DATE\x10\x03\x12\x1a\n" +
+	"\x16FEATURE_TYPE_TIMESTAMP\x10\x042\x82\x02\n" +
+	"\tPredictor\x12L\n" +
+	"\aPredict\x12\x1f.decisiontree.v1.PredictRequest\x1a .decisiontree.v1.PredictResponse\x12U\n" +
+	"\fPredictBatch\x12\x1f.decisiontree.v1.PredictRequest\x1a .decisiontree.v1.PredictResponse(\x010\x01\x12P\n" +
+	"\fGetModelInfo\x12$.decisiontree.v1.GetModelInfoRequest\x1a\x1a.decisiontree.v1.ModelInfoB<Z:github.com/nyunja/c4.5-decision-tree/internal/model/rpc/pbb\x06proto3"
+
+var (
+	file_predictor_proto_rawDescOnce sync.Once
+	file_predictor_proto_rawDescData []byte
+)
+
+func file_predictor_proto_rawDescGZIP() []byte {
+	file_predictor_proto_rawDescOnce.Do(func() {
+		file_predictor_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_predictor_proto_rawDesc), len(file_predictor_proto_rawDesc)))
+	})
+	return file_predictor_proto_rawDescData
+}
+
+var file_predictor_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_predictor_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_predictor_proto_goTypes = []any{
+	(FeatureType)(0),              // 0: decisiontree.v1.FeatureType
+	(*Value)(nil),                 // 1: decisiontree.v1.Value
+	(*PredictRequest)(nil),        // 2: decisiontree.v1.PredictRequest
+	(*PredictResponse)(nil),       // 3: decisiontree.v1.PredictResponse
+	(*GetModelInfoRequest)(nil),   // 4: decisiontree.v1.GetModelInfoRequest
+	(*Feature)(nil),               // 5: decisiontree.v1.Feature
+	(*ModelInfo)(nil),             // 6: decisiontree.v1.ModelInfo
+	nil,                           // 7: decisiontree.v1.PredictRequest.FeaturesEntry
+	nil,                           // 8: decisiontree.v1.PredictResponse.ProbabilitiesEntry
+	(*timestamppb.Timestamp)(nil), // 9: google.protobuf.Timestamp
+}
+var file_predictor_proto_depIdxs = []int32{
+	9,  // 0: decisiontree.v1.Value.timestamp:type_name -> google.protobuf.Timestamp
+	7,  // 1: decisiontree.v1.PredictRequest.features:type_name -> decisiontree.v1.PredictRequest.FeaturesEntry
+	8,  // 2: decisiontree.v1.PredictResponse.probabilities:type_name -> decisiontree.v1.PredictResponse.ProbabilitiesEntry
+	0,  // 3: decisiontree.v1.Feature.type:type_name -> decisiontree.v1.FeatureType
+	5,  // 4: decisiontree.v1.ModelInfo.features:type_name -> decisiontree.v1.Feature
+	9,  // 5: decisiontree.v1.ModelInfo.loaded_at:type_name -> google.protobuf.Timestamp
+	1,  // 6: decisiontree.v1.PredictRequest.FeaturesEntry.value:type_name -> decisiontree.v1.Value
+	2,  // 7: decisiontree.v1.Predictor.Predict:input_type -> decisiontree.v1.PredictRequest
+	2,  // 8: decisiontree.v1.Predictor.PredictBatch:input_type -> decisiontree.v1.PredictRequest
+	4,  // 9: decisiontree.v1.Predictor.GetModelInfo:input_type -> decisiontree.v1.GetModelInfoRequest
+	3,  // 10: decisiontree.v1.Predictor.Predict:output_type -> decisiontree.v1.PredictResponse
+	3,  // 11: decisiontree.v1.Predictor.PredictBatch:output_type -> decisiontree.v1.PredictResponse
+	6,  // 12: decisiontree.v1.Predictor.GetModelInfo:output_type -> decisiontree.v1.ModelInfo
+	10, // [10:13] is the sub-list for method output_type
+	7,  // [7:10] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_predictor_proto_init() }
+func file_predictor_proto_init() {
+	if File_predictor_proto != nil {
+		return
+	}
+	file_predictor_proto_msgTypes[0].OneofWrappers = []any{
+		(*Value_Number)(nil),
+		(*Value_Text)(nil),
+		(*Value_Timestamp)(nil),
+	}
+	file_predictor_proto_msgTypes[2].OneofWrappers = []any{
+		(*PredictResponse_Class)(nil),
+		(*PredictResponse_Value)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_predictor_proto_rawDesc), len(file_predictor_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_predictor_proto_goTypes,
+		DependencyIndexes: file_predictor_proto_depIdxs,
+		EnumInfos:         file_predictor_proto_enumTypes,
+		MessageInfos:      file_predictor_proto_msgTypes,
+	}.Build()
+	File_predictor_proto = out.File
+	file_predictor_proto_goTypes = nil
+	file_predictor_proto_depIdxs = nil
+}
@@ -0,0 +1,227 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/predict"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/rpc/pb"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/server"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
+)
+
+// Service implements the Predictor gRPC service over the models of a
+// server, so both protocols serve the same, hot reloaded, models
+type Service struct {
+	pb.UnimplementedPredictorServer
+	models *server.Server
+}
+
+// NewService returns the gRPC service for the models of a server
+func NewService(models *server.Server) *Service {
+	return &Service{models: models}
+}
+
+// NewGRPCServer returns a gRPC server with the Predictor service registered.
+// Messages are limited to maxMessageBytes, the gRPC default when 0.
+func NewGRPCServer(models *server.Server, maxMessageBytes int) *grpc.Server {
+	var options []grpc.ServerOption
+	if maxMessageBytes > 0 {
+		options = append(options, grpc.MaxRecvMsgSize(maxMessageBytes))
+	}
+	grpcServer := grpc.NewServer(options...)
+	pb.RegisterPredictorServer(grpcServer, NewService(models))
+	return grpcServer
+}
+
+// ListenAndServe serves the gRPC server on addr until ctx is cancelled, then
+// lets the calls in flight finish, for at most timeout
+func ListenAndServe(ctx context.Context, grpcServer *grpc.Server, addr string, timeout time.Duration) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %v", addr, err)
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- grpcServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		grpcServer.Stop()
+	}
+	return <-errs
+}
+
+// Predict predicts one instance
+func (s *Service) Predict(ctx context.Context, request *pb.PredictRequest) (*pb.PredictResponse, error) {
+	return s.predict(request)
+}
+
+// PredictBatch predicts a stream of instances, answering each in order. A
+// request that cannot be predicted is answered with its error and the stream
+// goes on
+func (s *Service) PredictBatch(stream pb.Predictor_PredictBatchServer) error {
+	for {
+		request, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		response, err := s.predict(request)
+		if err != nil {
+			response = &pb.PredictResponse{Error: status.Convert(err).Message()}
+		}
+		if err := stream.Send(response); err != nil {
+			return err
+		}
+	}
+}
+
+// GetModelInfo describes a served model
+func (s *Service) GetModelInfo(ctx context.Context, request *pb.GetModelInfoRequest) (*pb.ModelInfo, error) {
+	_, info, ok := s.models.Model(request.GetModel())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown model '%s'", request.GetModel())
+	}
+
+	response := &pb.ModelInfo{
+		Name:     info.Name,
+		File:     info.File,
+		Task:     info.Task,
+		Target:   info.Target,
+		Classes:  info.Classes,
+		Ensemble: info.Ensemble,
+		Trees:    int32(info.Trees),
+		LoadedAt: timestamppb.New(info.LoadedAt),
+	}
+	for _, feature := range info.Features {
+		response.Features = append(response.Features, &pb.Feature{Name: feature, Type: featureType(info.FeatureTypes[feature])})
+	}
+	return response, nil
+}
+
+// predict predicts the instance of a request
+func (s *Service) predict(request *pb.PredictRequest) (*pb.PredictResponse, error) {
+	model, _, ok := s.models.Model(request.GetModel())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown model '%s'", request.GetModel())
+	}
+
+	instance, err := ToInstance(request.GetFeatures(), model)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if model.Task == t.Regression {
+		return &pb.PredictResponse{Prediction: &pb.PredictResponse_Value{Value: predict.PredictValue(model, instance)}}, nil
+	}
+	response := &pb.PredictResponse{Prediction: &pb.PredictResponse_Class{Class: predict.PredictClass(model, instance)}}
+	if request.GetProbabilities() {
+		response.Probabilities = predict.PredictProbabilities(model, instance)
+	}
+	return response, nil
+}
+
+// ToInstance converts typed feature values to the instance the model reads.
+// Numbers are accepted for numerical features, timestamps for date and
+// timestamp features, and text for categorical features. Numerical, date and
+// timestamp features also accept text they can be parsed from, and
+// categorical features accept numbers. Values without a kind are missing.
+func ToInstance(values map[string]*pb.Value, model *t.Model) (t.Instance, error) {
+	instance := make(t.Instance, len(values))
+	for feature, value := range values {
+		featureType := model.FeatureTypes[feature]
+
+		switch kind := value.GetKind().(type) {
+		case nil:
+			instance[feature] = nil
+
+		case *pb.Value_Number:
+			switch featureType {
+			case "date", "timestamp":
+				return nil, fmt.Errorf("feature '%s' is a %s, got a number", feature, featureType)
+			case "numerical":
+				instance[feature] = kind.Number
+			default:
+				instance[feature] = strconv.FormatFloat(kind.Number, 'f', -1, 64)
+			}
+
+		case *pb.Value_Timestamp:
+			if featureType == "numerical" || featureType == "categorical" {
+				return nil, fmt.Errorf("feature '%s' is %s, got a timestamp", feature, featureType)
+			}
+			if err := kind.Timestamp.CheckValid(); err != nil {
+				return nil, fmt.Errorf("feature '%s': %v", feature, err)
+			}
+			instance[feature] = kind.Timestamp.AsTime()
+
+		case *pb.Value_Text:
+			switch featureType {
+			case "numerical":
+				number, err := utils.ConvertStringToNumerical(kind.Text)
+				if err != nil {
+					return nil, fmt.Errorf("feature '%s' is numerical, got '%s'", feature, kind.Text)
+				}
+				instance[feature] = number
+			case "date":
+				date, err := utils.ConvertStringToDate(kind.Text)
+				if err != nil {
+					return nil, fmt.Errorf("feature '%s' is a date, got '%s'", feature, kind.Text)
+				}
+				instance[feature] = *date
+			case "timestamp":
+				timestamp, err := utils.ConvertStringToTimestamp(kind.Text)
+				if err != nil {
+					return nil, fmt.Errorf("feature '%s' is a timestamp, got '%s'", feature, kind.Text)
+				}
+				instance[feature] = *timestamp
+			default:
+				instance[feature] = kind.Text
+			}
+		}
+	}
+	return instance, nil
+}
+
+// featureType maps the feature types of a model to the schema
+func featureType(featureType string) pb.FeatureType {
+	switch featureType {
+	case "categorical":
+		return pb.FeatureType_FEATURE_TYPE_CATEGORICAL
+	case "numerical":
+		return pb.FeatureType_FEATURE_TYPE_NUMERICAL
+	case "date":
+		return pb.FeatureType_FEATURE_TYPE_DATE
+	case "timestamp":
+		return pb.FeatureType_FEATURE_TYPE_TIMESTAMP
+	}
+	return pb.FeatureType_FEATURE_TYPE_UNSPECIFIED
+}
@@ -0,0 +1,233 @@
+package rpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	m "github.com/nyunja/c4.5-decision-tree/internal/model/model"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/rpc/pb"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/server"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+)
+
+// signupModel predicts churn from the plan and, for recent signups, the spend
+func signupModel() *t.Model {
+	cutoff := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	return &t.Model{
+		FeatureNames: []string{"signup", "spend", "plan", "churn"},
+		FeatureTypes: map[string]string{"signup": "date", "spend": "numerical", "plan": "categorical", "churn": "categorical"},
+		TargetName:   "churn",
+		Task:         t.Classification,
+		Root: &t.Node{
+			Feature:     "signup",
+			Continuous:  true,
+			Threshold:   float64(cutoff.Unix()),
+			Samples:     100,
+			ClassCounts: map[string]float64{"stay": 70, "leave": 30},
+			Children: []*t.Node{
+				{IsLeaf: true, Class: "stay", Samples: 50, ClassCounts: map[string]float64{"stay": 45, "leave": 5}},
+				{
+					Feature:     "spend",
+					Continuous:  true,
+					Threshold:   20,
+					Samples:     50,
+					ClassCounts: map[string]float64{"stay": 25, "leave": 25},
+					Children: []*t.Node{
+						{IsLeaf: true, Class: "leave", Samples: 25, ClassCounts: map[string]float64{"stay": 5, "leave": 20}},
+						{IsLeaf: true, Class: "stay", Samples: 25, ClassCounts: map[string]float64{"stay": 20, "leave": 5}},
+					},
+				},
+			},
+		},
+	}
+}
+
+// valueModel is a regression stump on size
+func valueModel() *t.Model {
+	return &t.Model{
+		FeatureNames: []string{"size", "price"},
+		FeatureTypes: map[string]string{"size": "numerical"},
+		TargetName:   "price",
+		Task:         t.Regression,
+		Root: &t.Node{
+			Feature:    "size",
+			Continuous: true,
+			Threshold:  80,
+			Children: []*t.Node{
+				{IsLeaf: true, Mean: 150},
+				{IsLeaf: true, Mean: 400},
+			},
+		},
+	}
+}
+
+// newClient serves the service over an in-memory connection and returns a client
+func newClient(tt *testing.T) pb.PredictorClient {
+	tt.Cleanup(func() { os.RemoveAll("./decision_model") })
+	require.NoError(tt, m.SaveModel(signupModel(), "churn.json"))
+	require.NoError(tt, m.SaveModel(valueModel(), "price.json"))
+	models, err := server.New([]string{"churn.json", "price.json"}, server.Options{})
+	require.NoError(tt, err)
+
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := NewGRPCServer(models, 0)
+	go grpcServer.Serve(listener)
+	tt.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(tt, err)
+	tt.Cleanup(func() { conn.Close() })
+	return pb.NewPredictorClient(conn)
+}
+
+func number(v float64) *pb.Value { return &pb.Value{Kind: &pb.Value_Number{Number: v}} }
+func text(v string) *pb.Value    { return &pb.Value{Kind: &pb.Value_Text{Text: v}} }
+func date(y int, mo time.Month, d int) *pb.Value {
+	return &pb.Value{Kind: &pb.Value_Timestamp{Timestamp: timestamppb.New(time.Date(y, mo, d, 0, 0, 0, 0, time.UTC))}}
+}
+
+func TestPredict(tt *testing.T) {
+	client := newClient(tt)
+	ctx := context.Background()
+
+	response, err := client.Predict(ctx, &pb.PredictRequest{
+		Model:         "churn",
+		Features:      map[string]*pb.Value{"signup": date(2024, 6, 1), "spend": number(10), "plan": text("basic")},
+		Probabilities: true,
+	})
+	require.NoError(tt, err)
+	assert.Equal(tt, "leave", response.GetClass())
+	assert.InDelta(tt, 0.8, response.GetProbabilities()["leave"], 1e-9)
+
+	// Text is parsed for typed features, and empty values are missing
+	response, err = client.Predict(ctx, &pb.PredictRequest{
+		Model:    "churn",
+		Features: map[string]*pb.Value{"signup": text("2024-01-15"), "spend": {}},
+	})
+	require.NoError(tt, err)
+	assert.Equal(tt, "stay", response.GetClass())
+	assert.Empty(tt, response.GetProbabilities())
+
+	response, err = client.Predict(ctx, &pb.PredictRequest{Model: "price", Features: map[string]*pb.Value{"size": number(120)}})
+	require.NoError(tt, err)
+	assert.Equal(tt, 400.0, response.GetValue())
+}
+
+func TestPredict_Errors(tt *testing.T) {
+	client := newClient(tt)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		request *pb.PredictRequest
+		code    codes.Code
+	}{
+		{"Unknown model", &pb.PredictRequest{Model: "other"}, codes.NotFound},
+		{"Number for a date", &pb.PredictRequest{Model: "churn", Features: map[string]*pb.Value{"signup": number(3)}}, codes.InvalidArgument},
+		{"Timestamp for a number", &pb.PredictRequest{Model: "churn", Features: map[string]*pb.Value{"spend": date(2024, 1, 1)}}, codes.InvalidArgument},
+		{"Unparsable number", &pb.PredictRequest{Model: "churn", Features: map[string]*pb.Value{"spend": text("a lot")}}, codes.InvalidArgument},
+	}
+	for _, tc := range tests {
+		tt.Run(tc.name, func(tt *testing.T) {
+			_, err := client.Predict(ctx, tc.request)
+			assert.Equal(tt, tc.code, status.Code(err))
+		})
+	}
+}
+
+func TestPredictBatch(tt *testing.T) {
+	client := newClient(tt)
+
+	stream, err := client.PredictBatch(context.Background())
+	require.NoError(tt, err)
+
+	spends := []float64{5, 50, 15}
+	for _, spend := range spends {
+		require.NoError(tt, stream.Send(&pb.PredictRequest{
+			Model:    "churn",
+			Features: map[string]*pb.Value{"signup": date(2024, 6, 1), "spend": number(spend)},
+		}))
+	}
+	require.NoError(tt, stream.CloseSend())
+
+	var classes []string
+	for {
+		response, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(tt, err)
+		classes = append(classes, response.GetClass())
+	}
+	assert.Equal(tt, []string{"leave", "stay", "leave"}, classes)
+}
+
+func TestPredictBatch_InvalidRequest(tt *testing.T) {
+	client := newClient(tt)
+
+	stream, err := client.PredictBatch(context.Background())
+	require.NoError(tt, err)
+
+	requests := []*pb.PredictRequest{
+		{Model: "other", Features: map[string]*pb.Value{"spend": number(5)}},
+		{Model: "churn", Features: map[string]*pb.Value{"spend": text("lots")}},
+		{Model: "churn", Features: map[string]*pb.Value{"signup": date(2024, 6, 1), "spend": number(50)}},
+	}
+	for _, request := range requests {
+		require.NoError(tt, stream.Send(request))
+	}
+	require.NoError(tt, stream.CloseSend())
+
+	var responses []*pb.PredictResponse
+	for {
+		response, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(tt, err)
+		responses = append(responses, response)
+	}
+	require.Len(tt, responses, 3)
+	assert.Equal(tt, "unknown model 'other'", responses[0].GetError())
+	assert.Empty(tt, responses[0].GetClass())
+	assert.Contains(tt, responses[1].GetError(), "spend")
+	assert.Empty(tt, responses[2].GetError())
+	assert.Equal(tt, "stay", responses[2].GetClass())
+}
+
+func TestGetModelInfo(tt *testing.T) {
+	client := newClient(tt)
+
+	info, err := client.GetModelInfo(context.Background(), &pb.GetModelInfoRequest{Model: "churn"})
+	require.NoError(tt, err)
+	assert.Equal(tt, "churn", info.GetName())
+	assert.Equal(tt, "classification", info.GetTask())
+	assert.Equal(tt, "churn", info.GetTarget())
+	assert.Equal(tt, []string{"leave", "stay"}, info.GetClasses())
+	assert.Equal(tt, int32(1), info.GetTrees())
+	require.Len(tt, info.GetFeatures(), 3)
+	assert.Equal(tt, "signup", info.GetFeatures()[0].GetName())
+	assert.Equal(tt, pb.FeatureType_FEATURE_TYPE_DATE, info.GetFeatures()[0].GetType())
+	assert.Equal(tt, pb.FeatureType_FEATURE_TYPE_NUMERICAL, info.GetFeatures()[1].GetType())
+
+	_, err = client.GetModelInfo(context.Background(), &pb.GetModelInfoRequest{Model: "other"})
+	assert.Equal(tt, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
+)
+
+// ToInstance converts the JSON values of an instance to the types the model
+// was trained on, like the prediction parser does for CSV records. Numbers
+// are kept for numerical features, numerical, date and timestamp strings are
+// parsed, and every other value is read as a category. Nulls are missing
+// values. Values that do not parse as their feature's type are an error, as
+// they are for the gRPC service.
+func ToInstance(values map[string]interface{}, model *t.Model) (t.Instance, error) {
+	instance := make(t.Instance, len(values))
+	for feature, value := range values {
+		if value == nil {
+			instance[feature] = nil
+			continue
+		}
+
+		featureType := model.FeatureTypes[feature]
+		switch featureType {
+		case "numerical", "date", "timestamp":
+			if number, ok := value.(float64); ok && featureType == "numerical" {
+				instance[feature] = number
+				continue
+			}
+			converted, ok := utils.ConvertValue(toString(value), featureType)
+			if !ok {
+				return nil, typeError(feature, featureType, toString(value))
+			}
+			instance[feature] = converted
+		default:
+			instance[feature] = toString(value)
+		}
+	}
+	return instance, nil
+}
+
+// typeError reports a value that does not parse as the type of its feature,
+// worded as the gRPC service words it
+func typeError(feature, featureType, value string) error {
+	if featureType == "numerical" {
+		return fmt.Errorf("feature '%s' is numerical, got '%s'", feature, value)
+	}
+	return fmt.Errorf("feature '%s' is a %s, got '%s'", feature, featureType, value)
+}
+
+// toString formats a JSON value as a category
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", value)
+}
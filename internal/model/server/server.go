@@ -0,0 +1,405 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/explain"
+	m "github.com/nyunja/c4.5-decision-tree/internal/model/model"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/predict"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+)
+
+// DefaultMaxBodyBytes limits the size of request bodies when Options does not
+const DefaultMaxBodyBytes = 1 << 20
+
+// Options holds the parameters of the server
+type Options struct {
+	// MaxBodyBytes is the largest request body accepted, DefaultMaxBodyBytes when 0
+	MaxBodyBytes int64
+	// ShutdownTimeout is how long in-flight requests may take to finish on
+	// shutdown, 10 seconds when 0
+	ShutdownTimeout time.Duration
+}
+
+// Server serves predictions of one or more models over HTTP
+type Server struct {
+	options Options
+
+	mu     sync.RWMutex
+	models map[string]*entry
+	names  []string
+}
+
+// entry is a loaded model and the state of its file
+type entry struct {
+	file     string
+	model    *t.Model
+	loadedAt time.Time
+	modTime  time.Time // modification time of the file last loaded
+	failed   time.Time // modification time of the file that last failed to load
+}
+
+// ModelInfo describes a served model
+type ModelInfo struct {
+	Name         string            `json:"name"`
+	File         string            `json:"file"`
+	Task         string            `json:"task"`
+	Target       string            `json:"target"`
+	Features     []string          `json:"features"`
+	FeatureTypes map[string]string `json:"feature_types"`
+	Classes      []string          `json:"classes,omitempty"`
+	Ensemble     string            `json:"ensemble,omitempty"`
+	Trees        int               `json:"trees"`
+	LoadedAt     time.Time         `json:"loaded_at"`
+}
+
+// Prediction is the response for one instance. Prediction holds the class,
+// or the value for regression models.
+type Prediction struct {
+	Prediction    interface{}          `json:"prediction"`
+	Probabilities map[string]float64   `json:"probabilities,omitempty"`
+	Explanation   *explain.Explanation `json:"explanation,omitempty"`
+}
+
+// New loads the model files with LoadModel. Each model is served under its
+// file name without the extension.
+func New(files []string, options Options) (*Server, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no model to serve")
+	}
+	if options.MaxBodyBytes <= 0 {
+		options.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	if options.ShutdownTimeout <= 0 {
+		options.ShutdownTimeout = 10 * time.Second
+	}
+
+	s := &Server{options: options, models: make(map[string]*entry, len(files))}
+	for _, file := range files {
+		name := ModelName(file)
+		if _, exists := s.models[name]; exists {
+			return nil, fmt.Errorf("two models are named '%s'", name)
+		}
+
+		info, err := os.Stat(m.ModelPath(file))
+		if err != nil {
+			return nil, fmt.Errorf("error reading model %s: %v", file, err)
+		}
+		model, err := m.LoadModel(file)
+		if err != nil {
+			return nil, err
+		}
+		s.models[name] = &entry{file: file, model: model, loadedAt: time.Now(), modTime: info.ModTime()}
+		s.names = append(s.names, name)
+	}
+	sort.Strings(s.names)
+	return s, nil
+}
+
+// ModelName returns the name a model file is served under
+func ModelName(file string) string {
+	base := filepath.Base(file)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// Handler returns the HTTP routes of the server:
+//
+//	GET  /healthz                           health check
+//	GET  /v1/models                         metadata of every model
+//	GET  /v1/models/{name}                  metadata of one model
+//	POST /v1/models/{name}/predict          predict {"instance": {...}}
+//	POST /v1/models/{name}/predict/batch    predict {"instances": [{...}, ...]}
+//
+// The predict routes add class probabilities with ?probabilities=true and
+// decision paths with ?explain=true.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.health)
+	mux.HandleFunc("GET /v1/models", s.listModels)
+	mux.HandleFunc("GET /v1/models/{name}", s.getModel)
+	mux.HandleFunc("POST /v1/models/{name}/predict", s.predictOne)
+	mux.HandleFunc("POST /v1/models/{name}/predict/batch", s.predictBatch)
+	return mux
+}
+
+// ListenAndServe serves on addr until ctx is cancelled, then stops accepting
+// requests and waits for the ones in flight
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.options.ShutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("error shutting down: %v", err)
+	}
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Watch reloads every model whose file changed, checking every interval
+// until ctx is cancelled
+func (s *Server) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Reload()
+		}
+	}
+}
+
+// Reload reloads the models whose file was modified since it was last
+// loaded. A model that fails to load keeps being served from the last good
+// file and is tried again on every reload, logging the failure once per
+// version of the file.
+func (s *Server) Reload() {
+	for _, name := range s.names {
+		s.mu.RLock()
+		current := s.models[name]
+		s.mu.RUnlock()
+
+		info, err := os.Stat(m.ModelPath(current.file))
+		if err != nil || info.ModTime().Equal(current.modTime) {
+			continue
+		}
+
+		next := &entry{file: current.file, model: current.model, loadedAt: current.loadedAt, modTime: current.modTime, failed: current.failed}
+		model, err := m.LoadModel(current.file)
+		if err != nil {
+			if !info.ModTime().Equal(current.failed) {
+				log.Printf("Keeping the loaded model %s: %v", name, err)
+			}
+			next.failed = info.ModTime()
+		} else {
+			next.model = model
+			next.loadedAt = time.Now()
+			next.modTime = info.ModTime()
+			log.Printf("Reloaded model %s from %s", name, current.file)
+		}
+
+		s.mu.Lock()
+		s.models[name] = next
+		s.mu.Unlock()
+	}
+}
+
+// Model returns the model served under a name and its metadata
+func (s *Server) Model(name string) (*t.Model, ModelInfo, bool) {
+	e, ok := s.lookup(name)
+	if !ok {
+		return nil, ModelInfo{}, false
+	}
+	return e.model, describe(name, e), true
+}
+
+// lookup returns the model served under a name
+func (s *Server) lookup(name string) (*entry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.models[name]
+	return e, ok
+}
+
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok", "models": len(s.names)})
+}
+
+func (s *Server) listModels(w http.ResponseWriter, r *http.Request) {
+	infos := make([]ModelInfo, 0, len(s.names))
+	for _, name := range s.names {
+		e, _ := s.lookup(name)
+		infos = append(infos, describe(name, e))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"models": infos})
+}
+
+func (s *Server) getModel(w http.ResponseWriter, r *http.Request) {
+	e, ok := s.lookup(r.PathValue("name"))
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown model '%s'", r.PathValue("name")))
+		return
+	}
+	writeJSON(w, http.StatusOK, describe(r.PathValue("name"), e))
+}
+
+func (s *Server) predictOne(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Instance map[string]interface{} `json:"instance"`
+	}
+	e, options, ok := s.prepare(w, r, &body)
+	if !ok {
+		return
+	}
+	if body.Instance == nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("missing instance"))
+		return
+	}
+	instance, err := ToInstance(body.Instance, e.model)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, predictInstance(e.model, instance, options))
+}
+
+func (s *Server) predictBatch(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Instances []map[string]interface{} `json:"instances"`
+	}
+	e, options, ok := s.prepare(w, r, &body)
+	if !ok {
+		return
+	}
+
+	predictions := make([]Prediction, len(body.Instances))
+	for i, values := range body.Instances {
+		instance, err := ToInstance(values, e.model)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("instance %d: %v", i, err))
+			return
+		}
+		predictions[i] = predictInstance(e.model, instance, options)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"predictions": predictions})
+}
+
+// predictOptions holds the optional parts of a prediction response
+type predictOptions struct {
+	probabilities bool
+	explain       bool
+}
+
+// prepare finds the model of a predict request, reads its options from the
+// query and decodes its body, writing the error response when one fails
+func (s *Server) prepare(w http.ResponseWriter, r *http.Request, body interface{}) (*entry, predictOptions, bool) {
+	var options predictOptions
+	e, ok := s.lookup(r.PathValue("name"))
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown model '%s'", r.PathValue("name")))
+		return nil, options, false
+	}
+
+	var err error
+	if options.probabilities, err = queryBool(r, "probabilities"); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return nil, options, false
+	}
+	if options.explain, err = queryBool(r, "explain"); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return nil, options, false
+	}
+
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.options.MaxBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(body); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("request body is larger than %d bytes", tooLarge.Limit))
+		} else {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %v", err))
+		}
+		return nil, options, false
+	}
+	return e, options, true
+}
+
+// predictInstance predicts one instance with the requested extras
+func predictInstance(model *t.Model, instance t.Instance, options predictOptions) Prediction {
+	var prediction Prediction
+	if model.Task == t.Regression {
+		prediction.Prediction = predict.PredictValue(model, instance)
+	} else {
+		prediction.Prediction = predict.PredictClass(model, instance)
+		if options.probabilities {
+			prediction.Probabilities = predict.PredictProbabilities(model, instance)
+		}
+	}
+	if options.explain {
+		explanation := explain.Explain(model, instance)
+		prediction.Explanation = &explanation
+	}
+	return prediction
+}
+
+// describe returns the metadata of a loaded model
+func describe(name string, e *entry) ModelInfo {
+	model := e.model
+	info := ModelInfo{
+		Name:         name,
+		File:         e.file,
+		Task:         model.Task,
+		Target:       model.TargetName,
+		Features:     explain.ModelFeatures(model),
+		FeatureTypes: model.FeatureTypes,
+		Trees:        1,
+		LoadedAt:     e.loadedAt,
+	}
+	if info.Task == "" {
+		info.Task = t.Classification
+	}
+	if info.Task == t.Classification {
+		info.Classes = explain.Outputs(model)
+	}
+	if model.Ensemble != nil {
+		info.Ensemble = model.Ensemble.Method
+		info.Trees = len(model.Ensemble.Trees)
+	}
+	return info
+}
+
+// queryBool reads an optional boolean query parameter
+func queryBool(r *http.Request, name string) (bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value '%s' for %s", value, name)
+	}
+	return parsed, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
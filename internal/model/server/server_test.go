@@ -0,0 +1,220 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	m "github.com/nyunja/c4.5-decision-tree/internal/model/model"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loanModel approves incomes above 52000
+func loanModel(threshold float64) *t.Model {
+	return &t.Model{
+		FeatureNames: []string{"income", "region", "label"},
+		FeatureTypes: map[string]string{"income": "numerical", "region": "categorical"},
+		TargetName:   "label",
+		Task:         t.Classification,
+		Root: &t.Node{
+			Feature:     "income",
+			Continuous:  true,
+			Threshold:   threshold,
+			Samples:     100,
+			ClassCounts: map[string]float64{"approve": 60, "reject": 40},
+			Children: []*t.Node{
+				{IsLeaf: true, Class: "reject", Samples: 40, ClassCounts: map[string]float64{"approve": 10, "reject": 30}},
+				{IsLeaf: true, Class: "approve", Samples: 60, ClassCounts: map[string]float64{"approve": 50, "reject": 10}},
+			},
+		},
+	}
+}
+
+// priceModel is a regression stump on size
+func priceModel() *t.Model {
+	return &t.Model{
+		FeatureNames: []string{"size", "price"},
+		FeatureTypes: map[string]string{"size": "numerical"},
+		TargetName:   "price",
+		Task:         t.Regression,
+		Root: &t.Node{
+			Feature:    "size",
+			Continuous: true,
+			Threshold:  80,
+			Samples:    10,
+			Mean:       250,
+			Children: []*t.Node{
+				{IsLeaf: true, Samples: 6, Mean: 150},
+				{IsLeaf: true, Samples: 4, Mean: 400},
+			},
+		},
+	}
+}
+
+func newTestServer(tt *testing.T, options Options) *Server {
+	tt.Cleanup(func() { os.RemoveAll("./decision_model") })
+	require.NoError(tt, m.SaveModel(loanModel(52000), "loan.json"))
+	require.NoError(tt, m.SaveModel(priceModel(), "price.json"))
+
+	s, err := New([]string{"loan.json", "price.json"}, options)
+	require.NoError(tt, err)
+	return s
+}
+
+func request(tt *testing.T, handler http.Handler, method, path, body string) (int, map[string]interface{}) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var response map[string]interface{}
+	require.NoError(tt, json.Unmarshal(rec.Body.Bytes(), &response), rec.Body.String())
+	return rec.Code, response
+}
+
+func TestPredict(tt *testing.T) {
+	handler := newTestServer(tt, Options{}).Handler()
+
+	status, response := request(tt, handler, http.MethodPost, "/v1/models/loan/predict", `{"instance": {"income": 60000, "region": "EU"}}`)
+	assert.Equal(tt, http.StatusOK, status)
+	assert.Equal(tt, "approve", response["prediction"])
+	assert.Nil(tt, response["probabilities"])
+
+	status, response = request(tt, handler, http.MethodPost, "/v1/models/loan/predict?probabilities=true&explain=true", `{"instance": {"income": "30000"}}`)
+	assert.Equal(tt, http.StatusOK, status)
+	assert.Equal(tt, "reject", response["prediction"])
+	assert.Equal(tt, map[string]interface{}{"approve": 0.25, "reject": 0.75}, response["probabilities"])
+	explanation := response["explanation"].(map[string]interface{})
+	assert.Equal(tt, "income <= 52000", explanation["path"].([]interface{})[0].(map[string]interface{})["test"])
+
+	status, response = request(tt, handler, http.MethodPost, "/v1/models/price/predict", `{"instance": {"size": 120}}`)
+	assert.Equal(tt, http.StatusOK, status)
+	assert.Equal(tt, 400.0, response["prediction"])
+}
+
+func TestPredictBatch(tt *testing.T) {
+	handler := newTestServer(tt, Options{}).Handler()
+
+	status, response := request(tt, handler, http.MethodPost, "/v1/models/loan/predict/batch?probabilities=1",
+		`{"instances": [{"income": 60000}, {"income": 30000}, {"income": null}]}`)
+	assert.Equal(tt, http.StatusOK, status)
+	predictions := response["predictions"].([]interface{})
+	assert.Len(tt, predictions, 3)
+	assert.Equal(tt, "approve", predictions[0].(map[string]interface{})["prediction"])
+	assert.Equal(tt, "reject", predictions[1].(map[string]interface{})["prediction"])
+	assert.NotNil(tt, predictions[2].(map[string]interface{})["probabilities"])
+}
+
+func TestMetadataAndHealth(tt *testing.T) {
+	handler := newTestServer(tt, Options{}).Handler()
+
+	status, response := request(tt, handler, http.MethodGet, "/healthz", "")
+	assert.Equal(tt, http.StatusOK, status)
+	assert.Equal(tt, "ok", response["status"])
+	assert.Equal(tt, 2.0, response["models"])
+
+	status, response = request(tt, handler, http.MethodGet, "/v1/models/loan", "")
+	assert.Equal(tt, http.StatusOK, status)
+	assert.Equal(tt, "classification", response["task"])
+	assert.Equal(tt, "label", response["target"])
+	assert.Equal(tt, []interface{}{"income", "region"}, response["features"])
+	assert.Equal(tt, []interface{}{"approve", "reject"}, response["classes"])
+
+	status, response = request(tt, handler, http.MethodGet, "/v1/models", "")
+	assert.Equal(tt, http.StatusOK, status)
+	assert.Len(tt, response["models"], 2)
+}
+
+func TestErrors(tt *testing.T) {
+	handler := newTestServer(tt, Options{MaxBodyBytes: 64}).Handler()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"Unknown model", http.MethodPost, "/v1/models/other/predict", `{"instance": {}}`, http.StatusNotFound},
+		{"Invalid JSON", http.MethodPost, "/v1/models/loan/predict", `{"instance": `, http.StatusBadRequest},
+		{"Unknown field", http.MethodPost, "/v1/models/loan/predict", `{"row": {}}`, http.StatusBadRequest},
+		{"Missing instance", http.MethodPost, "/v1/models/loan/predict", `{}`, http.StatusBadRequest},
+		{"Invalid option", http.MethodPost, "/v1/models/loan/predict?explain=maybe", `{"instance": {}}`, http.StatusBadRequest},
+		{"Invalid number", http.MethodPost, "/v1/models/loan/predict", `{"instance": {"income": "lots"}}`, http.StatusBadRequest},
+		{"Invalid batch number", http.MethodPost, "/v1/models/loan/predict/batch", `{"instances": [{"income": "lots"}]}`, http.StatusBadRequest},
+		{"Body too large", http.MethodPost, "/v1/models/loan/predict", `{"instance": {"region": "` + strings.Repeat("x", 100) + `"}}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, tc := range tests {
+		tt.Run(tc.name, func(tt *testing.T) {
+			status, response := request(tt, handler, tc.method, tc.path, tc.body)
+			assert.Equal(tt, tc.status, status)
+			assert.NotEmpty(tt, response["error"])
+		})
+	}
+}
+
+func TestReload(tt *testing.T) {
+	s := newTestServer(tt, Options{})
+	handler := s.Handler()
+
+	_, response := request(tt, handler, http.MethodPost, "/v1/models/loan/predict", `{"instance": {"income": 60000}}`)
+	assert.Equal(tt, "approve", response["prediction"])
+
+	// A file that does not parse keeps the loaded model
+	future := time.Now().Add(time.Hour)
+	require.NoError(tt, os.WriteFile(m.ModelPath("loan.json"), []byte("{"), 0o644))
+	require.NoError(tt, os.Chtimes(m.ModelPath("loan.json"), future, future))
+	s.Reload()
+	_, response = request(tt, handler, http.MethodPost, "/v1/models/loan/predict", `{"instance": {"income": 60000}}`)
+	assert.Equal(tt, "approve", response["prediction"])
+
+	// A fixed file is loaded even when its modification time did not change
+	require.NoError(tt, m.SaveModel(loanModel(70000), "loan.json"))
+	require.NoError(tt, os.Chtimes(m.ModelPath("loan.json"), future, future))
+	s.Reload()
+	_, response = request(tt, handler, http.MethodPost, "/v1/models/loan/predict", `{"instance": {"income": 60000}}`)
+	assert.Equal(tt, "reject", response["prediction"])
+}
+
+func TestListenAndServe_GracefulShutdown(tt *testing.T) {
+	s := newTestServer(tt, Options{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go s.Watch(ctx, 10*time.Millisecond)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.ListenAndServe(ctx, "127.0.0.1:0")
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(tt, err)
+	case <-time.After(5 * time.Second):
+		tt.Fatal("server did not shut down")
+	}
+}
+
+func TestNew_Errors(tt *testing.T) {
+	tt.Cleanup(func() { os.RemoveAll("./decision_model") })
+	_, err := New(nil, Options{})
+	assert.Error(tt, err)
+	_, err = New([]string{"missing.json"}, Options{})
+	assert.Error(tt, err)
+
+	require.NoError(tt, m.SaveModel(loanModel(52000), "loan.json"))
+	require.NoError(tt, os.MkdirAll("./decision_model/v2", 0o755))
+	var buf bytes.Buffer
+	require.NoError(tt, json.NewEncoder(&buf).Encode(loanModel(52000)))
+	require.NoError(tt, os.WriteFile("./decision_model/v2/loan.json", buf.Bytes(), 0o644))
+	_, err = New([]string{"loan.json", "v2/loan.json"}, Options{})
+	assert.Error(tt, err)
+}
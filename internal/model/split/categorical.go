@@ -3,9 +3,11 @@ package split
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/nyunja/c4.5-decision-tree/internal/model/counter"
 	"github.com/nyunja/c4.5-decision-tree/internal/model/entropy"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
 )
 
 // EvaluateCategoricalFeature evaluates a categorical feature
@@ -17,32 +19,35 @@ func EvaluateCategoricalFeature(feature string, context SplitContext) SplitResul
 	if len(valueCounts) == 0 {
 		return SplitResult{
 			Feature:      feature,
-			GainRatio:    0,
+			Score:        0,
 			IsContinuous: false,
 		}
 	}
 
-	// Calculate information gain
-	infoGain := context.BaseEntropy
-	splitInfo := 0.0
-
 	// Create counters for each value
 	valueCounters := CreateValueCounters(feature, context, valueCounts)
+	if context.Options.BinaryCategorical && len(context.ParentCounter.Counts) <= 2 {
+		return EvaluateBinarySplit(feature, context, valueCounters)
+	}
+	if context.Options.SubsetGrouping {
+		return EvaluateSubsetSplit(feature, context, valueCounters)
+	}
 
-	// Calculate information gain and split info
-	for _, counter := range valueCounters {
-		infoGain, splitInfo = entropy.GainInfoAndSplitInfo(counter, context.Instances, infoGain, splitInfo)
+	values := make([]string, 0, len(valueCounters))
+	for value := range valueCounters {
+		values = append(values, value)
 	}
+	sort.Strings(values)
 
-	// Calculate gain ratio
-	gainRatio := 0.0
-	if splitInfo > 0 {
-		gainRatio = infoGain / splitInfo
+	children := make([]*counter.ClassCounter, 0, len(valueCounters))
+	for _, value := range values {
+		children = append(children, valueCounters[value])
 	}
 
 	return SplitResult{
 		Feature:      feature,
-		GainRatio:    gainRatio,
+		Gain:         entropy.InformationGain(context.ParentCounter, children),
+		Score:        context.Options.criterion().Score(context.ParentCounter, children, 0),
 		IsContinuous: false,
 	}
 }
@@ -68,7 +73,7 @@ func CreateValueCounters(feature string, context SplitContext,
 		targetVal := fmt.Sprintf("%v", instance[context.TargetFeature])
 
 		if counter, ok := valueCounters[strVal]; ok {
-			counter.Add(targetVal)
+			counter.AddWeighted(targetVal, utils.InstanceWeight(instance, context.Options.WeightFeature))
 		}
 	}
 
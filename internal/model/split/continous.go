@@ -3,10 +3,12 @@ package split
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/nyunja/c4.5-decision-tree/internal/model/counter"
 	"github.com/nyunja/c4.5-decision-tree/internal/model/entropy"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
 )
 
 // EvaluateContinuousFeature evaluates a continuous feature for the best split point
@@ -18,35 +20,84 @@ func EvaluateContinuousFeature(feature string, context SplitContext) SplitResult
 	if len(sortedValues) <= 1 {
 		return SplitResult{
 			Feature:      feature,
-			GainRatio:    0,
+			Score:        0,
 			IsContinuous: true,
 		}
 	}
 
-	// Find the best split point
-	bestGainRatio := 0.0
+	// Find the best split point. Gain based criteria pick the threshold by
+	// raw information gain, as Release 8 does, and only the chosen split is
+	// penalised and divided by its split info.
+	criterion := context.Options.criterion()
+	bestScore := 0.0
 	bestThreshold := 0.0
+	var bestChildren []*counter.ClassCounter
 
 	for i := 0; i < len(sortedValues)-1; i++ {
 		threshold := (sortedValues[i] + sortedValues[i+1]) / 2
-		gainRatio, leftCount, rightCount := EvaluateThreshold(feature, threshold, context)
+		leftCounter, rightCounter := EvaluateThreshold(feature, threshold, context)
+		children := []*counter.ClassCounter{leftCounter, rightCounter}
+		score := thresholdScore(criterion, context.ParentCounter, children)
 
-		if gainRatio > bestGainRatio && IsSplitValid(leftCount, rightCount) {
-			bestGainRatio = gainRatio
+		if score > bestScore && IsSplitValid(leftCounter.Total, rightCounter.Total) {
+			bestScore = score
 			bestThreshold = threshold
+			bestChildren = children
 		}
 	}
 
+	if bestChildren == nil {
+		return SplitResult{
+			Feature:      feature,
+			Score:        0,
+			IsContinuous: true,
+		}
+	}
+
+	// Penalise the gain for having chosen among many thresholds
+	penalty := 0.0
+	if context.Options.MDLCorrection {
+		penalty = ThresholdCost(feature, context)
+	}
+
 	return SplitResult{
 		Feature:      feature,
-		GainRatio:    bestGainRatio,
+		Gain:         entropy.InformationGain(context.ParentCounter, bestChildren) - penalty,
+		Score:        criterion.Score(context.ParentCounter, bestChildren, penalty),
 		IsContinuous: true,
 		Threshold:    bestThreshold,
 	}
 }
 
-// EvaluateThreshold evaluates a specific threshold for a continuous feature
-func EvaluateThreshold(feature string, threshold float64, context SplitContext) (float64, int, int) {
+// thresholdScore ranks the thresholds of a continuous feature: by information
+// gain for gain based criteria, by the criterion itself otherwise
+func thresholdScore(criterion Criterion, parent *counter.ClassCounter, children []*counter.ClassCounter) float64 {
+	switch criterion.(type) {
+	case GainRatio, InformationGain:
+		return entropy.InformationGain(parent, children)
+	}
+	return criterion.Score(parent, children, 0)
+}
+
+// ThresholdCost returns the Release 8 MDL penalty log2(N-1)/|D|, where N is the
+// number of distinct values of the feature among the instances being split
+// and |D| their weighted total, the scale the gain is computed on
+func ThresholdCost(feature string, context SplitContext) float64 {
+	distinct := make(map[float64]bool)
+	for _, instance := range context.Instances {
+		if floatVal, ok := ExtractNumericValue(instance[feature]); ok {
+			distinct[floatVal] = true
+		}
+	}
+
+	if len(distinct) < 2 || context.ParentCounter == nil || context.ParentCounter.Total == 0 {
+		return 0
+	}
+	return math.Log2(float64(len(distinct)-1)) / context.ParentCounter.Total
+}
+
+// EvaluateThreshold counts the target classes on each side of a threshold
+func EvaluateThreshold(feature string, threshold float64, context SplitContext) (*counter.ClassCounter, *counter.ClassCounter) {
 	// Count instances on each side of the threshold
 	leftCounter := counter.NewClassCounter()
 	rightCounter := counter.NewClassCounter()
@@ -63,29 +114,15 @@ func EvaluateThreshold(feature string, threshold float64, context SplitContext)
 		}
 
 		targetVal := fmt.Sprintf("%v", instance[context.TargetFeature])
+		weight := utils.InstanceWeight(instance, context.Options.WeightFeature)
 		if floatVal <= threshold {
-			leftCounter.Add(targetVal)
+			leftCounter.AddWeighted(targetVal, weight)
 		} else {
-			rightCounter.Add(targetVal)
+			rightCounter.AddWeighted(targetVal, weight)
 		}
 	}
 
-	// Calculate information gain
-	leftProb := float64(leftCounter.Total) / float64(len(context.Instances))
-	rightProb := float64(rightCounter.Total) / float64(len(context.Instances))
-
-	infoGain := context.BaseEntropy
-	if leftCounter.Total > 0 {
-		infoGain -= leftProb * leftCounter.GetEntropy()
-	}
-	if rightCounter.Total > 0 {
-		infoGain -= rightProb * rightCounter.GetEntropy()
-	}
-
-	// Calculate the gain ratio
-	gainRatio := entropy.GainRatio(leftProb, rightProb, infoGain)
-
-	return gainRatio, leftCounter.Total, rightCounter.Total
+	return leftCounter, rightCounter
 }
 
 // ExtractNumericValue converts various types to float64 for comparison
@@ -102,8 +139,8 @@ func ExtractNumericValue(val interface{}) (float64, bool) {
 	}
 }
 
-// IsSplitValid checks if a split is valid based on left and right counts
-func IsSplitValid(leftCount, rightCount int) bool {
+// IsSplitValid checks if a split is valid based on left and right (weighted) counts
+func IsSplitValid(leftCount, rightCount float64) bool {
 	// You can add minimum size constraints here if needed
 	return leftCount > 0 && rightCount > 0
 }
@@ -0,0 +1,154 @@
+// split/criterion.go
+package split
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/counter"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/entropy"
+)
+
+// Criterion scores a candidate partition of the instances at a node
+type Criterion interface {
+	// Name is the identifier used by TrainOptions and the CLI
+	Name() string
+
+	// Score rates splitting parent into children; higher is better and a score
+	// of zero or less means the split is not worth making. penalty is the
+	// Release 8 MDL cost in bits and only affects gain based criteria.
+	Score(parent *counter.ClassCounter, children []*counter.ClassCounter, penalty float64) float64
+}
+
+// GainRatio is the C4.5 criterion: information gain divided by split info
+type GainRatio struct{}
+
+// InformationGain is the ID3 criterion: the reduction in entropy
+type InformationGain struct{}
+
+// Gini is the CART criterion: the reduction in Gini impurity
+type Gini struct{}
+
+// ChiSquare scores the class/branch contingency table with Pearson's chi-square
+// statistic, or the G-statistic when GStatistic is set. Statistics are turned
+// into Wilson-Hilferty z-scores so splits with different degrees of freedom compare.
+type ChiSquare struct {
+	GStatistic bool
+}
+
+// Criteria lists the names accepted by CriterionByName
+var Criteria = []string{"gain-ratio", "info-gain", "gini", "chi-square", "g-statistic"}
+
+// CriterionByName returns the criterion registered under name
+func CriterionByName(name string) (Criterion, error) {
+	switch name {
+	case "gain-ratio":
+		return GainRatio{}, nil
+	case "info-gain":
+		return InformationGain{}, nil
+	case "gini":
+		return Gini{}, nil
+	case "chi-square":
+		return ChiSquare{}, nil
+	case "g-statistic":
+		return ChiSquare{GStatistic: true}, nil
+	default:
+		return nil, fmt.Errorf("unknown split criterion '%s', expected one of %v", name, Criteria)
+	}
+}
+
+func (GainRatio) Name() string { return "gain-ratio" }
+
+func (GainRatio) Score(parent *counter.ClassCounter, children []*counter.ClassCounter, penalty float64) float64 {
+	infoGain := entropy.InformationGain(parent, children) - penalty
+	splitInfo := entropy.SplitInfo(parent, children)
+	if infoGain <= 0 || splitInfo <= 0 {
+		return 0
+	}
+	return infoGain / splitInfo
+}
+
+func (InformationGain) Name() string { return "info-gain" }
+
+func (InformationGain) Score(parent *counter.ClassCounter, children []*counter.ClassCounter, penalty float64) float64 {
+	return entropy.InformationGain(parent, children) - penalty
+}
+
+func (Gini) Name() string { return "gini" }
+
+func (Gini) Score(parent *counter.ClassCounter, children []*counter.ClassCounter, _ float64) float64 {
+	if parent.Total == 0 {
+		return 0
+	}
+
+	decrease := parent.GetGini()
+	for _, child := range children {
+		decrease -= child.Total / parent.Total * child.GetGini()
+	}
+	return decrease
+}
+
+func (c ChiSquare) Name() string {
+	if c.GStatistic {
+		return "g-statistic"
+	}
+	return "chi-square"
+}
+
+func (c ChiSquare) Score(_ *counter.ClassCounter, children []*counter.ClassCounter, _ float64) float64 {
+	// Column totals over the instances that reached a branch
+	classTotals := make(map[string]float64)
+	total := 0.0
+	rows := 0
+	for _, child := range children {
+		if child.Total == 0 {
+			continue
+		}
+		rows++
+		total += child.Total
+		for class, count := range child.Counts {
+			classTotals[class] += count
+		}
+	}
+
+	dof := (rows - 1) * (len(classTotals) - 1)
+	if dof <= 0 {
+		return 0
+	}
+
+	classes := make([]string, 0, len(classTotals))
+	for class := range classTotals {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+
+	statistic := 0.0
+	for _, child := range children {
+		for _, class := range classes {
+			classTotal := classTotals[class]
+			expected := child.Total * classTotal / total
+			if expected == 0 {
+				continue
+			}
+			observed := child.Counts[class]
+			if c.GStatistic {
+				if observed > 0 {
+					statistic += 2 * observed * math.Log(observed/expected)
+				}
+			} else {
+				statistic += (observed - expected) * (observed - expected) / expected
+			}
+		}
+	}
+
+	return WilsonHilferty(statistic, dof)
+}
+
+// WilsonHilferty converts a chi-square distributed statistic with dof degrees
+// of freedom into an approximately standard normal z-score
+func WilsonHilferty(statistic float64, dof int) float64 {
+	k := float64(dof)
+	variance := 2 / (9 * k)
+	return (math.Cbrt(statistic/k) - (1 - variance)) / math.Sqrt(variance)
+}
@@ -0,0 +1,105 @@
+package split
+
+import (
+	"math"
+	"testing"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/cache"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/counter"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/fixtures"
+)
+
+// newCounter builds a class counter from class -> count pairs
+func newCounter(counts map[string]int) *counter.ClassCounter {
+	c := counter.NewClassCounter()
+	for class, count := range counts {
+		for i := 0; i < count; i++ {
+			c.Add(class)
+		}
+	}
+	return c
+}
+
+func TestCriterionScores(t *testing.T) {
+	// Outlook on the golf dataset: sunny 2/3, overcast 4/0, rainy 3/2
+	parent := newCounter(map[string]int{"yes": 9, "no": 5})
+	children := []*counter.ClassCounter{
+		newCounter(map[string]int{"yes": 2, "no": 3}),
+		newCounter(map[string]int{"yes": 4}),
+		newCounter(map[string]int{"yes": 3, "no": 2}),
+	}
+
+	tests := []struct {
+		name      string
+		criterion Criterion
+		want      float64
+	}{
+		{"Information gain", InformationGain{}, 0.246749819774439},
+		{"Gain ratio", GainRatio{}, 0.156427562421175},
+		{"Gini", Gini{}, 0.116326530612245},
+		// Pearson chi-square 3.547 and G 4.789 on 2 degrees of freedom
+		{"Chi-square", ChiSquare{}, WilsonHilferty(3.546666666666666, 2)},
+		{"G-statistic", ChiSquare{GStatistic: true}, WilsonHilferty(4.788950372649158, 2)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.criterion.Score(parent, children, 0); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("%s.Score() = %v, want %v", tt.criterion.Name(), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCriterionScores_Penalty(t *testing.T) {
+	parent := newCounter(map[string]int{"yes": 2, "no": 2})
+	children := []*counter.ClassCounter{
+		newCounter(map[string]int{"yes": 2}),
+		newCounter(map[string]int{"no": 2}),
+	}
+
+	if got := (InformationGain{}).Score(parent, children, 0.25); math.Abs(got-0.75) > 1e-12 {
+		t.Errorf("InformationGain.Score() with penalty = %v, want 0.75", got)
+	}
+	if got := (GainRatio{}).Score(parent, children, 2); got != 0 {
+		t.Errorf("GainRatio.Score() with penalty exceeding gain = %v, want 0", got)
+	}
+	if got := (Gini{}).Score(parent, children, 2); got != 0.5 {
+		t.Errorf("Gini.Score() should ignore the penalty, got %v", got)
+	}
+}
+
+func TestCriterionByName(t *testing.T) {
+	for _, name := range Criteria {
+		criterion, err := CriterionByName(name)
+		if err != nil {
+			t.Fatalf("CriterionByName(%q) returned error: %v", name, err)
+		}
+		if criterion.Name() != name {
+			t.Errorf("CriterionByName(%q).Name() = %q", name, criterion.Name())
+		}
+	}
+
+	if _, err := CriterionByName("entropy"); err == nil {
+		t.Error("CriterionByName() should reject unknown names")
+	}
+}
+
+func TestFindBestSplitWithOptions_Criteria(t *testing.T) {
+	instances := fixtures.Golf()
+	features := []string{"outlook", "windy"}
+	featureTypes := map[string]string{"outlook": "categorical", "windy": "categorical"}
+	featureCache := cache.NewFeatureCache()
+	featureCache.PrecomputeFeatureValues(instances, features, "play", featureTypes)
+
+	for _, name := range Criteria {
+		t.Run(name, func(t *testing.T) {
+			criterion, _ := CriterionByName(name)
+			options := Options{Criterion: criterion}
+			result := FindBestSplitWithOptions(instances, features, "play", featureTypes, map[string]bool{}, featureCache, options)
+			if result.Feature != "outlook" {
+				t.Errorf("FindBestSplitWithOptions() with %s feature = %v, want outlook", name, result.Feature)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+// split/regression.go
+package split
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/counter"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
+)
+
+// RegressionCriterion scores a candidate partition of a numeric target
+type RegressionCriterion interface {
+	// Name is the identifier used by TrainOptions and the CLI
+	Name() string
+
+	// Score rates splitting parent into children; higher is better and a
+	// score of zero or less means the split is not worth making
+	Score(parent *counter.TargetStats, children []*counter.TargetStats) float64
+}
+
+// VarianceReduction is the CART regression criterion
+type VarianceReduction struct{}
+
+// SDReduction is the M5 criterion: the reduction in standard deviation
+type SDReduction struct{}
+
+// RegressionCriteria lists the names accepted by RegressionCriterionByName
+var RegressionCriteria = []string{"variance", "sd"}
+
+// RegressionCriterionByName returns the regression criterion registered under name
+func RegressionCriterionByName(name string) (RegressionCriterion, error) {
+	switch name {
+	case "variance":
+		return VarianceReduction{}, nil
+	case "sd":
+		return SDReduction{}, nil
+	default:
+		return nil, fmt.Errorf("unknown regression criterion '%s', expected one of %v", name, RegressionCriteria)
+	}
+}
+
+func (VarianceReduction) Name() string { return "variance" }
+
+func (VarianceReduction) Score(parent *counter.TargetStats, children []*counter.TargetStats) float64 {
+	if parent.Weight == 0 {
+		return 0
+	}
+
+	reduction := parent.Variance()
+	for _, child := range children {
+		reduction -= child.Weight / parent.Weight * child.Variance()
+	}
+	return reduction
+}
+
+func (SDReduction) Name() string { return "sd" }
+
+func (SDReduction) Score(parent *counter.TargetStats, children []*counter.TargetStats) float64 {
+	if parent.Weight == 0 {
+		return 0
+	}
+
+	reduction := parent.StdDev()
+	for _, child := range children {
+		reduction -= child.Weight / parent.Weight * child.StdDev()
+	}
+	return reduction
+}
+
+// TargetValue returns the numeric target of an instance
+func TargetValue(instance t.Instance, targetFeature string) (float64, bool) {
+	return ExtractNumericValue(instance[targetFeature])
+}
+
+// EvaluateRegressionFeature evaluates a feature against a numeric target
+func EvaluateRegressionFeature(feature string, context SplitContext) SplitResult {
+	if IsContinuousFeature(context.FeatureTypes[feature]) {
+		return evaluateRegressionThresholds(feature, context)
+	}
+	return evaluateRegressionCategories(feature, context)
+}
+
+// evaluateRegressionThresholds finds the best threshold of a continuous feature
+func evaluateRegressionThresholds(feature string, context SplitContext) SplitResult {
+	context.Cache.Mu.RLock()
+	sortedValues := context.Cache.SortedValues[feature]
+	context.Cache.Mu.RUnlock()
+
+	criterion := context.Options.Regression
+	bestScore := 0.0
+	bestThreshold := 0.0
+	found := false
+
+	for i := 0; i < len(sortedValues)-1; i++ {
+		threshold := (sortedValues[i] + sortedValues[i+1]) / 2
+		left, right := counter.NewTargetStats(), counter.NewTargetStats()
+
+		for _, instance := range context.Instances {
+			floatVal, ok := ExtractNumericValue(instance[feature])
+			if !ok {
+				continue
+			}
+			target, ok := TargetValue(instance, context.TargetFeature)
+			if !ok {
+				continue
+			}
+			weight := utils.InstanceWeight(instance, context.Options.WeightFeature)
+			if floatVal <= threshold {
+				left.Add(target, weight)
+			} else {
+				right.Add(target, weight)
+			}
+		}
+
+		score := criterion.Score(context.ParentStats, []*counter.TargetStats{left, right})
+		if score > bestScore && IsSplitValid(left.Weight, right.Weight) {
+			bestScore = score
+			bestThreshold = threshold
+			found = true
+		}
+	}
+
+	if !found {
+		return SplitResult{Feature: feature, IsContinuous: true}
+	}
+
+	return SplitResult{
+		Feature:      feature,
+		Gain:         bestScore,
+		Score:        bestScore,
+		IsContinuous: true,
+		Threshold:    bestThreshold,
+	}
+}
+
+// evaluateRegressionCategories scores one child per value, or a binary split
+// of the values ordered by their mean target when BinaryCategorical is set
+func evaluateRegressionCategories(feature string, context SplitContext) SplitResult {
+	valueStats := make(map[string]*counter.TargetStats)
+	for _, instance := range context.Instances {
+		val := instance[feature]
+		if val == nil {
+			continue
+		}
+		target, ok := TargetValue(instance, context.TargetFeature)
+		if !ok {
+			continue
+		}
+
+		strVal := fmt.Sprintf("%v", val)
+		if valueStats[strVal] == nil {
+			valueStats[strVal] = counter.NewTargetStats()
+		}
+		valueStats[strVal].Add(target, utils.InstanceWeight(instance, context.Options.WeightFeature))
+	}
+
+	if len(valueStats) < 2 {
+		return SplitResult{Feature: feature}
+	}
+
+	values := make([]string, 0, len(valueStats))
+	for value := range valueStats {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	criterion := context.Options.Regression
+	if !context.Options.BinaryCategorical {
+		children := make([]*counter.TargetStats, len(values))
+		for i, value := range values {
+			children[i] = valueStats[value]
+		}
+		score := criterion.Score(context.ParentStats, children)
+		return SplitResult{Feature: feature, Gain: score, Score: score}
+	}
+
+	// The best binary partition is a prefix of the values ordered by mean
+	sort.SliceStable(values, func(i, j int) bool {
+		return valueStats[values[i]].Mean() < valueStats[values[j]].Mean()
+	})
+	known := counter.NewTargetStats()
+	for _, value := range values {
+		known = known.Merge(valueStats[value])
+	}
+
+	left := counter.NewTargetStats()
+	bestScore := math.Inf(-1)
+	bestPrefix := 0
+	for i := 0; i < len(values)-1; i++ {
+		left = left.Merge(valueStats[values[i]])
+		score := criterion.Score(context.ParentStats, []*counter.TargetStats{left, known.Subtract(left)})
+		if score > bestScore {
+			bestScore = score
+			bestPrefix = i + 1
+		}
+	}
+
+	groups := [][]string{
+		append([]string{}, values[:bestPrefix]...),
+		append([]string{}, values[bestPrefix:]...),
+	}
+	sort.Strings(groups[0])
+	sort.Strings(groups[1])
+
+	return SplitResult{
+		Feature: feature,
+		Gain:    bestScore,
+		Score:   bestScore,
+		Groups:  groups,
+		Binary:  true,
+	}
+}
@@ -0,0 +1,101 @@
+package split
+
+import (
+	"math"
+	"testing"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/cache"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/counter"
+	test "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+)
+
+func newStats(values ...float64) *counter.TargetStats {
+	stats := counter.NewTargetStats()
+	for _, value := range values {
+		stats.Add(value, 1)
+	}
+	return stats
+}
+
+func TestRegressionCriteria(t *testing.T) {
+	parent := newStats(1, 1, 5, 5)
+	perfect := []*counter.TargetStats{newStats(1, 1), newStats(5, 5)}
+	useless := []*counter.TargetStats{newStats(1, 5), newStats(1, 5)}
+
+	tests := []struct {
+		criterion RegressionCriterion
+		children  []*counter.TargetStats
+		want      float64
+	}{
+		{VarianceReduction{}, perfect, 4},
+		{VarianceReduction{}, useless, 0},
+		{SDReduction{}, perfect, 2},
+		{SDReduction{}, useless, 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.criterion.Score(parent, tt.children); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("%s.Score() = %v, want %v", tt.criterion.Name(), got, tt.want)
+		}
+	}
+}
+
+func TestRegressionCriterionByName(t *testing.T) {
+	for _, name := range RegressionCriteria {
+		criterion, err := RegressionCriterionByName(name)
+		if err != nil || criterion.Name() != name {
+			t.Errorf("RegressionCriterionByName(%q) = %v, %v", name, criterion, err)
+		}
+	}
+	if _, err := RegressionCriterionByName("mse"); err == nil {
+		t.Error("Expected an error for an unknown regression criterion")
+	}
+}
+
+func TestFindBestSplitWithOptions_Regression(t *testing.T) {
+	instances := []test.Instance{
+		{"size": 1.0, "zone": "a", "price": 10.0},
+		{"size": 2.0, "zone": "b", "price": 11.0},
+		{"size": 3.0, "zone": "a", "price": 12.0},
+		{"size": 10.0, "zone": "b", "price": 50.0},
+		{"size": 11.0, "zone": "a", "price": 52.0},
+		{"size": 12.0, "zone": "b", "price": 51.0},
+	}
+	featureTypes := map[string]string{"size": "numerical", "zone": "categorical", "price": "numerical"}
+	features := []string{"size", "zone"}
+
+	featureCache := cache.NewFeatureCache()
+	featureCache.PrecomputeFeatureValues(instances, features, "price", featureTypes)
+
+	for _, criterion := range []RegressionCriterion{VarianceReduction{}, SDReduction{}} {
+		options := DefaultOptions()
+		options.Regression = criterion
+
+		result := FindBestSplitWithOptions(instances, features, "price", featureTypes, map[string]bool{}, featureCache, options)
+		if result.Feature != "size" || !result.IsContinuous || result.Threshold != 6.5 {
+			t.Errorf("%s: got split %+v, want size <= 6.5", criterion.Name(), result)
+		}
+	}
+}
+
+func TestEvaluateRegressionFeature_BinaryCategorical(t *testing.T) {
+	instances := []test.Instance{
+		{"zone": "a", "price": 10.0},
+		{"zone": "b", "price": 50.0},
+		{"zone": "c", "price": 12.0},
+		{"zone": "a", "price": 11.0},
+		{"zone": "b", "price": 52.0},
+		{"zone": "c", "price": 13.0},
+	}
+	options := Options{Regression: VarianceReduction{}, BinaryCategorical: true}
+	context := CreateSplitContextWithOptions(instances, []string{"zone"}, "price",
+		map[string]string{"zone": "categorical", "price": "numerical"}, map[string]bool{}, cache.NewFeatureCache(), options)
+
+	result := EvaluateRegressionFeature("zone", context)
+	if !result.Binary || len(result.Groups) != 2 {
+		t.Fatalf("Expected a binary split, got %+v", result)
+	}
+	if got := result.Groups[0]; len(got) != 2 || got[0] != "a" || got[1] != "c" {
+		t.Errorf("Expected the low-price group [a c], got %v", got)
+	}
+}
@@ -2,41 +2,111 @@
 package split
 
 import (
+	"fmt"
+	"math"
+	"math/rand/v2"
 	"runtime"
+	"sort"
 	"sync"
 
 	"github.com/nyunja/c4.5-decision-tree/internal/model/cache"
-	"github.com/nyunja/c4.5-decision-tree/internal/model/entropy"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/counter"
 	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
 )
 
-// FindBestSplit finds the best feature and split point using the feature cache
+// epsilon is the tolerance C4.5 uses when comparing gains
+const epsilon = 1e-3
+
+// FindBestSplit finds the best feature and split point using the feature cache.
+// It scores splits with the Release 8 defaults of DefaultOptions; use
+// FindBestSplitWithOptions for other criteria and settings.
 func FindBestSplit(instances []t.Instance, features []string, targetFeature string,
 	featureTypes map[string]string, excludedFeatures map[string]bool,
 	cache *cache.FeatureCache,
 ) (string, interface{}, bool, float64) {
+	result := FindBestSplitWithOptions(instances, features, targetFeature, featureTypes, excludedFeatures, cache, DefaultOptions())
+	return result.Feature, result.Value, result.IsContinuous, result.Threshold
+}
+
+// FindBestSplitWithOptions finds the best split using the given scoring options.
+// An empty Feature in the result means no useful split was found.
+func FindBestSplitWithOptions(instances []t.Instance, features []string, targetFeature string,
+	featureTypes map[string]string, excludedFeatures map[string]bool,
+	cache *cache.FeatureCache, options Options,
+) SplitResult {
 	if len(instances) == 0 || len(features) == 0 {
-		return "", nil, false, 0
+		return SplitResult{}
 	}
 
-	context := CreateSplitContext(instances, features, targetFeature, featureTypes, excludedFeatures, cache)
+	if options.FeatureSubset > 0 {
+		features = SampleFeatures(features, targetFeature, excludedFeatures, options.FeatureSubset, options.Rand)
+	}
+
+	context := CreateSplitContextWithOptions(instances, features, targetFeature, featureTypes, excludedFeatures, cache, options)
 
 	// Start the parallel evaluation process
 	result := EvaluateFeaturesInParallel(context)
 
-	if result.GainRatio <= 0 {
-		return "", nil, false, 0
+	if result.Score <= 0 {
+		return SplitResult{}
 	}
 
-	return result.Feature, result.Value, result.IsContinuous, result.Threshold
+	return result
+}
+
+// SampleFeatures draws n of the candidate features without replacement,
+// leaving out the target and excluded features. The sample keeps the order
+// of features and holds every candidate when there are at most n.
+func SampleFeatures(features []string, targetFeature string, excludedFeatures map[string]bool, n int, rng *rand.Rand) []string {
+	candidates := make([]string, 0, len(features))
+	for _, feature := range features {
+		if feature != targetFeature && !excludedFeatures[feature] {
+			candidates = append(candidates, feature)
+		}
+	}
+	if n >= len(candidates) {
+		return candidates
+	}
+
+	perm := rand.Perm
+	if rng != nil {
+		perm = rng.Perm
+	}
+	picked := perm(len(candidates))[:n]
+	sort.Ints(picked)
+
+	sample := make([]string, n)
+	for i, index := range picked {
+		sample[i] = candidates[index]
+	}
+	return sample
 }
 
-// CreateSplitContext prepares the context needed for split evaluation
+// CreateSplitContext prepares the context needed for split evaluation with
+// the default options
 func CreateSplitContext(instances []t.Instance, features []string, targetFeature string,
 	featureTypes map[string]string, excludedFeatures map[string]bool,
 	cache *cache.FeatureCache,
 ) SplitContext {
-	baseEntropy := entropy.CalculateEntropy(instances, targetFeature)
+	return CreateSplitContextWithOptions(instances, features, targetFeature, featureTypes, excludedFeatures, cache, DefaultOptions())
+}
+
+// CreateSplitContextWithOptions prepares the context needed for split evaluation with the given options
+func CreateSplitContextWithOptions(instances []t.Instance, features []string, targetFeature string,
+	featureTypes map[string]string, excludedFeatures map[string]bool,
+	cache *cache.FeatureCache, options Options,
+) SplitContext {
+	parentCounter := counter.NewClassCounter()
+	parentStats := counter.NewTargetStats()
+	for _, instance := range instances {
+		weight := utils.InstanceWeight(instance, options.WeightFeature)
+		if options.Regression == nil {
+			parentCounter.AddWeighted(fmt.Sprintf("%v", instance[targetFeature]), weight)
+		} else if target, ok := TargetValue(instance, targetFeature); ok {
+			parentStats.Add(target, weight)
+		}
+	}
 
 	return SplitContext{
 		Instances:        instances,
@@ -45,7 +115,10 @@ func CreateSplitContext(instances []t.Instance, features []string, targetFeature
 		FeatureTypes:     featureTypes,
 		ExcludedFeatures: excludedFeatures,
 		Cache:            cache,
-		BaseEntropy:      baseEntropy,
+		BaseEntropy:      parentCounter.GetEntropy(),
+		ParentCounter:    parentCounter,
+		ParentStats:      parentStats,
+		Options:          options,
 	}
 }
 
@@ -76,21 +149,69 @@ func EvaluateFeaturesInParallel(context SplitContext) SplitResult {
 		close(resultsChan)
 	}()
 
+	// Put the results back in feature order, so ties do not depend on scheduling
+	position := make(map[string]int, len(context.Features))
+	for i, feature := range context.Features {
+		position[feature] = i
+	}
+	results := make([]SplitResult, 0, len(context.Features))
+	for result := range resultsChan {
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return position[results[i].Feature] < position[results[j].Feature]
+	})
+
 	// Find the best split
-	return FindBestResult(resultsChan)
+	return BestResult(results, context.Options)
 }
 
 // FindBestResult collects all results and finds the best one
-func FindBestResult(resultsChan <-chan SplitResult) SplitResult {
-	bestResult := SplitResult{GainRatio: -1}
+func FindBestResult(resultsChan <-chan SplitResult, options Options) SplitResult {
+	results := make([]SplitResult, 0)
 	for result := range resultsChan {
-		if result.GainRatio > bestResult.GainRatio {
+		results = append(results, result)
+	}
+	return BestResult(results, options)
+}
+
+// BestResult returns the result with the highest score among those admitted
+// by the average gain filter. Ties go to the earliest result.
+func BestResult(results []SplitResult, options Options) SplitResult {
+	minGain := math.Inf(-1)
+	if _, isGainRatio := options.criterion().(GainRatio); isGainRatio && options.AverageGainFilter && options.Regression == nil {
+		minGain = AverageGain(results) - epsilon
+	}
+
+	bestResult := SplitResult{Score: math.Inf(-1)}
+	for _, result := range results {
+		if result.Gain < minGain {
+			continue
+		}
+		if result.Score > bestResult.Score {
 			bestResult = result
 		}
 	}
 	return bestResult
 }
 
+// AverageGain returns the mean gain of the results with a positive gain,
+// which C4.5 uses as the admission threshold before comparing gain ratios
+func AverageGain(results []SplitResult) float64 {
+	total := 0.0
+	possible := 0
+	for _, result := range results {
+		if result.Gain > epsilon {
+			total += result.Gain
+			possible++
+		}
+	}
+	if possible == 0 {
+		return 0
+	}
+	return total / float64(possible)
+}
+
 // FeatureEvaluationWorker evaluates features from the channel
 func FeatureEvaluationWorker(wg *sync.WaitGroup, featuresChan <-chan string,
 	resultsChan chan<- SplitResult, context SplitContext,
@@ -104,7 +225,9 @@ func FeatureEvaluationWorker(wg *sync.WaitGroup, featuresChan <-chan string,
 		}
 
 		featureType := context.FeatureTypes[feature]
-		if IsContinuousFeature(featureType) {
+		if context.Options.Regression != nil {
+			resultsChan <- EvaluateRegressionFeature(feature, context)
+		} else if IsContinuousFeature(featureType) {
 			resultsChan <- EvaluateContinuousFeature(feature, context)
 		} else {
 			resultsChan <- EvaluateCategoricalFeature(feature, context)
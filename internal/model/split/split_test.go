@@ -1,10 +1,15 @@
 package split
 
 import (
+	"math"
+	"math/rand/v2"
 	"reflect"
 	"testing"
 
 	"github.com/nyunja/c4.5-decision-tree/internal/model/cache"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/counter"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/entropy"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/fixtures"
 	test "github.com/nyunja/c4.5-decision-tree/internal/model/types"
 )
 
@@ -19,6 +24,15 @@ func TestFindBestSplit(t *testing.T) {
 		{"feature1": "B", "feature2": 20.0, "target": "No"},
 	}
 
+	// Only the lowest threshold separates a class, which the Release 8
+	// penalty on continuous gains still leaves worth splitting on
+	numericInstances := []test.Instance{
+		{"feature1": "A", "feature2": 5.0, "target": "Yes"},
+		{"feature1": "B", "feature2": 10.0, "target": "No"},
+		{"feature1": "A", "feature2": 15.0, "target": "No"},
+		{"feature1": "B", "feature2": 20.0, "target": "No"},
+	}
+
 	// Define feature types
 	featureTypes := map[string]string{
 		"feature1": "categorical",
@@ -63,7 +77,7 @@ func TestFindBestSplit(t *testing.T) {
 		},
 		{
 			name:             "Best split is numerical",
-			instances:        instances,
+			instances:        numericInstances,
 			features:         []string{"feature2"},
 			targetFeature:    "target",
 			featureTypes:     featureTypes,
@@ -123,3 +137,174 @@ func TestFindBestSplit(t *testing.T) {
 		})
 	}
 }
+
+func TestFindBestSplitWithOptions_Golf(t *testing.T) {
+	instances := fixtures.Golf()
+	features := fixtures.GolfFeatures
+	featureTypes := fixtures.GolfTypes()
+	featureCache := cache.NewFeatureCache()
+	featureCache.PrecomputeFeatureValues(instances, features, "play", featureTypes)
+
+	tests := []struct {
+		name        string
+		options     Options
+		wantFeature string
+	}{
+		// Without the penalty a temperature threshold beats outlook
+		{"Raw gain ratio", Options{}, "temperature"},
+		{"MDL correction only", Options{MDLCorrection: true}, "outlook"},
+		{"Reference C4.5", DefaultOptions(), "outlook"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := FindBestSplitWithOptions(instances, features, "play", featureTypes, map[string]bool{}, featureCache, tt.options)
+			if result.Feature != tt.wantFeature {
+				t.Errorf("FindBestSplitWithOptions() feature = %v, want %v", result.Feature, tt.wantFeature)
+			}
+		})
+	}
+}
+
+func TestEvaluateContinuousFeature_RanksThresholdsByGain(t *testing.T) {
+	// Classes A A A A A B A B over the values 1 to 8: x <= 5.5 has the most
+	// gain, x <= 7.5 the highest gain ratio
+	instances := make([]test.Instance, 0, 8)
+	for i, class := range []string{"A", "A", "A", "A", "A", "B", "A", "B"} {
+		instances = append(instances, test.Instance{"x": float64(i + 1), "target": class})
+	}
+	featureTypes := map[string]string{"x": "numerical", "target": "categorical"}
+	featureCache := cache.NewFeatureCache()
+	featureCache.PrecomputeFeatureValues(instances, []string{"x"}, "target", featureTypes)
+
+	for _, options := range []Options{{}, DefaultOptions()} {
+		context := CreateSplitContextWithOptions(instances, []string{"x"}, "target", featureTypes, map[string]bool{}, featureCache, options)
+		result := EvaluateContinuousFeature("x", context)
+		if result.Threshold != 5.5 {
+			t.Errorf("EvaluateContinuousFeature() threshold = %v, want 5.5 (MDL correction %v)", result.Threshold, options.MDLCorrection)
+		}
+		if result.Score <= 0 {
+			t.Errorf("EvaluateContinuousFeature() score = %v, want a positive gain ratio", result.Score)
+		}
+	}
+}
+
+func TestThresholdCost(t *testing.T) {
+	instances := fixtures.Golf()
+	context := CreateSplitContext(instances, fixtures.GolfFeatures, fixtures.GolfTarget, fixtures.GolfTypes(), nil, nil)
+
+	// Humidity has 10 distinct values over 14 instances
+	want := math.Log2(9) / 14
+	if got := ThresholdCost("humidity", context); math.Abs(got-want) > 1e-12 {
+		t.Errorf("ThresholdCost() = %v, want %v", got, want)
+	}
+
+	if got := ThresholdCost("outlook", context); got != 0 {
+		t.Errorf("ThresholdCost() for non-numeric feature = %v, want 0", got)
+	}
+}
+
+func TestThresholdCost_Weighted(t *testing.T) {
+	instances := fixtures.Golf()
+	for i, instance := range instances {
+		// Weights of 1 and 3 total 28 over the 14 instances
+		instance["weight"] = float64(1 + 2*(i%2))
+	}
+	options := DefaultOptions()
+	options.WeightFeature = "weight"
+	featureCache := cache.NewFeatureCache()
+	featureCache.PrecomputeFeatureValues(instances, fixtures.GolfFeatures, fixtures.GolfTarget, fixtures.GolfTypes())
+	context := CreateSplitContextWithOptions(instances, fixtures.GolfFeatures, fixtures.GolfTarget, fixtures.GolfTypes(), nil, featureCache, options)
+
+	// The penalty is on the scale of the weighted gain it is subtracted from
+	want := math.Log2(9) / 28
+	if got := ThresholdCost("humidity", context); math.Abs(got-want) > 1e-12 {
+		t.Errorf("ThresholdCost() = %v, want %v", got, want)
+	}
+
+	result := EvaluateContinuousFeature("humidity", context)
+	left, right := EvaluateThreshold("humidity", result.Threshold, context)
+	gain := entropy.InformationGain(context.ParentCounter, []*counter.ClassCounter{left, right})
+	if math.Abs(result.Gain-(gain-want)) > 1e-12 {
+		t.Errorf("EvaluateContinuousFeature() gain = %v, want %v less the penalty %v", result.Gain, gain, want)
+	}
+}
+
+func TestFindBestResult_AverageGainFilter(t *testing.T) {
+	results := []SplitResult{
+		{Feature: "low_gain", Gain: 0.05, Score: 0.9},
+		{Feature: "high_gain", Gain: 0.6, Score: 0.4},
+		{Feature: "negative", Gain: -0.2, Score: 0},
+	}
+
+	tests := []struct {
+		name        string
+		options     Options
+		wantFeature string
+	}{
+		{"Without filter", Options{}, "low_gain"},
+		{"With filter", Options{AverageGainFilter: true}, "high_gain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resultsChan := make(chan SplitResult, len(results))
+			for _, result := range results {
+				resultsChan <- result
+			}
+			close(resultsChan)
+
+			if got := FindBestResult(resultsChan, tt.options); got.Feature != tt.wantFeature {
+				t.Errorf("FindBestResult() feature = %v, want %v", got.Feature, tt.wantFeature)
+			}
+		})
+	}
+
+	if got, want := AverageGain(results), 0.325; math.Abs(got-want) > 1e-12 {
+		t.Errorf("AverageGain() = %v, want %v", got, want)
+	}
+}
+
+func TestSampleFeatures(t *testing.T) {
+	features := []string{"a", "b", "target", "c", "d", "skip"}
+	excluded := map[string]bool{"skip": true}
+	rng := rand.New(rand.NewPCG(1, 0))
+
+	for i := 0; i < 20; i++ {
+		sample := SampleFeatures(features, "target", excluded, 2, rng)
+		if len(sample) != 2 || sample[0] >= sample[1] {
+			t.Fatalf("Expected two features in order, got %v", sample)
+		}
+		for _, feature := range sample {
+			if feature == "target" || feature == "skip" {
+				t.Fatalf("Sampled ineligible feature %q", feature)
+			}
+		}
+	}
+
+	if got := SampleFeatures(features, "target", excluded, 10, rng); !reflect.DeepEqual(got, []string{"a", "b", "c", "d"}) {
+		t.Errorf("Expected every candidate, got %v", got)
+	}
+}
+
+func TestFindBestSplitWithOptions_TiesGoToFeatureOrder(t *testing.T) {
+	// Both features separate the classes perfectly
+	instances := []test.Instance{
+		{"zeta": "x", "alpha": "p", "target": "yes"},
+		{"zeta": "x", "alpha": "p", "target": "yes"},
+		{"zeta": "y", "alpha": "q", "target": "no"},
+		{"zeta": "y", "alpha": "q", "target": "no"},
+	}
+	featureTypes := map[string]string{"zeta": "categorical", "alpha": "categorical"}
+	featureCache := cache.NewFeatureCache()
+	featureCache.PrecomputeFeatureValues(instances, []string{"zeta", "alpha"}, "target", featureTypes)
+
+	for _, features := range [][]string{{"zeta", "alpha"}, {"alpha", "zeta"}} {
+		for i := 0; i < 20; i++ {
+			result := FindBestSplitWithOptions(instances, features, "target", featureTypes, map[string]bool{}, featureCache, DefaultOptions())
+			if result.Feature != features[0] {
+				t.Fatalf("Expected the tie to go to %q, got %q", features[0], result.Feature)
+			}
+		}
+	}
+}
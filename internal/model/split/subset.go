@@ -0,0 +1,248 @@
+// split/subset.go
+package split
+
+import (
+	"math"
+	"sort"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/counter"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/entropy"
+)
+
+// maxSubsetGroups bounds the number of groups entering the greedy merge,
+// which costs O(k^3) in the number of groups k
+const maxSubsetGroups = 64
+
+// valueGroup is a set of categorical values and the classes seen with them
+type valueGroup struct {
+	values  []string
+	counter *counter.ClassCounter
+}
+
+// EvaluateSubsetSplit groups the values of a categorical feature by greedy
+// gain-ratio merging, as C4.5 does with its subset option. It starts from one
+// group per value, repeatedly applies the merge giving the best gain ratio and
+// keeps the best partition seen with at least two groups.
+func EvaluateSubsetSplit(feature string, context SplitContext, valueCounters map[string]*counter.ClassCounter) SplitResult {
+	groups := initialGroups(valueCounters)
+	if len(groups) < 2 {
+		return SplitResult{Feature: feature}
+	}
+
+	parent := context.ParentCounter
+	total := parent.Total
+	baseEntropy := parent.GetEntropy()
+
+	// The info and split info of a partition are sums of per group terms
+	infoTerm := func(c *counter.ClassCounter) float64 {
+		return c.Total / total * c.GetEntropy()
+	}
+	splitTerm := func(c *counter.ClassCounter) float64 {
+		prob := c.Total / total
+		return -prob * math.Log2(prob)
+	}
+	ratio := func(info, splitInfo float64) float64 {
+		if splitInfo <= 0 {
+			return 0
+		}
+		return (baseEntropy - info) / splitInfo
+	}
+
+	info, splitInfo := 0.0, 0.0
+	for _, group := range groups {
+		info += infoTerm(group.counter)
+		splitInfo += splitTerm(group.counter)
+	}
+
+	best := cloneGroups(groups)
+	bestRatio := ratio(info, splitInfo)
+
+	for len(groups) > 2 {
+		mergeI, mergeJ := -1, -1
+		var merged *counter.ClassCounter
+		mergedRatio := math.Inf(-1)
+		mergedInfo, mergedSplitInfo := 0.0, 0.0
+
+		for i := 0; i < len(groups); i++ {
+			for j := i + 1; j < len(groups); j++ {
+				candidate := mergeCounters(groups[i].counter, groups[j].counter)
+				candidateInfo := info - infoTerm(groups[i].counter) - infoTerm(groups[j].counter) + infoTerm(candidate)
+				candidateSplitInfo := splitInfo - splitTerm(groups[i].counter) - splitTerm(groups[j].counter) + splitTerm(candidate)
+				if r := ratio(candidateInfo, candidateSplitInfo); r > mergedRatio {
+					mergeI, mergeJ = i, j
+					merged = candidate
+					mergedRatio = r
+					mergedInfo, mergedSplitInfo = candidateInfo, candidateSplitInfo
+				}
+			}
+		}
+
+		groups[mergeI] = valueGroup{
+			values:  append(append([]string{}, groups[mergeI].values...), groups[mergeJ].values...),
+			counter: merged,
+		}
+		groups = append(groups[:mergeJ], groups[mergeJ+1:]...)
+		info, splitInfo = mergedInfo, mergedSplitInfo
+
+		if mergedRatio > bestRatio {
+			best = cloneGroups(groups)
+			bestRatio = mergedRatio
+		}
+	}
+
+	return groupedResult(feature, context, best, false)
+}
+
+// EvaluateBinarySplit finds the best "value in S / not in S" split for a
+// binary target. Values are ordered by the proportion of the first class,
+// after which the optimal subset is one of the k-1 prefixes of that order.
+func EvaluateBinarySplit(feature string, context SplitContext, valueCounters map[string]*counter.ClassCounter) SplitResult {
+	groups := make([]valueGroup, 0, len(valueCounters))
+	for value, valueCounter := range valueCounters {
+		if valueCounter.Total > 0 {
+			groups = append(groups, valueGroup{values: []string{value}, counter: valueCounter})
+		}
+	}
+	if len(groups) < 2 {
+		return SplitResult{Feature: feature}
+	}
+
+	classes := make([]string, 0, len(context.ParentCounter.Counts))
+	for class := range context.ParentCounter.Counts {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	firstClass := classes[0]
+
+	proportion := func(g valueGroup) float64 {
+		return g.counter.Counts[firstClass] / g.counter.Total
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if pi, pj := proportion(groups[i]), proportion(groups[j]); pi != pj {
+			return pi < pj
+		}
+		return groups[i].values[0] < groups[j].values[0]
+	})
+
+	criterion := context.Options.criterion()
+	known := mergeGroups(groups).counter
+	left := counter.NewClassCounter()
+	bestScore := math.Inf(-1)
+	bestPrefix := 0
+	for i := 0; i < len(groups)-1; i++ {
+		left = mergeCounters(left, groups[i].counter)
+		right := subtractCounters(known, left)
+		if score := criterion.Score(context.ParentCounter, []*counter.ClassCounter{left, right}, 0); score > bestScore {
+			bestScore = score
+			bestPrefix = i + 1
+		}
+	}
+
+	return groupedResult(feature, context, []valueGroup{
+		mergeGroups(groups[:bestPrefix]),
+		mergeGroups(groups[bestPrefix:]),
+	}, true)
+}
+
+// groupedResult scores a partition of values with the configured criterion
+func groupedResult(feature string, context SplitContext, groups []valueGroup, binary bool) SplitResult {
+	children := make([]*counter.ClassCounter, len(groups))
+	valueGroups := make([][]string, len(groups))
+	for i, group := range groups {
+		children[i] = group.counter
+		valueGroups[i] = append([]string{}, group.values...)
+		sort.Strings(valueGroups[i])
+	}
+
+	return SplitResult{
+		Feature:      feature,
+		Gain:         entropy.InformationGain(context.ParentCounter, children),
+		Score:        context.Options.criterion().Score(context.ParentCounter, children, 0),
+		IsContinuous: false,
+		Groups:       valueGroups,
+		Binary:       binary,
+	}
+}
+
+// initialGroups creates one group per value seen at the node. Values whose
+// instances all share one class are pooled per class first, since merging
+// them never loses gain, and the rarest groups are pooled if too many remain.
+func initialGroups(valueCounters map[string]*counter.ClassCounter) []valueGroup {
+	values := make([]string, 0, len(valueCounters))
+	for value, valueCounter := range valueCounters {
+		if valueCounter.Total > 0 {
+			values = append(values, value)
+		}
+	}
+	sort.Strings(values)
+
+	groups := make([]valueGroup, 0, len(values))
+	pure := make(map[string]int)
+	for _, value := range values {
+		valueCounter := valueCounters[value]
+		if len(valueCounter.Counts) == 1 {
+			class := valueCounter.GetMajorityClass()
+			if idx, ok := pure[class]; ok {
+				groups[idx].values = append(groups[idx].values, value)
+				groups[idx].counter = mergeCounters(groups[idx].counter, valueCounter)
+				continue
+			}
+			pure[class] = len(groups)
+		}
+		groups = append(groups, valueGroup{values: []string{value}, counter: valueCounter})
+	}
+
+	if len(groups) > maxSubsetGroups {
+		sort.SliceStable(groups, func(i, j int) bool {
+			return groups[i].counter.Total > groups[j].counter.Total
+		})
+		rest := mergeGroups(groups[maxSubsetGroups-1:])
+		groups = append(groups[:maxSubsetGroups-1], rest)
+	}
+
+	return groups
+}
+
+// mergeGroups combines several groups into one
+func mergeGroups(groups []valueGroup) valueGroup {
+	merged := valueGroup{counter: counter.NewClassCounter()}
+	for _, group := range groups {
+		merged.values = append(merged.values, group.values...)
+		merged.counter = mergeCounters(merged.counter, group.counter)
+	}
+	return merged
+}
+
+// mergeCounters returns a new counter holding the counts of both counters
+func mergeCounters(a, b *counter.ClassCounter) *counter.ClassCounter {
+	merged := counter.NewClassCounter()
+	for class, count := range a.Counts {
+		merged.Counts[class] += count
+	}
+	for class, count := range b.Counts {
+		merged.Counts[class] += count
+	}
+	merged.Total = a.Total + b.Total
+	return merged
+}
+
+// subtractCounters returns a new counter holding the counts of a minus those of b
+func subtractCounters(a, b *counter.ClassCounter) *counter.ClassCounter {
+	remaining := counter.NewClassCounter()
+	for class, count := range a.Counts {
+		if count -= b.Counts[class]; count > 0 {
+			remaining.Counts[class] = count
+		}
+	}
+	remaining.Total = a.Total - b.Total
+	return remaining
+}
+
+// cloneGroups copies a partition so later merges do not alter it
+func cloneGroups(groups []valueGroup) []valueGroup {
+	cloned := make([]valueGroup, len(groups))
+	for i, group := range groups {
+		cloned[i] = valueGroup{values: append([]string{}, group.values...), counter: group.counter}
+	}
+	return cloned
+}
@@ -0,0 +1,133 @@
+package split
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nyunja/c4.5-decision-tree/internal/model/cache"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/counter"
+	test "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+)
+
+// zipInstances returns a high-cardinality feature where codes zip0-zip9 lean
+// towards "A" and zip10-zip19 towards "B"
+func zipInstances() []test.Instance {
+	instances := []test.Instance{}
+	for i := 0; i < 20; i++ {
+		zip := fmt.Sprintf("zip%d", i)
+		majority, minority := "A", "B"
+		if i >= 10 {
+			majority, minority = "B", "A"
+		}
+		instances = append(instances,
+			test.Instance{"zip": zip, "target": majority},
+			test.Instance{"zip": zip, "target": majority},
+			test.Instance{"zip": zip, "target": majority},
+			test.Instance{"zip": zip, "target": minority},
+		)
+	}
+	return instances
+}
+
+func zipContext(instances []test.Instance, options Options) SplitContext {
+	featureTypes := map[string]string{"zip": "categorical"}
+	featureCache := cache.NewFeatureCache()
+	featureCache.PrecomputeFeatureValues(instances, []string{"zip"}, "target", featureTypes)
+	context := CreateSplitContext(instances, []string{"zip"}, "target", featureTypes, map[string]bool{}, featureCache)
+	context.Options = options
+	return context
+}
+
+// groupOf returns the index of the group holding value, or -1
+func groupOf(groups [][]string, value string) int {
+	for i, group := range groups {
+		for _, v := range group {
+			if v == value {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func TestEvaluateSubsetSplit(t *testing.T) {
+	instances := zipInstances()
+	context := zipContext(instances, Options{SubsetGrouping: true})
+
+	multiway := EvaluateCategoricalFeature("zip", zipContext(instances, Options{}))
+	result := EvaluateCategoricalFeature("zip", context)
+
+	if len(result.Groups) != 2 {
+		t.Fatalf("EvaluateSubsetSplit() produced %d groups, want 2: %v", len(result.Groups), result.Groups)
+	}
+	if result.Score <= multiway.Score {
+		t.Errorf("Subset gain ratio %v should beat one child per value %v", result.Score, multiway.Score)
+	}
+
+	// Codes leaning the same way end up in the same group
+	for i := 1; i < 20; i++ {
+		same := groupOf(result.Groups, "zip0") == groupOf(result.Groups, fmt.Sprintf("zip%d", i))
+		if same != (i < 10) {
+			t.Errorf("zip%d grouped with zip0 = %v, want %v", i, same, i < 10)
+		}
+	}
+}
+
+func TestEvaluateBinarySplit(t *testing.T) {
+	instances := []test.Instance{
+		{"colour": "red", "target": "yes"},
+		{"colour": "red", "target": "yes"},
+		{"colour": "blue", "target": "no"},
+		{"colour": "blue", "target": "no"},
+		{"colour": "green", "target": "yes"},
+		{"colour": "green", "target": "no"},
+		{"colour": "black", "target": "no"},
+	}
+	featureTypes := map[string]string{"colour": "categorical"}
+	featureCache := cache.NewFeatureCache()
+	featureCache.PrecomputeFeatureValues(instances, []string{"colour"}, "target", featureTypes)
+
+	result := FindBestSplitWithOptions(instances, []string{"colour"}, "target", featureTypes, map[string]bool{}, featureCache, Options{BinaryCategorical: true})
+
+	if !result.Binary || len(result.Groups) != 2 {
+		t.Fatalf("FindBestSplitWithOptions() = %+v, want a binary split", result)
+	}
+	// Values are ordered by the share of "no" and the subset is a prefix of that order
+	if groupOf(result.Groups, "red") != 0 || groupOf(result.Groups, "black") != 1 || groupOf(result.Groups, "blue") != 1 {
+		t.Errorf("Unexpected binary groups %v", result.Groups)
+	}
+}
+
+func TestInitialGroups_PoolsPureValues(t *testing.T) {
+	valueCounters := map[string]*counter.ClassCounter{}
+	for i := 0; i < 200; i++ {
+		valueCounters[fmt.Sprintf("id%d", i)] = newCounter(map[string]int{[]string{"A", "B"}[i%2]: 1})
+	}
+	valueCounters["mixed"] = newCounter(map[string]int{"A": 1, "B": 1})
+	valueCounters["unseen"] = newCounter(map[string]int{})
+
+	// One group per class for the pure values plus the mixed value
+	if groups := initialGroups(valueCounters); len(groups) != 3 {
+		t.Errorf("initialGroups() = %d groups, want 3", len(groups))
+	}
+}
+
+func TestInitialGroups_CapsGroupCount(t *testing.T) {
+	valueCounters := map[string]*counter.ClassCounter{}
+	for i := 0; i < 100; i++ {
+		valueCounters[fmt.Sprintf("v%d", i)] = newCounter(map[string]int{"A": 1, "B": 1 + i%3})
+	}
+
+	groups := initialGroups(valueCounters)
+	if len(groups) != maxSubsetGroups {
+		t.Fatalf("initialGroups() = %d groups, want %d", len(groups), maxSubsetGroups)
+	}
+
+	values := 0
+	for _, group := range groups {
+		values += len(group.values)
+	}
+	if values != 100 {
+		t.Errorf("initialGroups() kept %d values, want 100", values)
+	}
+}
@@ -2,7 +2,10 @@
 package split
 
 import (
+	"math/rand/v2"
+
 	"github.com/nyunja/c4.5-decision-tree/internal/model/cache"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/counter"
 	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
 )
 
@@ -10,9 +13,74 @@ import (
 type SplitResult struct {
 	Feature      string
 	Value        interface{}
-	GainRatio    float64
+	Gain         float64
+	Score        float64
 	IsContinuous bool
 	Threshold    float64
+
+	// Groups partitions the values of a categorical feature, one group per
+	// child. It is nil for the default one child per value split.
+	Groups [][]string
+	// Binary marks a "value in Groups[0] / not in Groups[0]" split
+	Binary bool
+}
+
+// Options controls how candidate splits are scored and selected
+type Options struct {
+	// Criterion scores candidate splits, gain ratio when nil
+	Criterion Criterion
+
+	// MDLCorrection subtracts log2(N-1)/|D| from the gain of continuous
+	// thresholds, where N is the number of distinct values (C4.5 Release 8).
+	// Only gain based criteria are affected.
+	MDLCorrection bool
+
+	// AverageGainFilter only considers features whose gain is at least the
+	// average gain of all candidate features before picking by gain ratio.
+	// It has no effect with other criteria.
+	AverageGainFilter bool
+
+	// SubsetGrouping merges categorical values into groups by greedy
+	// gain-ratio merging instead of creating one child per value (C4.5 -s)
+	SubsetGrouping bool
+
+	// BinaryCategorical splits categorical features into "value in S / not
+	// in S" using class-ordered values when the node has two classes
+	BinaryCategorical bool
+
+	// Regression scores splits against a numeric target when set, in
+	// which case Criterion, the Release 8 corrections and SubsetGrouping
+	// are not used
+	Regression RegressionCriterion
+
+	// WeightFeature names the column holding instance weights. Every
+	// instance weighs 1 when it is empty.
+	WeightFeature string
+
+	// FeatureSubset evaluates only this many randomly drawn features at
+	// each node, as random forests do. All features are used when it is 0.
+	FeatureSubset int
+
+	// Rand draws the feature subsets, the global source when nil. It is not
+	// safe for concurrent use, so every tree needs its own.
+	Rand *rand.Rand
+}
+
+// criterion returns the configured criterion, defaulting to gain ratio
+func (o Options) criterion() Criterion {
+	if o.Criterion == nil {
+		return GainRatio{}
+	}
+	return o.Criterion
+}
+
+// DefaultOptions returns the options matching the reference C4.5 Release 8
+func DefaultOptions() Options {
+	return Options{
+		Criterion:         GainRatio{},
+		MDLCorrection:     true,
+		AverageGainFilter: true,
+	}
 }
 
 // SplitContext holds the context data needed for split evaluation
@@ -24,4 +92,7 @@ type SplitContext struct {
 	ExcludedFeatures map[string]bool
 	Cache            *cache.FeatureCache
 	BaseEntropy      float64
+	ParentCounter    *counter.ClassCounter
+	ParentStats      *counter.TargetStats
+	Options          Options
 }
@@ -1,5 +1,10 @@
 package model
 
+import (
+	"math"
+	"sort"
+)
+
 // Node represents a node in the decision tree
 type Node struct {
 	Feature    string      `json:"feature,omitempty"`
@@ -9,6 +14,22 @@ type Node struct {
 	Children   []*Node     `json:"children,omitempty"`
 	Continuous bool        `json:"continuous,omitempty"`
 	Threshold  float64     `json:"threshold,omitempty"`
+	Values     []string    `json:"values,omitempty"` // categorical values grouped into this child
+	Binary     bool        `json:"binary,omitempty"` // values not in the first child's Values go to the second
+
+	ClassCounts map[string]float64 `json:"class_counts,omitempty"` // weighted class distribution of the training instances
+	Samples     float64            `json:"samples,omitempty"`      // total weight of the training instances
+	Gain        float64            `json:"gain,omitempty"`         // gain (or variance reduction) of the split at a decision node
+
+	// Regression trees predict Mean, or LinearModel at leaves that have one
+	Mean        float64      `json:"mean,omitempty"`
+	LinearModel *LinearModel `json:"linear_model,omitempty"`
+}
+
+// LinearModel is a least-squares fit of the target on numeric features
+type LinearModel struct {
+	Intercept    float64            `json:"intercept"`
+	Coefficients map[string]float64 `json:"coefficients"`
 }
 
 // Model represents the trained decision tree model
@@ -17,18 +38,149 @@ type Model struct {
 	FeatureTypes map[string]string `json:"feature_types"` // categorical, numerical, date, timestamp
 	FeatureNames []string          `json:"feature_names"`
 	TargetName   string            `json:"target_name"`
+	Task         string            `json:"task,omitempty"` // classification (default) or regression
+	WeightColumn string            `json:"weight_column,omitempty"`
+	Costs        CostMatrix        `json:"costs,omitempty"`
+	// ClassOrder breaks ties between classes, as it did at the leaves: the
+	// classes from most to least frequent in the training data
+	ClassOrder []string `json:"class_order,omitempty"`
+
+	// Ensemble holds the trees of ensemble models, in which case Root is nil
+	Ensemble *Ensemble `json:"ensemble,omitempty"`
+
+	// ExcludedColumns are the columns left out of training, with why
+	ExcludedColumns []ColumnNote `json:"excluded_columns,omitempty"`
+	// Warnings are columns kept for training that may be a problem, such as
+	// possible target leakage
+	Warnings []ColumnNote `json:"warnings,omitempty"`
+
+	// Reading records how the training input was read, so the files the
+	// model predicts are read the same way
+	Reading *Reading `json:"reading,omitempty"`
+}
+
+// Reading is how the values of an input were read, as declared by its schema
+type Reading struct {
+	// Missing are the values read as missing in every column
+	Missing []string `json:"missing,omitempty"`
+	// ColumnMissing are the values read as missing in single columns
+	ColumnMissing map[string][]string `json:"column_missing,omitempty"`
+	// DateFormats are the Go layouts of the dates of columns
+	DateFormats map[string]string `json:"date_formats,omitempty"`
+}
+
+// ColumnNote is a column with the reason it was excluded or flagged
+type ColumnNote struct {
+	Column string `json:"column"`
+	Reason string `json:"reason"`
+}
+
+// Ensemble holds the trees of an ensemble model and how their votes are combined
+type Ensemble struct {
+	Method      string    `json:"method"`           // forest, bag or boost
+	Voting      string    `json:"voting,omitempty"` // majority (default) or probability
+	Trees       []*Node   `json:"trees"`
+	Weights     []float64 `json:"weights,omitempty"`      // vote of each tree, 1 when empty (boosting alphas)
+	MaxFeatures int       `json:"max_features,omitempty"` // features drawn at each node of a forest
+	Seed        int64     `json:"seed"`                   // seed of the random draws, to reproduce the model
+
+	// OOBError is the out-of-bag misclassification rate, or the out-of-bag
+	// mean squared error for regression
+	OOBError float64 `json:"oob_error,omitempty"`
+	// ValidationError is the misclassification rate on the held-out split
+	// used for early stopping
+	ValidationError float64 `json:"validation_error,omitempty"`
+}
+
+// Task names
+const (
+	Classification = "classification"
+	Regression     = "regression"
+)
+
+// Ensemble methods and voting schemes
+const (
+	Forest = "forest"
+	Bag    = "bag"
+	Boost  = "boost"
+
+	MajorityVote    = "majority"
+	ProbabilityVote = "probability"
+)
+
+// CostMatrix holds misclassification costs as actual class -> predicted class -> cost
+type CostMatrix map[string]map[string]float64
+
+// Cost returns the cost of predicting predicted for an instance of class actual.
+// Pairs missing from the matrix cost 0 when correct and 1 otherwise.
+func (c CostMatrix) Cost(actual, predicted string) float64 {
+	if cost, ok := c[actual][predicted]; ok {
+		return cost
+	}
+	if actual == predicted {
+		return 0
+	}
+	return 1
+}
+
+// MinExpectedCostClass returns the class with the lowest expected cost under
+// the (weighted) class distribution. Ties go to the alphabetically first class.
+func (c CostMatrix) MinExpectedCostClass(distribution map[string]float64) string {
+	candidates := make(map[string]bool, len(distribution))
+	for class := range distribution {
+		candidates[class] = true
+	}
+	for actual, row := range c {
+		candidates[actual] = true
+		for predicted := range row {
+			candidates[predicted] = true
+		}
+	}
+
+	classes := make([]string, 0, len(candidates))
+	for class := range candidates {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+
+	actuals := make([]string, 0, len(distribution))
+	for actual := range distribution {
+		actuals = append(actuals, actual)
+	}
+	sort.Strings(actuals)
+
+	bestClass := ""
+	bestCost := math.Inf(1)
+	for _, predicted := range classes {
+		expected := 0.0
+		for _, actual := range actuals {
+			expected += distribution[actual] * c.Cost(actual, predicted)
+		}
+		if expected < bestCost {
+			bestCost = expected
+			bestClass = predicted
+		}
+	}
+
+	return bestClass
 }
 
 type Instance map[string]interface{}
 
 // ColumnStats stores statistics about a column
 type ColumnStats struct {
-	Min           float64
-	Max           float64
-	Sum           float64
-	Count         int
-	UniqueValues  map[string]int
-	IsNumeric     bool
+	Min          float64
+	Max          float64
+	Sum          float64
+	Count        int
+	Missing      int
+	UniqueValues map[string]int
+	IsNumeric    bool
+	// IsInteger is set while every numeric value is a whole number
+	IsInteger bool
+	// Integers holds the distinct values of an integer column, without the
+	// cap of UniqueValues, so IDs can be told from repeated codes
+	Integers      map[int64]bool
 	IsDate        bool
 	IsTimestamp   bool
 	IsCategorical bool
@@ -78,44 +78,61 @@ func IsTimestampValue(value string) bool {
 	return err2 == nil
 }
 
+// InstanceWeight returns the weight of an instance stored in weightFeature.
+// Instances weigh 1 when no weight column is used or the value is missing.
+// Training rejects weights that are not finite, non-negative numbers before
+// any are read.
+func InstanceWeight(instance t.Instance, weightFeature string) float64 {
+	if weightFeature == "" {
+		return 1
+	}
+
+	switch v := instance[weightFeature].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	default:
+		return 1
+	}
+}
+
 // convertPredictionRecordToInstance converts a CSV record to an Instance object for prediction
 func ConvertPredictionRecordToInstance(record []string, headers []string, featureTypes map[string]string) t.Instance {
-	instance := make(t.Instance, len(headers))
-
-	for i, value := range record {
-		header := headers[i]
-
-		// Convert value based on feature type
-		switch featureTypes[header] {
-		case "numerical":
-			if value == "" {
-				instance[header] = nil
-			} else {
-				floatVal, err := strconv.ParseFloat(value, 64)
-				if err != nil {
-					instance[header] = value
-				} else {
-					instance[header] = floatVal
-				}
-			}
-		case "date":
-			if value == "" {
-				instance[header] = nil
-			} else {
-				instance[header] = ConvertToDateValue(value)
-			}
-		case "timestamp":
-			if value == "" {
-				instance[header] = nil
-			} else {
-				instance[header] = ConvertToTimestampValue(value)
-			}
-		default:
-			instance[header] = value
-		}
+	return ConvertRecordToInstance(record, headers, featureTypes)
+}
+
+// ConvertValue converts a value read as text to its feature type. Empty
+// values are missing and convert to nil. Values that do not convert also
+// give nil, and false.
+func ConvertValue(value string, featureType string) (interface{}, bool) {
+	if value == "" {
+		return nil, true
 	}
 
-	return instance
+	// Convert value based on feature type
+	switch featureType {
+	case "numerical":
+		floatVal, err := ConvertStringToNumerical(value)
+		if err != nil {
+			return nil, false
+		}
+		return floatVal, true
+	case "date":
+		dateVal, err := ConvertStringToDate(value)
+		if err != nil {
+			return nil, false
+		}
+		return *dateVal, true
+	case "timestamp":
+		timeVal, err := ConvertStringToTimestamp(value)
+		if err != nil {
+			return nil, false
+		}
+		return *timeVal, true
+	default:
+		return value, true
+	}
 }
 
 // convertToDateValue converts a string to a date value
@@ -4,11 +4,21 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode"
 
 	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
 )
 
-// detectIDColumns identifies columns that are likely to be IDs or indexes
+// idRatio is the share of its non-missing rows an integer column must have
+// distinct values for to be taken as an ID, and the share of the integers
+// between its smallest and largest value they must cover
+const idRatio = 0.9
+
+// detectIDColumns identifies columns that are likely to be IDs or indexes:
+// columns named as one, and integer columns shaped as one, with almost a
+// value per row and nearly consecutive values like row numbers. Continuous
+// numbers and integer measurements spread over a wide range, such as
+// incomes, are never taken as IDs, however many distinct values they hold.
 func DetectIDColumns(stats *t.DatasetStats, headers []string) []string {
 	idColumns := []string{}
 
@@ -19,21 +29,23 @@ func DetectIDColumns(stats *t.DatasetStats, headers []string) []string {
 		}
 
 		// Check if the column name suggests it's an ID
-		lowerHeader := strings.ToLower(header)
-		if lowerHeader == "id" || lowerHeader == "index" || lowerHeader == "key" ||
-			strings.HasSuffix(lowerHeader, "id") || strings.HasSuffix(lowerHeader, "key") ||
-			strings.HasPrefix(lowerHeader, "id") || lowerHeader == "day" {
+		if isIDName(header) {
 			idColumns = append(idColumns, header)
 			continue
 		}
 
 		colStats := stats.ColumnStats[header]
+		if colStats == nil {
+			continue
+		}
 
-		// Check if numerical and all values are unique (high cardinality)
-		if colStats.IsNumeric && len(colStats.UniqueValues) > 0 {
-			// If we have close to as many unique values as rows, it's likely an ID
-			uniqueRatio := float64(len(colStats.UniqueValues)) / float64(Min(1000, colStats.Count))
-			if uniqueRatio > 0.9 {
+		// Check if the integers of the column are nearly all distinct and
+		// nearly consecutive
+		if colStats.IsNumeric && colStats.IsInteger && colStats.Count > 1 {
+			distinct := float64(len(colStats.Integers))
+			uniqueRatio := distinct / float64(colStats.Count)
+			coverage := distinct / (colStats.Max - colStats.Min + 1)
+			if uniqueRatio > idRatio && coverage > idRatio {
 				idColumns = append(idColumns, header)
 			}
 		}
@@ -42,6 +54,50 @@ func DetectIDColumns(stats *t.DatasetStats, headers []string) []string {
 	return idColumns
 }
 
+// isIDName reports whether a column name names an ID: id, key or index, or
+// a name whose last word is id or key, as in user_id, user-id, userId or
+// userID. Words inside a name, as in paid, valid or idle, do not count.
+func isIDName(header string) bool {
+	words := nameWords(header)
+	if len(words) == 0 {
+		return false
+	}
+	if len(words) == 1 && words[0] == "index" {
+		return true
+	}
+	last := words[len(words)-1]
+	return last == "id" || last == "key"
+}
+
+// nameWords splits a column name into lower case words at separators and
+// at the start of capitalised words
+func nameWords(name string) []string {
+	var words []string
+	var word []rune
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == ' ' || r == '.':
+			if len(word) > 0 {
+				words = append(words, strings.ToLower(string(word)))
+				word = nil
+			}
+			continue
+		case unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]):
+			// userId and userID both start a word at the I
+			if len(word) > 0 {
+				words = append(words, strings.ToLower(string(word)))
+				word = nil
+			}
+		}
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		words = append(words, strings.ToLower(string(word)))
+	}
+	return words
+}
+
 // contains checks if a string is in a slice
 func Contains(slice []string, item string) bool {
 	for _, s := range slice {
@@ -96,39 +152,37 @@ func FilterInstances(instances []t.Instance, feature string, value string, isCon
 	return filteredInstances
 }
 
-// convertRecordToInstance converts a CSV record to an Instance object
+// FilterInstancesInSet keeps the instances whose categorical value is (or, when
+// inSet is false, is not) one of values. Instances missing the feature are dropped.
+func FilterInstancesInSet(instances []t.Instance, feature string, values []string, inSet bool) []t.Instance {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+
+	filteredInstances := make([]t.Instance, 0, len(instances)/2)
+	for _, instance := range instances {
+		val, ok := instance[feature]
+		if !ok || val == nil {
+			continue
+		}
+		if set[fmt.Sprintf("%v", val)] == inSet {
+			filteredInstances = append(filteredInstances, instance)
+		}
+	}
+
+	return filteredInstances
+}
+
+// convertRecordToInstance converts a CSV record to an Instance object.
+// Empty values, and values that do not convert to their feature type, are
+// missing.
 func ConvertRecordToInstance(record []string, headers []string, featureTypes map[string]string) t.Instance {
 	instance := make(t.Instance, len(headers))
 
 	for i, value := range record {
 		header := headers[i]
-
-		// Convert value based on feature type
-		switch featureTypes[header] {
-		case "numerical":
-			convert, err := ConvertStringToNumerical(header)
-			if err != nil {
-				instance[header] = convert
-			} else {
-				instance[header] = value
-			}
-		case "date":
-			convert, err := ConvertStringToDate(value)
-			if err != nil {
-				instance[header] = convert
-			} else {
-				instance[header] = value
-			}
-		case "timestamp":
-			convert, err := ConvertStringToTimestamp(value)
-			if err != nil {
-				instance[header] = convert
-			} else {
-				instance[header] = value
-			}
-		default:
-			instance[header] = value
-		}
+		instance[header], _ = ConvertValue(value, featureTypes[header])
 	}
 
 	return instance
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -29,6 +30,40 @@ func TestDetectIDColumns(t *testing.T) {
 	assert.ElementsMatch(t, expectedIDs, idColumns, "Detected ID columns do not match expected")
 }
 
+func TestDetectIDColumns_Integers(t *testing.T) {
+	stats := &test.DatasetStats{
+		RowCount: 3000,
+		ColumnStats: map[string]*test.ColumnStats{
+			// Continuous values are never IDs, even past the cap on unique values
+			"income": {IsNumeric: true, UniqueValues: make(map[string]int, 1000), Count: 3000},
+			"row":    {IsNumeric: true, IsInteger: true, Integers: make(map[int64]bool), Count: 2900, Missing: 100, Min: 0, Max: 2899},
+			"day":    {IsNumeric: true, IsInteger: true, Integers: map[int64]bool{1: true, 2: true, 3: true}, Count: 3000, Min: 1, Max: 3},
+			// Integer measurements are distinct too, but spread over a wide range
+			"salary": {IsNumeric: true, IsInteger: true, Integers: make(map[int64]bool), Count: 300, Min: 20000, Max: 99833},
+		},
+	}
+	for i := 0; i < 1000; i++ {
+		stats.ColumnStats["income"].UniqueValues[fmt.Sprint(i)] = 1
+	}
+	for i := 0; i < 2900; i++ {
+		stats.ColumnStats["row"].Integers[int64(i)] = true
+	}
+	for i := 0; i < 300; i++ {
+		stats.ColumnStats["salary"].Integers[int64(20000+i*267)] = true
+	}
+
+	assert.Equal(t, []string{"row"}, DetectIDColumns(stats, []string{"income", "row", "day", "salary"}))
+}
+
+func TestIsIDName(t *testing.T) {
+	for _, name := range []string{"id", "ID", "key", "index", "user_id", "user-id", "userId", "userID", "customer key"} {
+		assert.True(t, isIDName(name), name)
+	}
+	for _, name := range []string{"paid", "valid", "fluid", "idle", "identity", "hockey", "day", "Idaho", "keys"} {
+		assert.False(t, isIDName(name), name)
+	}
+}
+
 func TestContains(t *testing.T) {
 	type args struct {
 		slice []string
@@ -134,3 +169,35 @@ func TestFilterInstances(t *testing.T) {
 		assert.Empty(t, filtered)
 	})
 }
+
+func TestFilterInstancesInSet(t *testing.T) {
+	instances := []test.Instance{
+		{"colour": "red"},
+		{"colour": "blue"},
+		{"colour": "green"},
+		{"colour": nil},
+	}
+
+	in := FilterInstancesInSet(instances, "colour", []string{"red", "green"}, true)
+	assert.ElementsMatch(t, []test.Instance{{"colour": "red"}, {"colour": "green"}}, in)
+
+	out := FilterInstancesInSet(instances, "colour", []string{"red", "green"}, false)
+	assert.ElementsMatch(t, []test.Instance{{"colour": "blue"}}, out)
+}
+
+func TestConvertRecordToInstance(t *testing.T) {
+	headers := []string{"age", "joined", "seen", "colour"}
+	featureTypes := map[string]string{"age": "numerical", "joined": "date", "seen": "timestamp", "colour": "categorical"}
+
+	instance := ConvertRecordToInstance([]string{"42", "2024-03-01", "2024-03-01T10:00:00Z", "red"}, headers, featureTypes)
+	assert.Equal(t, 42.0, instance["age"])
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), instance["joined"])
+	assert.Equal(t, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), instance["seen"])
+	assert.Equal(t, "red", instance["colour"])
+
+	// Empty values and values that do not convert are missing
+	instance = ConvertRecordToInstance([]string{"forty", "", "yesterday", ""}, headers, featureTypes)
+	for _, header := range headers {
+		assert.Nil(t, instance[header], header)
+	}
+}
@@ -0,0 +1,340 @@
+package parquet
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	pq "github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/deprecated"
+	"github.com/parquet-go/parquet-go/format"
+
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/nyunja/c4.5-decision-tree/internal/source"
+)
+
+// Column is a leaf column of a Parquet file read as a feature. Columns of
+// nested groups are named by their dotted path.
+type Column struct {
+	Name string
+	Type string // feature type taken from the Parquet schema
+
+	index   int
+	convert func(pq.Value) interface{}
+}
+
+// Reader reads the instances of a Parquet file one row group at a time, so
+// only one row group of the selected columns is held in memory
+type Reader struct {
+	file    *os.File
+	parquet *pq.File
+
+	// Columns are the columns read, in schema order
+	Columns []Column
+	// Skipped lists the columns that cannot be read as features, such as
+	// lists and maps, with the reason
+	Skipped map[string]string
+}
+
+// Open opens a Parquet file and maps its schema to feature types. The
+// footer at the end of the file is read first, so Parquet is not read from
+// standard input.
+func Open(file string) (*Reader, error) {
+	if source.IsStdin(file) {
+		return nil, fmt.Errorf("Parquet files cannot be read from standard input")
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("error opening Parquet file: %v", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("error opening Parquet file: %v", err)
+	}
+	parquetFile, err := pq.OpenFile(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("error reading Parquet file %s: %v", file, err)
+	}
+
+	r := &Reader{file: f, parquet: parquetFile, Skipped: make(map[string]string)}
+	schema := parquetFile.Schema()
+	for _, path := range schema.Columns() {
+		name := strings.Join(path, ".")
+		leaf, _ := schema.Lookup(path...)
+		if leaf.MaxRepetitionLevel > 0 {
+			r.Skipped[name] = "repeated values"
+			continue
+		}
+		featureType, convert, err := columnType(leaf.Node.Type())
+		if err != nil {
+			r.Skipped[name] = err.Error()
+			continue
+		}
+		r.Columns = append(r.Columns, Column{Name: name, Type: featureType, index: leaf.ColumnIndex, convert: convert})
+	}
+	if len(r.Columns) == 0 {
+		f.Close()
+		return nil, fmt.Errorf("no readable columns in %s", file)
+	}
+	return r, nil
+}
+
+// Close closes the file
+func (r *Reader) Close() error {
+	return r.file.Close()
+}
+
+// Select restricts the columns read to those named, leaving the others unread
+func (r *Reader) Select(names []string) {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	selected := r.Columns[:0]
+	for _, column := range r.Columns {
+		if wanted[column.Name] {
+			selected = append(selected, column)
+		}
+	}
+	r.Columns = selected
+}
+
+// Headers returns the names of the columns read
+func (r *Reader) Headers() []string {
+	headers := make([]string, len(r.Columns))
+	for i, column := range r.Columns {
+		headers[i] = column.Name
+	}
+	return headers
+}
+
+// FeatureTypes returns the feature type of every column read
+func (r *Reader) FeatureTypes() map[string]string {
+	featureTypes := make(map[string]string, len(r.Columns))
+	for _, column := range r.Columns {
+		featureTypes[column.Name] = column.Type
+	}
+	return featureTypes
+}
+
+// NumRows returns the number of rows of the file
+func (r *Reader) NumRows() int64 {
+	return r.parquet.NumRows()
+}
+
+// ErrStop stops ReadRowGroups without an error
+var ErrStop = errors.New("stop reading")
+
+// ReadRowGroups calls fn with the instances of each row group in turn,
+// until fn returns an error. ErrStop ends reading early without an error.
+func (r *Reader) ReadRowGroups(fn func([]t.Instance) error) error {
+	for _, rowGroup := range r.parquet.RowGroups() {
+		instances := make([]t.Instance, rowGroup.NumRows())
+		for i := range instances {
+			instances[i] = make(t.Instance, len(r.Columns))
+		}
+
+		chunks := rowGroup.ColumnChunks()
+		for _, column := range r.Columns {
+			if err := readColumn(chunks[column.index], column, instances); err != nil {
+				return fmt.Errorf("error reading column '%s': %v", column.Name, err)
+			}
+		}
+
+		if err := fn(instances); err != nil {
+			if errors.Is(err, ErrStop) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// readColumn sets the values of a column chunk on the instances of its row group
+func readColumn(chunk pq.ColumnChunk, column Column, instances []t.Instance) error {
+	pages := chunk.Pages()
+	defer pages.Close()
+
+	buffer := make([]pq.Value, 1024)
+	row := 0
+	for {
+		page, err := pages.ReadPage()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		values := page.Values()
+		for {
+			n, err := values.ReadValues(buffer)
+			for _, value := range buffer[:n] {
+				if row >= len(instances) {
+					pq.Release(page)
+					return fmt.Errorf("more values than rows")
+				}
+				if value.IsNull() {
+					instances[row][column.Name] = nil
+				} else {
+					instances[row][column.Name] = column.convert(value)
+				}
+				row++
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				pq.Release(page)
+				return err
+			}
+		}
+		pq.Release(page)
+	}
+	if row != len(instances) {
+		return fmt.Errorf("read %d values for %d rows", row, len(instances))
+	}
+	return nil
+}
+
+// columnType maps the type of a leaf column to a feature type and the
+// conversion of its values. Logical types take precedence over the physical
+// type: dates, timestamps and INT96 timestamps are read as times, decimals
+// and times of day as numbers, and strings, enums, JSON and UUIDs as
+// categories.
+func columnType(typ pq.Type) (string, func(pq.Value) interface{}, error) {
+	logical := typ.LogicalType()
+	if logical == nil {
+		logical = &format.LogicalType{}
+	}
+	var converted deprecated.ConvertedType = -1
+	if c := typ.ConvertedType(); c != nil {
+		converted = *c
+	}
+
+	switch {
+	case logical.Date != nil || converted == deprecated.Date:
+		return "date", func(v pq.Value) interface{} {
+			return time.Unix(int64(v.Int32())*86400, 0).UTC()
+		}, nil
+
+	case logical.Timestamp != nil:
+		return "timestamp", timestampOf(logical.Timestamp.Unit), nil
+	case converted == deprecated.TimestampMillis:
+		return "timestamp", func(v pq.Value) interface{} { return time.UnixMilli(v.Int64()).UTC() }, nil
+	case converted == deprecated.TimestampMicros:
+		return "timestamp", func(v pq.Value) interface{} { return time.UnixMicro(v.Int64()).UTC() }, nil
+	case typ.Kind() == pq.Int96:
+		return "timestamp", func(v pq.Value) interface{} { return int96Time(v.Int96()) }, nil
+
+	case logical.Decimal != nil:
+		scale := math.Pow(10, float64(logical.Decimal.Scale))
+		return "numerical", func(v pq.Value) interface{} {
+			switch v.Kind() {
+			case pq.Int32:
+				return float64(v.Int32()) / scale
+			case pq.Int64:
+				return float64(v.Int64()) / scale
+			}
+			unscaled, _ := new(big.Float).SetInt(twosComplement(v.ByteArray())).Float64()
+			return unscaled / scale
+		}, nil
+
+	case logical.Time != nil:
+		// Times of day are numbers of seconds since midnight
+		unit := timeUnitSeconds(logical.Time.Unit)
+		return "numerical", func(v pq.Value) interface{} {
+			if v.Kind() == pq.Int32 {
+				return float64(v.Int32()) * unit
+			}
+			return float64(v.Int64()) * unit
+		}, nil
+
+	case logical.UUID != nil:
+		return "categorical", func(v pq.Value) interface{} { return uuidString(v.ByteArray()) }, nil
+
+	case logical.Float16 != nil, logical.Variant != nil, logical.Geometry != nil, logical.Geography != nil:
+		return "", nil, fmt.Errorf("unsupported type %s", logical)
+	}
+
+	unsigned := logical.Integer != nil && !logical.Integer.IsSigned
+	switch typ.Kind() {
+	case pq.Boolean:
+		return "categorical", func(v pq.Value) interface{} { return strconv.FormatBool(v.Boolean()) }, nil
+	case pq.Int32:
+		if unsigned {
+			return "numerical", func(v pq.Value) interface{} { return float64(v.Uint32()) }, nil
+		}
+		return "numerical", func(v pq.Value) interface{} { return float64(v.Int32()) }, nil
+	case pq.Int64:
+		if unsigned {
+			return "numerical", func(v pq.Value) interface{} { return float64(v.Uint64()) }, nil
+		}
+		return "numerical", func(v pq.Value) interface{} { return float64(v.Int64()) }, nil
+	case pq.Float:
+		return "numerical", func(v pq.Value) interface{} { return float64(v.Float()) }, nil
+	case pq.Double:
+		return "numerical", func(v pq.Value) interface{} { return v.Double() }, nil
+	case pq.ByteArray, pq.FixedLenByteArray:
+		return "categorical", func(v pq.Value) interface{} { return string(v.ByteArray()) }, nil
+	}
+	return "", nil, fmt.Errorf("unsupported type %s", typ)
+}
+
+// timestampOf returns the conversion of timestamps in unit
+func timestampOf(unit format.TimeUnit) func(pq.Value) interface{} {
+	switch {
+	case unit.Millis != nil:
+		return func(v pq.Value) interface{} { return time.UnixMilli(v.Int64()).UTC() }
+	case unit.Micros != nil:
+		return func(v pq.Value) interface{} { return time.UnixMicro(v.Int64()).UTC() }
+	}
+	return func(v pq.Value) interface{} { return time.Unix(0, v.Int64()).UTC() }
+}
+
+// timeUnitSeconds returns the number of seconds in one unit
+func timeUnitSeconds(unit format.TimeUnit) float64 {
+	switch {
+	case unit.Millis != nil:
+		return 1e-3
+	case unit.Micros != nil:
+		return 1e-6
+	}
+	return 1e-9
+}
+
+// int96Time converts a legacy INT96 timestamp, nanoseconds of the day
+// followed by the Julian day
+func int96Time(value deprecated.Int96) time.Time {
+	nanos := int64(uint64(value[1])<<32 | uint64(value[0]))
+	days := int64(value[2]) - 2440588 // Julian day of the Unix epoch
+	return time.Unix(days*86400, nanos).UTC()
+}
+
+// twosComplement decodes a big-endian two's complement integer
+func twosComplement(b []byte) *big.Int {
+	value := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		value.Sub(value, new(big.Int).Lsh(big.NewInt(1), uint(len(b))*8))
+	}
+	return value
+}
+
+// uuidString formats the 16 bytes of a UUID
+func uuidString(b []byte) string {
+	if len(b) != 16 {
+		return hex.EncodeToString(b)
+	}
+	s := hex.EncodeToString(b)
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
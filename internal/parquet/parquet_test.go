@@ -0,0 +1,151 @@
+package parquet
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pq "github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+)
+
+type address struct {
+	City string `parquet:"city"`
+}
+
+type account struct {
+	ID      string    `parquet:"id"`
+	Spend   *float64  `parquet:"spend,optional"`
+	Visits  int32     `parquet:"visits"`
+	Balance int64     `parquet:"balance,decimal(2:18)"`
+	Signup  int32     `parquet:"signup,date"` // days since the epoch
+	Seen    time.Time `parquet:"seen,timestamp(millisecond)"`
+	Active  bool      `parquet:"active"`
+	Address address   `parquet:"address"`
+	Tags    []string  `parquet:"tags,list"`
+	Churn   string    `parquet:"churn"`
+}
+
+func spend(v float64) *float64 { return &v }
+
+func days(date time.Time) int32 { return int32(date.Unix() / 86400) }
+
+// writeAccounts writes accounts to a Parquet file with rowGroupSize rows per row group
+func writeAccounts(tt *testing.T, accounts []account, rowGroupSize int) string {
+	file := filepath.Join(tt.TempDir(), "accounts.parquet")
+	f, err := os.Create(file)
+	require.NoError(tt, err)
+	defer f.Close()
+
+	writer := pq.NewGenericWriter[account](f)
+	for start := 0; start < len(accounts); start += rowGroupSize {
+		end := min(start+rowGroupSize, len(accounts))
+		_, err := writer.Write(accounts[start:end])
+		require.NoError(tt, err)
+		require.NoError(tt, writer.Flush())
+	}
+	require.NoError(tt, writer.Close())
+	return file
+}
+
+func sampleAccounts() []account {
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	return []account{
+		{ID: "0042", Spend: spend(12.5), Visits: 3, Balance: 1050, Signup: days(day), Seen: day.Add(90 * time.Minute), Active: true, Address: address{City: "Nairobi"}, Tags: []string{"a"}, Churn: "no"},
+		{ID: "0043", Visits: 1, Balance: -25, Signup: days(day.AddDate(0, 0, 1)), Seen: day, Address: address{City: "Kisumu"}, Churn: "yes"},
+		{ID: "0044", Spend: spend(3), Visits: 7, Balance: 0, Signup: days(day.AddDate(0, 1, 0)), Seen: day, Active: true, Address: address{City: "Nairobi"}, Churn: "no"},
+	}
+}
+
+func TestOpen(tt *testing.T) {
+	reader, err := Open(writeAccounts(tt, sampleAccounts(), 10))
+	require.NoError(tt, err)
+	defer reader.Close()
+
+	assert.Equal(tt, []string{"id", "spend", "visits", "balance", "signup", "seen", "active", "address.city", "churn"}, reader.Headers())
+	assert.Equal(tt, map[string]string{
+		"id":           "categorical",
+		"spend":        "numerical",
+		"visits":       "numerical",
+		"balance":      "numerical",
+		"signup":       "date",
+		"seen":         "timestamp",
+		"active":       "categorical",
+		"address.city": "categorical",
+		"churn":        "categorical",
+	}, reader.FeatureTypes())
+	assert.Contains(tt, reader.Skipped, "tags.list.element")
+	assert.Equal(tt, int64(3), reader.NumRows())
+}
+
+func TestReadRowGroups(tt *testing.T) {
+	reader, err := Open(writeAccounts(tt, sampleAccounts(), 2))
+	require.NoError(tt, err)
+	defer reader.Close()
+
+	var groups [][]t.Instance
+	require.NoError(tt, reader.ReadRowGroups(func(instances []t.Instance) error {
+		groups = append(groups, instances)
+		return nil
+	}))
+	require.Len(tt, groups, 2)
+	require.Len(tt, groups[0], 2)
+
+	first := groups[0][0]
+	assert.Equal(tt, "0042", first["id"])
+	assert.Equal(tt, 12.5, first["spend"])
+	assert.Equal(tt, 3.0, first["visits"])
+	assert.InDelta(tt, 10.5, first["balance"], 1e-9)
+	assert.Equal(tt, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), first["signup"])
+	assert.Equal(tt, time.Date(2024, 3, 1, 1, 30, 0, 0, time.UTC), first["seen"])
+	assert.Equal(tt, "true", first["active"])
+	assert.Equal(tt, "Nairobi", first["address.city"])
+
+	second := groups[0][1]
+	assert.Nil(tt, second["spend"])
+	assert.Contains(tt, second, "spend")
+	assert.InDelta(tt, -0.25, second["balance"], 1e-9)
+
+	assert.Equal(tt, "0044", groups[1][0]["id"])
+}
+
+func TestSelectAndStop(tt *testing.T) {
+	reader, err := Open(writeAccounts(tt, sampleAccounts(), 1))
+	require.NoError(tt, err)
+	defer reader.Close()
+
+	reader.Select([]string{"visits", "churn", "unknown"})
+	assert.Equal(tt, []string{"visits", "churn"}, reader.Headers())
+
+	var read []t.Instance
+	require.NoError(tt, reader.ReadRowGroups(func(instances []t.Instance) error {
+		read = append(read, instances...)
+		if len(read) == 2 {
+			return ErrStop
+		}
+		return nil
+	}))
+	require.Len(tt, read, 2)
+	assert.Equal(tt, t.Instance{"visits": 1.0, "churn": "yes"}, read[1])
+}
+
+func TestInt96Time(tt *testing.T) {
+	// Noon on the Julian day of 2024-03-01
+	nanos := uint64(12 * time.Hour)
+	value := [3]uint32{uint32(nanos), uint32(nanos >> 32), 2460371}
+	assert.Equal(tt, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), int96Time(value))
+}
+
+func TestOpen_Errors(tt *testing.T) {
+	_, err := Open(filepath.Join(tt.TempDir(), "missing.parquet"))
+	assert.Error(tt, err)
+
+	file := filepath.Join(tt.TempDir(), "not.parquet")
+	require.NoError(tt, os.WriteFile(file, []byte("id,churn\n1,no\n"), 0o644))
+	_, err = Open(file)
+	assert.Error(tt, err)
+}
@@ -0,0 +1,373 @@
+package profile
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	tcsv "github.com/nyunja/c4.5-decision-tree/internal/csv"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+	"github.com/nyunja/c4.5-decision-tree/internal/model/utils"
+)
+
+// topValues is the number of most frequent values reported per column
+const topValues = 5
+
+// leakageCorrelation is the correlation with a numerical target above which
+// a feature is flagged as leakage
+const leakageCorrelation = 0.99
+
+// nearUniqueRatio is the share of its non-missing rows a categorical column
+// must have distinct values for to be flagged as near-unique
+const nearUniqueRatio = 0.9
+
+// ValueCount is a value with the number of rows holding it
+type ValueCount struct {
+	Value string  `json:"value"`
+	Count int     `json:"count"`
+	Share float64 `json:"share"`
+}
+
+// Column is the profile of one column
+type Column struct {
+	Name        string  `json:"name"`
+	Type        string  `json:"type"`
+	Missing     int     `json:"missing"`
+	MissingRate float64 `json:"missing_rate"`
+	// Cardinality is the number of distinct values, counted up to
+	// csv.MaxUniqueValues
+	Cardinality       int          `json:"cardinality"`
+	CardinalityCapped bool         `json:"cardinality_capped,omitempty"`
+	Min               *float64     `json:"min,omitempty"`
+	Max               *float64     `json:"max,omitempty"`
+	Mean              *float64     `json:"mean,omitempty"`
+	TopValues         []ValueCount `json:"top_values,omitempty"`
+	Target            bool         `json:"target,omitempty"`
+	ID                bool         `json:"id,omitempty"`
+	Constant          bool         `json:"constant,omitempty"`
+	// NearUnique is set on categorical columns with almost a value per row,
+	// such as names or free text
+	NearUnique bool `json:"near_unique,omitempty"`
+	// Leakage explains why the column may leak the target, empty when it
+	// does not
+	Leakage string `json:"leakage,omitempty"`
+}
+
+// Profile describes a dataset before training
+type Profile struct {
+	Rows    int          `json:"rows"`
+	Target  string       `json:"target,omitempty"`
+	Classes []ValueCount `json:"classes,omitempty"` // class distribution of a categorical target
+	Columns []Column     `json:"columns"`
+
+	IDColumns         []string `json:"id_columns"`
+	ConstantColumns   []string `json:"constant_columns"`
+	NearUniqueColumns []string `json:"near_unique_columns"`
+	LeakageColumns    []string `json:"leakage_columns"`
+}
+
+// Build profiles the instances of a dataset from the statistics the CSV
+// passes collect. Values of every format are read back as text for them,
+// strings being text that is never taken for a number. With a target, its
+// class distribution is reported and features that predict it perfectly
+// are flagged as possible leakage.
+func Build(instances []t.Instance, headers []string, featureTypes map[string]string, target string) (*Profile, error) {
+	records := &instanceReader{instances: instances, headers: headers, featureTypes: featureTypes}
+	stats, err := tcsv.CollectRecordStatistics(records, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &Profile{
+		Rows:              stats.RowCount,
+		Target:            target,
+		Columns:           make([]Column, 0, len(headers)),
+		IDColumns:         []string{},
+		ConstantColumns:   []string{},
+		NearUniqueColumns: []string{},
+		LeakageColumns:    []string{},
+	}
+
+	ids := make(map[string]bool)
+	for _, header := range utils.DetectIDColumns(stats, headers) {
+		if header != target {
+			ids[header] = true
+		}
+	}
+
+	// Statistics cover at most the first SampleSize rows
+	sampled := utils.Min(stats.RowCount, tcsv.SampleSize)
+	for _, header := range headers {
+		colStats := stats.ColumnStats[header]
+		column := Column{
+			Name:              header,
+			Type:              featureTypes[header],
+			Missing:           colStats.Missing,
+			Cardinality:       len(colStats.UniqueValues),
+			CardinalityCapped: len(colStats.UniqueValues) >= tcsv.MaxUniqueValues,
+			TopValues:         top(colStats.UniqueValues, sampled-colStats.Missing, topValues),
+			Target:            header == target,
+			ID:                ids[header],
+			Constant:          len(colStats.UniqueValues) <= 1,
+		}
+		if sampled > 0 {
+			column.MissingRate = float64(colStats.Missing) / float64(sampled)
+		}
+		if column.Type == "numerical" && colStats.IsNumeric && colStats.Count > 0 {
+			mean := colStats.Sum / float64(colStats.Count)
+			column.Min, column.Max, column.Mean = &colStats.Min, &colStats.Max, &mean
+		}
+		if column.Type == "categorical" && !column.Target && !column.ID && !column.Constant {
+			// The cardinality is capped, so the values are counted over
+			// every row
+			values, rows := distinct(instances, header)
+			column.NearUnique = values > 1 && float64(values) >= nearUniqueRatio*float64(rows)
+		}
+		// Every value of an ID or near-unique categorical holds a single
+		// class, so only their numbers are checked for leakage
+		if target != "" && header != target && !column.Constant &&
+			(column.Type != "categorical" || !column.ID && !column.NearUnique) {
+			column.Leakage = leakage(instances, header, column.Type, target, featureTypes[target])
+		}
+
+		if column.ID {
+			profile.IDColumns = append(profile.IDColumns, header)
+		}
+		if column.Constant {
+			profile.ConstantColumns = append(profile.ConstantColumns, header)
+		}
+		if column.NearUnique {
+			profile.NearUniqueColumns = append(profile.NearUniqueColumns, header)
+		}
+		if column.Leakage != "" {
+			profile.LeakageColumns = append(profile.LeakageColumns, header)
+		}
+		profile.Columns = append(profile.Columns, column)
+	}
+
+	if target != "" && featureTypes[target] == "categorical" {
+		classes := make(map[string]int)
+		labelled := 0
+		for _, instance := range instances {
+			if value := instance[target]; value != nil {
+				classes[fmt.Sprintf("%v", value)]++
+				labelled++
+			}
+		}
+		profile.Classes = top(classes, labelled, len(classes))
+	}
+	return profile, nil
+}
+
+// Exclusions returns the columns to leave out of training, with why: ID
+// columns, constant columns and near-unique categoricals. Columns that may
+// leak the target are returned apart as warnings, since a feature that
+// predicts the target perfectly may also be a genuine one.
+func (p *Profile) Exclusions() (excluded, warnings []t.ColumnNote) {
+	for _, column := range p.Columns {
+		switch {
+		case column.ID:
+			excluded = append(excluded, t.ColumnNote{Column: column.Name, Reason: "looks like an ID"})
+		case column.Constant:
+			excluded = append(excluded, t.ColumnNote{Column: column.Name, Reason: "holds a single value"})
+		case column.NearUnique:
+			excluded = append(excluded, t.ColumnNote{Column: column.Name, Reason: "categorical with a distinct value in almost every row"})
+		case column.Leakage != "":
+			warnings = append(warnings, t.ColumnNote{Column: column.Name, Reason: "possible target leakage: " + column.Leakage})
+		}
+	}
+	return excluded, warnings
+}
+
+// distinct returns the number of distinct values of a column and of rows
+// where it is not missing
+func distinct(instances []t.Instance, header string) (values int, rows int) {
+	seen := make(map[string]bool)
+	for _, instance := range instances {
+		if value := instance[header]; value != nil {
+			seen[fmt.Sprintf("%v", value)] = true
+			rows++
+		}
+	}
+	return len(seen), rows
+}
+
+// top returns the n most frequent values, with their share of total
+func top(counts map[string]int, total int, n int) []ValueCount {
+	values := make([]ValueCount, 0, len(counts))
+	for value, count := range counts {
+		share := 0.0
+		if total > 0 {
+			share = float64(count) / float64(total)
+		}
+		values = append(values, ValueCount{Value: value, Count: count, Share: share})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+		return values[i].Value < values[j].Value
+	})
+	if len(values) > n {
+		values = values[:n]
+	}
+	return values
+}
+
+// leakage explains why a feature may leak the target, or returns "". A
+// categorical target leaks when every value of a categorical feature, or
+// every interval of a numerical one, holds a single class; a numerical
+// target when a numerical feature is almost perfectly correlated with it.
+func leakage(instances []t.Instance, feature, featureType, target, targetType string) string {
+	type pair struct {
+		value  interface{}
+		target interface{}
+	}
+	pairs := make([]pair, 0, len(instances))
+	for _, instance := range instances {
+		if instance[feature] != nil && instance[target] != nil {
+			pairs = append(pairs, pair{instance[feature], instance[target]})
+		}
+	}
+	if len(pairs) < 2 {
+		return ""
+	}
+
+	if targetType == "numerical" {
+		if featureType != "numerical" {
+			return ""
+		}
+		xs, ys := make([]float64, len(pairs)), make([]float64, len(pairs))
+		for i, p := range pairs {
+			xs[i], _ = p.value.(float64)
+			ys[i], _ = p.target.(float64)
+		}
+		if r := correlation(xs, ys); math.Abs(r) >= leakageCorrelation {
+			return fmt.Sprintf("correlation %.3f with the target", r)
+		}
+		return ""
+	}
+
+	classes := make(map[string]bool)
+	for _, p := range pairs {
+		classes[fmt.Sprintf("%v", p.target)] = true
+	}
+	if len(classes) < 2 {
+		return ""
+	}
+
+	switch featureType {
+	case "numerical", "date", "timestamp":
+		sort.SliceStable(pairs, func(i, j int) bool { return number(pairs[i].value) < number(pairs[j].value) })
+		// Each class must fill one interval of values, with no value
+		// shared by two classes
+		runs := 1
+		for i := 1; i < len(pairs); i++ {
+			if fmt.Sprintf("%v", pairs[i].target) == fmt.Sprintf("%v", pairs[i-1].target) {
+				continue
+			}
+			if number(pairs[i].value) == number(pairs[i-1].value) {
+				return ""
+			}
+			runs++
+		}
+		if runs == len(classes) {
+			return fmt.Sprintf("thresholds separate all %d classes", len(classes))
+		}
+	default:
+		classOf := make(map[string]string)
+		for _, p := range pairs {
+			value, class := fmt.Sprintf("%v", p.value), fmt.Sprintf("%v", p.target)
+			if seen, ok := classOf[value]; ok && seen != class {
+				return ""
+			}
+			classOf[value] = class
+		}
+		// Values nearly unique to their rows predict anything; that is an
+		// ID, not leakage
+		if len(classOf)*2 <= len(pairs) {
+			return fmt.Sprintf("each of its %d values holds a single class", len(classOf))
+		}
+	}
+	return ""
+}
+
+// number returns a numerical, date or timestamp value as a number
+func number(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case time.Time:
+		return float64(v.UnixNano())
+	}
+	return math.NaN()
+}
+
+// correlation returns the Pearson correlation of xs and ys, 0 when either
+// is constant
+func correlation(xs, ys []float64) float64 {
+	n := float64(len(xs))
+	var sumX, sumY, sumXY, sumXX, sumYY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+		sumXY += xs[i] * ys[i]
+		sumXX += xs[i] * xs[i]
+		sumYY += ys[i] * ys[i]
+	}
+	denominator := math.Sqrt(n*sumXX-sumX*sumX) * math.Sqrt(n*sumYY-sumY*sumY)
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
+
+// instanceReader reads instances back as records of text for the CSV
+// statistics
+type instanceReader struct {
+	instances    []t.Instance
+	headers      []string
+	featureTypes map[string]string
+	next         int
+	record       []string
+	text         []bool
+}
+
+func (r *instanceReader) Read() ([]string, error) {
+	if r.next >= len(r.instances) {
+		return nil, io.EOF
+	}
+	if r.record == nil {
+		r.record = make([]string, len(r.headers))
+		r.text = make([]bool, len(r.headers))
+	}
+
+	instance := r.instances[r.next]
+	r.next++
+	for i, header := range r.headers {
+		r.record[i], r.text[i] = "", false
+		switch v := instance[header].(type) {
+		case nil:
+		case float64:
+			r.record[i] = strconv.FormatFloat(v, 'g', -1, 64)
+		case time.Time:
+			if r.featureTypes[header] == "date" {
+				r.record[i] = v.Format("2006-01-02")
+			} else {
+				r.record[i] = v.Format(time.RFC3339)
+			}
+		case string:
+			r.record[i], r.text[i] = v, true
+		default:
+			r.record[i] = fmt.Sprintf("%v", v)
+		}
+	}
+	return r.record, nil
+}
+
+func (r *instanceReader) IsText(column int) bool {
+	return r.text[column]
+}
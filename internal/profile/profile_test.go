@@ -0,0 +1,228 @@
+package profile
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand/v2"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+)
+
+var customerHeaders = []string{"customer_id", "plan", "tenure", "joined", "country", "refund_issued", "churned"}
+
+var customerTypes = map[string]string{
+	"customer_id": "numerical", "plan": "categorical", "tenure": "numerical", "joined": "date",
+	"country": "categorical", "refund_issued": "categorical", "churned": "categorical",
+}
+
+// customers has an ID, a constant country and a refund flag that gives the
+// churn away
+func customers() []t.Instance {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	rows := []struct {
+		plan    string
+		tenure  interface{}
+		refund  string
+		churned string
+	}{
+		{"pro", 12.0, "no", "no"}, {"basic", 3.0, "yes", "yes"}, {"pro", 12.0, "no", "no"}, {"gold", 1.0, "yes", "yes"},
+		{"basic", nil, "no", "no"}, {"pro", 3.0, "yes", "yes"}, {"basic", 5.0, "no", "no"}, {"gold", 12.0, "yes", "yes"},
+	}
+	instances := make([]t.Instance, len(rows))
+	for i, row := range rows {
+		instances[i] = t.Instance{
+			"customer_id": float64(101 + i), "plan": row.plan, "tenure": row.tenure, "joined": day(i%3 + 1),
+			"country": "US", "refund_issued": row.refund, "churned": row.churned,
+		}
+	}
+	return instances
+}
+
+func column(tt *testing.T, profile *Profile, name string) Column {
+	for _, column := range profile.Columns {
+		if column.Name == name {
+			return column
+		}
+	}
+	tt.Fatalf("no column %s", name)
+	return Column{}
+}
+
+func TestBuild(tt *testing.T) {
+	profile, err := Build(customers(), customerHeaders, customerTypes, "churned")
+	require.NoError(tt, err)
+
+	assert.Equal(tt, 8, profile.Rows)
+	assert.Equal(tt, []ValueCount{{"no", 4, 0.5}, {"yes", 4, 0.5}}, profile.Classes)
+	assert.Equal(tt, []string{"customer_id"}, profile.IDColumns)
+	assert.Equal(tt, []string{"country"}, profile.ConstantColumns)
+	assert.Equal(tt, []string{"refund_issued"}, profile.LeakageColumns)
+
+	tenure := column(tt, profile, "tenure")
+	assert.Equal(tt, 1, tenure.Missing)
+	assert.InDelta(tt, 0.125, tenure.MissingRate, 1e-9)
+	assert.Equal(tt, 4, tenure.Cardinality)
+	assert.Equal(tt, 1.0, *tenure.Min)
+	assert.Equal(tt, 12.0, *tenure.Max)
+	assert.InDelta(tt, 48.0/7, *tenure.Mean, 1e-9)
+	assert.Equal(tt, ValueCount{"12", 3, 3.0 / 7}, tenure.TopValues[0])
+	assert.Empty(tt, tenure.Leakage)
+
+	plan := column(tt, profile, "plan")
+	assert.Nil(tt, plan.Min)
+	assert.Equal(tt, 3, plan.Cardinality)
+
+	assert.Equal(tt, 3, column(tt, profile, "joined").Cardinality)
+	assert.True(tt, column(tt, profile, "churned").Target)
+}
+
+func TestBuild_ManyRows(tt *testing.T) {
+	headers := []string{"row", "paid", "income", "day", "code"}
+	types := map[string]string{"row": "numerical", "paid": "categorical", "income": "numerical", "day": "numerical", "code": "numerical"}
+	instances := make([]t.Instance, 3000)
+	for i := range instances {
+		// Codes are distinct for the first rows, then all 7, past the
+		// cap on distinct values
+		code := float64(i)
+		if i >= 1500 {
+			code = 7
+		}
+		instances[i] = t.Instance{
+			"row": float64(i + 1), "paid": []string{"yes", "no"}[i%2], "income": 1000 + float64(i)*1.37,
+			"day": float64(i%7 + 1), "code": code,
+		}
+	}
+
+	profile, err := Build(instances, headers, types, "")
+	require.NoError(tt, err)
+
+	assert.Equal(tt, []string{"row"}, profile.IDColumns)
+	assert.True(tt, column(tt, profile, "income").CardinalityCapped)
+	assert.Equal(tt, ValueCount{"7", 1501, 1501.0 / 3000}, column(tt, profile, "code").TopValues[0])
+}
+
+func TestLeakage(tt *testing.T) {
+	instances := []t.Instance{
+		{"score": 0.1, "label": "low", "amount": 10.0, "paid": 11.0},
+		{"score": 0.2, "label": "low", "amount": 20.0, "paid": 19.5},
+		{"score": 0.8, "label": "high", "amount": 30.0, "paid": 31.0},
+		{"score": 0.9, "label": "high", "amount": 40.0, "paid": 40.5},
+	}
+	assert.Equal(tt, "thresholds separate all 2 classes", leakage(instances, "score", "numerical", "label", "categorical"))
+	// A numerical target leaks through an almost perfectly correlated feature
+	assert.Contains(tt, leakage(instances, "paid", "numerical", "amount", "numerical"), "correlation")
+
+	// Values shared by two classes do not separate them
+	instances[1]["score"] = 0.8
+	assert.Empty(tt, leakage(instances, "score", "numerical", "label", "categorical"))
+}
+
+func TestWrite(tt *testing.T) {
+	profile, err := Build(customers(), customerHeaders, customerTypes, "churned")
+	require.NoError(tt, err)
+
+	var table bytes.Buffer
+	require.NoError(tt, Write(&table, profile, "table"))
+	assert.Contains(tt, table.String(), "Possible target leakage: [refund_issued]")
+	assert.Contains(tt, table.String(), "leakage: each of its 2 values holds a single class")
+
+	var encoded bytes.Buffer
+	require.NoError(tt, Write(&encoded, profile, "json"))
+	var decoded Profile
+	require.NoError(tt, json.Unmarshal(encoded.Bytes(), &decoded))
+	assert.Equal(tt, profile.LeakageColumns, decoded.LeakageColumns)
+
+	var page bytes.Buffer
+	require.NoError(tt, Write(&page, profile, "html"))
+	assert.True(tt, strings.HasPrefix(page.String(), "<!DOCTYPE html>"))
+	assert.Contains(tt, page.String(), `<tr class="warn"><td>refund_issued</td>`)
+
+	assert.Error(tt, Write(&table, profile, "xml"))
+}
+
+func TestExclusions(tt *testing.T) {
+	instances := customers()
+	headers := append([]string{"name"}, customerHeaders...)
+	featureTypes := map[string]string{"name": "categorical"}
+	for header, featureType := range customerTypes {
+		featureTypes[header] = featureType
+	}
+	for i, instance := range instances {
+		instance["name"] = string(rune('a' + i))
+	}
+
+	profile, err := Build(instances, headers, featureTypes, "churned")
+	require.NoError(tt, err)
+	assert.Equal(tt, []string{"name"}, profile.NearUniqueColumns)
+	assert.False(tt, column(tt, profile, "plan").NearUnique)
+
+	excluded, warnings := profile.Exclusions()
+	columns := make([]string, len(excluded))
+	for i, note := range excluded {
+		columns[i] = note.Column
+	}
+	assert.Equal(tt, []string{"name", "customer_id", "country"}, columns)
+	assert.Equal(tt, []t.ColumnNote{{Column: "refund_issued", Reason: "possible target leakage: each of its 2 values holds a single class"}}, warnings)
+}
+
+func TestExclusions_KeepsFeaturesOfLargeInputs(tt *testing.T) {
+	headers := []string{"paid", "income", "salary", "day", "churned"}
+	featureTypes := map[string]string{"paid": "categorical", "income": "numerical", "salary": "numerical", "day": "numerical", "churned": "categorical"}
+	random := rand.New(rand.NewPCG(1, 0))
+	instances := make([]t.Instance, 3000)
+	for i := range instances {
+		instances[i] = t.Instance{
+			"paid": []string{"yes", "no"}[random.IntN(2)], "income": 20000 + 80000*random.Float64(),
+			"salary": float64(20000 + random.IntN(80001)), "day": float64(random.IntN(7) + 1),
+			"churned": []string{"yes", "no"}[random.IntN(2)],
+		}
+	}
+
+	profile, err := Build(instances, headers, featureTypes, "churned")
+	require.NoError(tt, err)
+	assert.True(tt, column(tt, profile, "income").CardinalityCapped)
+
+	excluded, warnings := profile.Exclusions()
+	assert.Empty(tt, excluded, "continuous and integer features over more than 1000 rows and a column named paid are features")
+	assert.Empty(tt, warnings)
+}
+
+func TestExclusions_IntegerFeatureLeaks(tt *testing.T) {
+	headers := []string{"income", "label"}
+	featureTypes := map[string]string{"income": "numerical", "label": "categorical"}
+	random := rand.New(rand.NewPCG(2, 0))
+	instances := make([]t.Instance, 300)
+	for i := range instances {
+		income := 20000 + random.IntN(80001)
+		instances[i] = t.Instance{"income": float64(income), "label": []string{"low", "high"}[min(income/60001, 1)]}
+	}
+
+	profile, err := Build(instances, headers, featureTypes, "label")
+	require.NoError(tt, err)
+	assert.Empty(tt, profile.IDColumns, "distinct integer incomes are not IDs")
+	assert.Equal(tt, []string{"income"}, profile.LeakageColumns)
+
+	excluded, warnings := profile.Exclusions()
+	assert.Empty(tt, excluded)
+	require.Len(tt, warnings, 1)
+	assert.Equal(tt, "income", warnings[0].Column)
+}
+
+func TestBuild_NumericIDLeaks(tt *testing.T) {
+	// Rows sorted by class give their numbers away
+	instances := make([]t.Instance, 20)
+	for i := range instances {
+		instances[i] = t.Instance{"row": float64(i + 1), "label": []string{"a", "b"}[i/10]}
+	}
+
+	profile, err := Build(instances, []string{"row", "label"}, map[string]string{"row": "numerical", "label": "categorical"}, "label")
+	require.NoError(tt, err)
+	assert.Equal(tt, []string{"row"}, profile.IDColumns)
+	assert.Equal(tt, []string{"row"}, profile.LeakageColumns)
+}
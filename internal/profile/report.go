@@ -0,0 +1,175 @@
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Formats lists the names accepted by Write
+var Formats = []string{"table", "json", "html"}
+
+// Write writes the profile in the given format: aligned tables, JSON or a
+// standalone HTML page
+func Write(w io.Writer, profile *Profile, format string) error {
+	switch format {
+	case "table":
+		return writeTable(w, profile)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(profile)
+	case "html":
+		return page.Execute(w, profile)
+	default:
+		return fmt.Errorf("unknown format '%s', expected one of %v", format, Formats)
+	}
+}
+
+// header returns the names of the columns of the column table
+func header() []string {
+	return []string{"column", "type", "missing", "cardinality", "min", "max", "mean", "top values", "flags"}
+}
+
+// row formats the columns of one column
+func row(column Column) []string {
+	cardinality := strconv.Itoa(column.Cardinality)
+	if column.CardinalityCapped {
+		cardinality += "+"
+	}
+	return []string{
+		column.Name,
+		column.Type,
+		fmt.Sprintf("%d (%.1f%%)", column.Missing, column.MissingRate*100),
+		cardinality,
+		formatNumber(column.Min),
+		formatNumber(column.Max),
+		formatNumber(column.Mean),
+		formatValues(column.TopValues),
+		strings.Join(flags(column), ", "),
+	}
+}
+
+// flags lists the warnings about a column
+func flags(column Column) []string {
+	var flags []string
+	if column.Target {
+		flags = append(flags, "target")
+	}
+	if column.ID {
+		flags = append(flags, "id")
+	}
+	if column.Constant {
+		flags = append(flags, "constant")
+	}
+	if column.NearUnique {
+		flags = append(flags, "near-unique")
+	}
+	if column.Leakage != "" {
+		flags = append(flags, "leakage: "+column.Leakage)
+	}
+	return flags
+}
+
+func formatNumber(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'g', 6, 64)
+}
+
+func formatValues(values []ValueCount) string {
+	formatted := make([]string, len(values))
+	for i, value := range values {
+		formatted[i] = fmt.Sprintf("%s (%d)", value.Value, value.Count)
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func writeTable(w io.Writer, profile *Profile) error {
+	fmt.Fprintf(w, "Rows: %d\n", profile.Rows)
+	if profile.Target != "" {
+		fmt.Fprintf(w, "Target: %s\n", profile.Target)
+	}
+	if len(profile.Classes) > 0 {
+		fmt.Fprintln(w, "Class distribution:")
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, class := range profile.Classes {
+			fmt.Fprintf(tw, "  %s\t%d\t%.1f%%\n", class.Value, class.Count, class.Share*100)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header(), "\t"))
+	for _, column := range profile.Columns {
+		fmt.Fprintln(tw, strings.Join(row(column), "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "ID columns: %v\n", profile.IDColumns)
+	fmt.Fprintf(w, "Constant columns: %v\n", profile.ConstantColumns)
+	fmt.Fprintf(w, "Near-unique categorical columns: %v\n", profile.NearUniqueColumns)
+	fmt.Fprintf(w, "Possible target leakage: %v\n", profile.LeakageColumns)
+	return nil
+}
+
+// page is the HTML report, with no external resources
+var page = template.Must(template.New("profile").Funcs(template.FuncMap{
+	"header": header,
+	"row":    row,
+	"percent": func(share float64) string {
+		return fmt.Sprintf("%.1f%%", share*100)
+	},
+	"warn": func(column Column) bool {
+		return column.ID || column.Constant || column.NearUnique || column.Leakage != ""
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Data profile</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f0f0f0; }
+tr.warn td { background: #fff4e0; }
+.bar { background: #4a90d9; height: 10px; }
+</style>
+</head>
+<body>
+<h1>Data profile</h1>
+<p>{{.Rows}} rows{{if .Target}}, target <strong>{{.Target}}</strong>{{end}}</p>
+{{if .Classes}}
+<h2>Class distribution</h2>
+<table>
+<tr><th>class</th><th>rows</th><th>share</th><th></th></tr>
+{{range .Classes}}<tr><td>{{.Value}}</td><td>{{.Count}}</td><td>{{percent .Share}}</td><td><div class="bar" style="width: {{percent .Share}}"></div></td></tr>
+{{end}}</table>
+{{end}}
+<h2>Columns</h2>
+<table>
+<tr>{{range header}}<th>{{.}}</th>{{end}}</tr>
+{{range .Columns}}<tr{{if warn .}} class="warn"{{end}}>{{range row .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+<h2>Warnings</h2>
+<ul>
+<li>ID columns: {{if .IDColumns}}{{range $i, $c := .IDColumns}}{{if $i}}, {{end}}{{$c}}{{end}}{{else}}none{{end}}</li>
+<li>Constant columns: {{if .ConstantColumns}}{{range $i, $c := .ConstantColumns}}{{if $i}}, {{end}}{{$c}}{{end}}{{else}}none{{end}}</li>
+<li>Near-unique categorical columns: {{if .NearUniqueColumns}}{{range $i, $c := .NearUniqueColumns}}{{if $i}}, {{end}}{{$c}}{{end}}{{else}}none{{end}}</li>
+<li>Possible target leakage: {{if .LeakageColumns}}{{range $i, $c := .LeakageColumns}}{{if $i}}, {{end}}{{$c}}{{end}}{{else}}none{{end}}</li>
+</ul>
+</body>
+</html>
+`))
@@ -0,0 +1,460 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	tcsv "github.com/nyunja/c4.5-decision-tree/internal/csv"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+)
+
+// Column roles
+const (
+	Feature = "feature"
+	Target  = "target"
+	ID      = "id"
+	Weight  = "weight"
+	Ignore  = "ignore"
+)
+
+// Types lists the column types a schema declares, the feature types of the models
+var Types = []string{"numerical", "categorical", "date", "timestamp"}
+
+// Roles lists the roles of columns
+var Roles = []string{Feature, Target, ID, Weight, Ignore}
+
+// templateCategories is the most distinct values a template lists as the
+// categories of a categorical column
+const templateCategories = 20
+
+// Column declares how one column is read
+type Column struct {
+	Name string `json:"name" yaml:"name"`
+	// Type is numerical, categorical, date or timestamp, inferred when empty
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+	// Role is feature when empty
+	Role string `json:"role,omitempty" yaml:"role,omitempty"`
+	// Categories are the values a categorical column allows. Other values
+	// are read as missing.
+	Categories []string `json:"categories,omitempty" yaml:"categories,omitempty"`
+	// DateFormat is the Go layout of the dates of a date or timestamp
+	// column, such as 02/01/2006
+	DateFormat string `json:"date_format,omitempty" yaml:"date_format,omitempty"`
+	// Missing are the values of the column read as missing
+	Missing []string `json:"missing,omitempty" yaml:"missing,omitempty"`
+}
+
+// Schema declares the columns of a dataset, overriding the types inferred
+// from its values. Columns it leaves out are inferred as usual.
+type Schema struct {
+	// Missing are the values of every column read as missing
+	Missing []string `json:"missing,omitempty" yaml:"missing,omitempty"`
+	Columns []Column `json:"columns" yaml:"columns"`
+}
+
+// Load reads a schema file, JSON when its extension is .json and YAML otherwise
+func Load(file string) (*Schema, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error opening schema file: %v", err)
+	}
+
+	schema := &Schema{}
+	if strings.ToLower(filepath.Ext(file)) == ".json" {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(schema)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(schema)
+	}
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("error parsing %s: %v", file, err)
+	}
+
+	if err := schema.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid schema %s: %v", file, err)
+	}
+	return schema, nil
+}
+
+// Validate checks the types and roles of the columns, and that at most one
+// column is the target and one the weight
+func (s *Schema) Validate() error {
+	seen := make(map[string]bool, len(s.Columns))
+	roles := make(map[string]string)
+	for i := range s.Columns {
+		column := &s.Columns[i]
+		column.Type = strings.ToLower(column.Type)
+		column.Role = strings.ToLower(column.Role)
+
+		if column.Name == "" {
+			return fmt.Errorf("column %d has no name", i+1)
+		}
+		if seen[column.Name] {
+			return fmt.Errorf("column '%s' is declared twice", column.Name)
+		}
+		seen[column.Name] = true
+
+		if column.Type != "" && !slices.Contains(Types, column.Type) {
+			return fmt.Errorf("column '%s' has unknown type '%s', expected one of %s", column.Name, column.Type, strings.Join(Types, ", "))
+		}
+		if column.Role != "" && !slices.Contains(Roles, column.Role) {
+			return fmt.Errorf("column '%s' has unknown role '%s', expected one of %s", column.Name, column.Role, strings.Join(Roles, ", "))
+		}
+		if column.Role == Target || column.Role == Weight {
+			if other, ok := roles[column.Role]; ok {
+				return fmt.Errorf("columns '%s' and '%s' are both the %s", other, column.Name, column.Role)
+			}
+			roles[column.Role] = column.Name
+		}
+
+		if len(column.Categories) > 0 && column.Type != "" && column.Type != "categorical" {
+			return fmt.Errorf("column '%s' lists categories but is %s", column.Name, column.Type)
+		}
+		if column.DateFormat != "" && column.Type != "" && column.Type != "date" && column.Type != "timestamp" {
+			return fmt.Errorf("column '%s' has a date format but is %s", column.Name, column.Type)
+		}
+		if column.Role == Weight && column.Type != "" && column.Type != "numerical" {
+			return fmt.Errorf("weight column '%s' must be numerical", column.Name)
+		}
+	}
+	return nil
+}
+
+// column returns the declaration of a column, nil when it is not declared
+func (s *Schema) column(name string) *Column {
+	for i := range s.Columns {
+		if s.Columns[i].Name == name {
+			return &s.Columns[i]
+		}
+	}
+	return nil
+}
+
+// columnType returns the declared type of a column: its type, categorical
+// when it lists categories, or date when it has a date format
+func (c *Column) columnType() string {
+	switch {
+	case c.Type != "":
+		return c.Type
+	case len(c.Categories) > 0:
+		return "categorical"
+	case c.DateFormat != "":
+		return "date"
+	}
+	return ""
+}
+
+// withRole returns the names of the columns with one of roles, in order
+func (s *Schema) withRole(roles ...string) []string {
+	if s == nil {
+		return nil
+	}
+	var names []string
+	for _, column := range s.Columns {
+		if slices.Contains(roles, column.Role) {
+			names = append(names, column.Name)
+		}
+	}
+	return names
+}
+
+// Target returns the target column, empty when none is declared
+func (s *Schema) Target() string {
+	if names := s.withRole(Target); len(names) > 0 {
+		return names[0]
+	}
+	return ""
+}
+
+// Weight returns the weight column, empty when none is declared
+func (s *Schema) Weight() string {
+	if names := s.withRole(Weight); len(names) > 0 {
+		return names[0]
+	}
+	return ""
+}
+
+// Excluded returns the columns that are not features: ID and ignored columns
+func (s *Schema) Excluded() []string {
+	return s.withRole(ID, Ignore)
+}
+
+// FeatureTypes returns the inferred feature types with the declared types
+// in place of the inferred ones. A nil schema keeps the inferred types.
+func (s *Schema) FeatureTypes(inferred map[string]string) map[string]string {
+	if s == nil {
+		return inferred
+	}
+	featureTypes := make(map[string]string, len(inferred))
+	for name, featureType := range inferred {
+		if column := s.column(name); column != nil && column.columnType() != "" {
+			featureType = column.columnType()
+		}
+		featureTypes[name] = featureType
+	}
+	return featureTypes
+}
+
+// Reading returns how the schema reads values, to be saved with a model
+// trained on the input it declares. It is nil when the schema declares no
+// missing values or date formats.
+func (s *Schema) Reading() *t.Reading {
+	if s == nil {
+		return nil
+	}
+	reading := &t.Reading{Missing: s.Missing}
+	for _, column := range s.Columns {
+		if len(column.Missing) > 0 {
+			if reading.ColumnMissing == nil {
+				reading.ColumnMissing = make(map[string][]string)
+			}
+			reading.ColumnMissing[column.Name] = column.Missing
+		}
+		if column.DateFormat != "" {
+			if reading.DateFormats == nil {
+				reading.DateFormats = make(map[string]string)
+			}
+			reading.DateFormats[column.Name] = column.DateFormat
+		}
+	}
+	if len(reading.Missing) == 0 && reading.ColumnMissing == nil && reading.DateFormats == nil {
+		return nil
+	}
+	return reading
+}
+
+// ForModel returns the schema the files a model predicts are read with:
+// the columns of the model with the types it was trained on, and the
+// missing values and date formats of its training input. Declarations of
+// declared add to them, but cannot change the type of a column the model
+// knows, since its splits depend on it.
+func ForModel(model *t.Model, declared *Schema) *Schema {
+	names := make([]string, 0, len(model.FeatureTypes))
+	for name := range model.FeatureTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	s := &Schema{Columns: make([]Column, 0, len(names))}
+	reading := model.Reading
+	if reading == nil {
+		reading = &t.Reading{}
+	}
+	s.Missing = append(s.Missing, reading.Missing...)
+	for _, name := range names {
+		s.Columns = append(s.Columns, Column{
+			Name:       name,
+			Type:       model.FeatureTypes[name],
+			DateFormat: reading.DateFormats[name],
+			Missing:    reading.ColumnMissing[name],
+		})
+	}
+	if declared == nil {
+		return s
+	}
+
+	s.Missing = append(s.Missing, declared.Missing...)
+	for _, column := range declared.Columns {
+		known := s.column(column.Name)
+		if known == nil {
+			s.Columns = append(s.Columns, column)
+			continue
+		}
+		known.Role = column.Role
+		known.Categories = column.Categories
+		if column.DateFormat != "" {
+			known.DateFormat = column.DateFormat
+		}
+		known.Missing = slices.Concat(known.Missing, column.Missing)
+	}
+	return s
+}
+
+// Records returns a reader of the records of records with the declared
+// values applied: missing values are emptied, as are categories a column
+// does not allow, and dates in a declared format are rewritten in the
+// format types are inferred from. A nil schema returns records unchanged.
+func (s *Schema) Records(records tcsv.RecordReader, headers []string) tcsv.RecordReader {
+	if s == nil {
+		return records
+	}
+
+	reader := &reader{records: records, columns: make([]*rules, len(headers))}
+	reader.texts, _ = records.(tcsv.TextReader)
+	for i, header := range headers {
+		column := s.column(header)
+		if column == nil && len(s.Missing) == 0 {
+			continue
+		}
+		rules := &rules{missing: make(map[string]bool)}
+		for _, token := range s.Missing {
+			rules.missing[token] = true
+		}
+		if column != nil {
+			for _, token := range column.Missing {
+				rules.missing[token] = true
+			}
+			if len(column.Categories) > 0 {
+				rules.categories = make(map[string]bool, len(column.Categories))
+				for _, category := range column.Categories {
+					rules.categories[category] = true
+				}
+			}
+			rules.dateFormat = column.DateFormat
+			rules.timestamp = column.columnType() == "timestamp"
+		}
+		reader.columns[i] = rules
+	}
+	return reader
+}
+
+// rules are the declared values of one column
+type rules struct {
+	missing    map[string]bool
+	categories map[string]bool
+	dateFormat string
+	timestamp  bool
+}
+
+// apply returns value with the rules applied
+func (r *rules) apply(value string) string {
+	if value == "" || r.missing[value] {
+		return ""
+	}
+	if r.categories != nil && !r.categories[value] {
+		return ""
+	}
+	if r.dateFormat != "" {
+		if date, err := time.Parse(r.dateFormat, value); err == nil {
+			if r.timestamp {
+				return date.Format(time.RFC3339)
+			}
+			return date.Format("2006-01-02")
+		}
+	}
+	return value
+}
+
+// reader applies the rules of each column to the records it reads
+type reader struct {
+	records tcsv.RecordReader
+	texts   tcsv.TextReader
+	columns []*rules
+}
+
+func (r *reader) Read() ([]string, error) {
+	record, err := r.records.Read()
+	if err != nil {
+		return record, err
+	}
+	for i, value := range record {
+		if i < len(r.columns) && r.columns[i] != nil {
+			record[i] = r.columns[i].apply(value)
+		}
+	}
+	return record, nil
+}
+
+func (r *reader) Line() int {
+	if lines, ok := r.records.(tcsv.LineReader); ok {
+		return lines.Line()
+	}
+	return 0
+}
+
+func (r *reader) IsText(column int) bool {
+	return r.texts != nil && r.texts.IsText(column)
+}
+
+// Infer builds the schema of a parsed dataset, as a template to edit: the
+// inferred type of every column, the values of categorical columns with
+// few distinct values, and the target and weight roles. Declarations of a
+// schema the dataset was read with are kept.
+func (s *Schema) Infer(instances []t.Instance, headers []string, featureTypes map[string]string, target, weight string) *Schema {
+	inferred := &Schema{Columns: make([]Column, 0, len(headers))}
+	if s != nil {
+		inferred.Missing = s.Missing
+	}
+
+	for _, header := range headers {
+		column := Column{Name: header}
+		if s != nil && s.column(header) != nil {
+			column = *s.column(header)
+		}
+		if column.Type == "" {
+			column.Type = featureTypes[header]
+		}
+		if column.Role == "" {
+			switch header {
+			case target:
+				column.Role = Target
+			case weight:
+				column.Role = Weight
+			default:
+				column.Role = Feature
+			}
+		}
+		if column.Type == "categorical" && len(column.Categories) == 0 {
+			column.Categories = categories(instances, header)
+		}
+		inferred.Columns = append(inferred.Columns, column)
+	}
+	return inferred
+}
+
+// categories returns the sorted distinct values of a column, or nil when
+// there are none or more than a template lists
+func categories(instances []t.Instance, header string) []string {
+	seen := make(map[string]bool)
+	for _, instance := range instances {
+		value := instance[header]
+		if value == nil || value == "" {
+			continue
+		}
+		seen[fmt.Sprintf("%v", value)] = true
+		if len(seen) > templateCategories {
+			return nil
+		}
+	}
+	if len(seen) == 0 {
+		return nil
+	}
+	values := make([]string, 0, len(seen))
+	for value := range seen {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// Write writes the schema as JSON when format is json, and as YAML with a
+// comment listing the types and roles otherwise
+func (s *Schema) Write(w io.Writer, format string) error {
+	if format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(s)
+	}
+
+	fmt.Fprintf(w, "# types: %s (inferred when left out)\n", strings.Join(Types, ", "))
+	fmt.Fprintf(w, "# roles: %s\n", strings.Join(Roles, ", "))
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(s); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,173 @@
+package schema
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tcsv "github.com/nyunja/c4.5-decision-tree/internal/csv"
+	t "github.com/nyunja/c4.5-decision-tree/internal/model/types"
+)
+
+const declared = `
+missing: [NA, "?"]
+columns:
+  - name: customer_id
+    role: id
+  - name: zip
+    type: categorical
+  - name: joined
+    date_format: 02/01/2006
+  - name: plan
+    categories: [pro, basic]
+    missing: [unknown]
+  - name: label
+    role: target
+`
+
+const customers = `customer_id,zip,plan,joined,spend,label
+1,02139,pro,03/01/2024,10,yes
+2,10001,unknown,15/01/2024,NA,no
+3,02139,gold,20/02/2024,?,yes
+`
+
+func writeFile(tt *testing.T, name, content string) string {
+	file := filepath.Join(tt.TempDir(), name)
+	require.NoError(tt, os.WriteFile(file, []byte(content), 0o644))
+	return file
+}
+
+func TestLoad(tt *testing.T) {
+	schema, err := Load(writeFile(tt, "schema.yaml", declared))
+	require.NoError(tt, err)
+	assert.Equal(tt, "label", schema.Target())
+	assert.Equal(tt, "", schema.Weight())
+	assert.Equal(tt, []string{"customer_id"}, schema.Excluded())
+
+	json := `{"columns": [{"name": "zip", "type": "Categorical"}, {"name": "w", "role": "weight"}]}`
+	schema, err = Load(writeFile(tt, "schema.json", json))
+	require.NoError(tt, err)
+	assert.Equal(tt, "categorical", schema.Columns[0].Type)
+	assert.Equal(tt, "w", schema.Weight())
+}
+
+func TestLoad_Invalid(tt *testing.T) {
+	tests := map[string]string{
+		"unknown type":     "columns:\n  - name: a\n    type: text\n",
+		"unknown role":     "columns:\n  - name: a\n    role: label\n",
+		"unknown field":    "columns:\n  - name: a\n    kind: numerical\n",
+		"duplicate":        "columns:\n  - name: a\n  - name: a\n",
+		"two targets":      "columns:\n  - name: a\n    role: target\n  - name: b\n    role: target\n",
+		"numeric category": "columns:\n  - name: a\n    type: numerical\n    categories: [x]\n",
+		"unnamed":          "columns:\n  - type: numerical\n",
+	}
+	for name, content := range tests {
+		_, err := Load(writeFile(tt, "schema.yaml", content))
+		assert.Error(tt, err, name)
+	}
+}
+
+func TestFeatureTypes(tt *testing.T) {
+	schema, err := Load(writeFile(tt, "schema.yaml", declared))
+	require.NoError(tt, err)
+
+	featureTypes := schema.FeatureTypes(map[string]string{"zip": "numerical", "joined": "categorical", "spend": "categorical"})
+	assert.Equal(tt, "categorical", featureTypes["zip"])
+	assert.Equal(tt, "date", featureTypes["joined"])
+	// Columns the schema leaves out keep their inferred type
+	assert.Equal(tt, "categorical", featureTypes["spend"])
+
+	var none *Schema
+	assert.Equal(tt, map[string]string{"a": "numerical"}, none.FeatureTypes(map[string]string{"a": "numerical"}))
+}
+
+func TestRecords(tt *testing.T) {
+	schema, err := Load(writeFile(tt, "schema.yaml", declared))
+	require.NoError(tt, err)
+
+	dialect := tcsv.DefaultDialect()
+	reader := tcsv.NewCSVReader(strings.NewReader(customers), dialect)
+	headers, err := tcsv.ReadCSVHeaders(reader, dialect)
+	require.NoError(tt, err)
+
+	stats, err := tcsv.CollectRecordStatistics(schema.Records(reader, headers), headers)
+	require.NoError(tt, err)
+	featureTypes := schema.FeatureTypes(tcsv.DetermineColumnTypes(stats))
+	// Missing tokens no longer make spend categorical, and the declared
+	// date format is recognised
+	assert.Equal(tt, "numerical", featureTypes["spend"])
+	assert.Equal(tt, "date", featureTypes["joined"])
+	assert.Equal(tt, "categorical", featureTypes["zip"])
+	// Missing values and categories that are not allowed are left out
+	assert.Equal(tt, map[string]int{"pro": 1}, stats.ColumnStats["plan"].UniqueValues)
+	assert.Contains(tt, stats.ColumnStats["joined"].UniqueValues, "2024-01-15")
+}
+
+func TestForModel(tt *testing.T) {
+	schema, err := Load(writeFile(tt, "schema.yaml", declared))
+	require.NoError(tt, err)
+	model := &t.Model{
+		FeatureTypes: map[string]string{"zip": "categorical", "joined": "date", "plan": "categorical", "spend": "numerical", "label": "categorical"},
+		Reading:      schema.Reading(),
+	}
+	assert.Equal(tt, []string{"NA", "?"}, model.Reading.Missing)
+	assert.Equal(tt, map[string][]string{"plan": {"unknown"}}, model.Reading.ColumnMissing)
+	assert.Equal(tt, map[string]string{"joined": "02/01/2006"}, model.Reading.DateFormats)
+
+	// The model's types override those inferred, and its missing values
+	// and date formats apply without a schema
+	predicted := ForModel(model, nil)
+	assert.Equal(tt, "categorical", predicted.FeatureTypes(map[string]string{"zip": "numerical"})["zip"])
+	assert.Equal(tt, []string{"unknown"}, predicted.column("plan").Missing)
+	assert.Equal(tt, []string{"NA", "?"}, predicted.Missing)
+	assert.Equal(tt, "02/01/2006", predicted.column("joined").DateFormat)
+
+	// A schema given at prediction adds values but cannot change the type
+	// the model was trained on
+	predicted = ForModel(model, &Schema{Missing: []string{"n/a"}, Columns: []Column{{Name: "spend", Type: "categorical", Missing: []string{"-"}}, {Name: "extra", Type: "numerical"}}})
+	assert.Equal(tt, []string{"NA", "?", "n/a"}, predicted.Missing)
+	assert.Equal(tt, "numerical", predicted.column("spend").Type)
+	assert.Equal(tt, []string{"-"}, predicted.column("spend").Missing)
+	assert.Equal(tt, "numerical", predicted.column("extra").Type)
+
+	var none *Schema
+	assert.Nil(tt, none.Reading())
+	assert.Nil(tt, (&Schema{Columns: []Column{{Name: "zip", Type: "categorical"}}}).Reading())
+}
+
+func TestInferAndWrite(tt *testing.T) {
+	schema, err := Load(writeFile(tt, "schema.yaml", declared))
+	require.NoError(tt, err)
+
+	instances := []t.Instance{{"zip": "02139"}, {"zip": "10001"}, {"zip": nil}}
+	headers := []string{"customer_id", "zip", "spend", "label"}
+	featureTypes := map[string]string{"customer_id": "numerical", "zip": "categorical", "spend": "numerical", "label": "categorical"}
+
+	var none *Schema
+	inferred := none.Infer(instances, headers, featureTypes, "label", "spend")
+	assert.Equal(tt, Feature, inferred.Columns[0].Role)
+	assert.Equal(tt, []string{"02139", "10001"}, inferred.Columns[1].Categories)
+	assert.Equal(tt, Weight, inferred.Columns[2].Role)
+	assert.Equal(tt, Target, inferred.Columns[3].Role)
+
+	// Declarations are kept
+	inferred = schema.Infer(instances, headers, featureTypes, "label", "")
+	assert.Equal(tt, ID, inferred.Columns[0].Role)
+	assert.Equal(tt, "numerical", inferred.Columns[0].Type)
+	assert.Equal(tt, []string{"NA", "?"}, inferred.Missing)
+
+	// The template reads back as the same schema
+	for _, name := range []string{"schema.yaml", "schema.json"} {
+		var buffer bytes.Buffer
+		format := strings.TrimPrefix(filepath.Ext(name), ".")
+		require.NoError(tt, inferred.Write(&buffer, format))
+		read, err := Load(writeFile(tt, name, buffer.String()))
+		require.NoError(tt, err, name)
+		assert.Equal(tt, inferred, read, name)
+	}
+}
@@ -0,0 +1,112 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Stdin is the file name that reads standard input
+const Stdin = "-"
+
+// Magic bytes of the supported compression formats
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	bzip2Magic = []byte("BZh") // followed by the block size, 1 to 9
+)
+
+// IsStdin reports whether file names standard input
+func IsStdin(file string) bool {
+	return file == Stdin
+}
+
+// Exists reports whether file can be opened: standard input or a file that exists
+func Exists(file string) bool {
+	if IsStdin(file) {
+		return true
+	}
+	_, err := os.Stat(file)
+	return err == nil
+}
+
+// BaseName returns file without the extension of a compression format, so
+// data.csv.gz is read as data.csv
+func BaseName(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".gz", ".gzip", ".zst", ".zstd", ".bz2":
+		return strings.TrimSuffix(file, filepath.Ext(file))
+	}
+	return file
+}
+
+// Open opens file, or standard input for "-", and decompresses gzip, zstd
+// and bzip2 content, recognised by its magic bytes rather than its name
+func Open(file string) (io.ReadCloser, error) {
+	var f *os.File
+	if IsStdin(file) {
+		f = os.Stdin
+	} else {
+		var err error
+		f, err = os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("error opening file: %v", err)
+		}
+	}
+
+	reader, err := Decompress(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("error reading %s: %v", file, err)
+	}
+	return &readCloser{Reader: reader, closers: []io.Closer{reader, f}}, nil
+}
+
+// Decompress returns a reader of the decompressed content of r, or of r
+// itself when it is not compressed
+func Decompress(r io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReaderSize(r, 1<<20)
+	magic, _ := buffered.Peek(4)
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		reader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip content: %v", err)
+		}
+		return reader, nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		decoder, err := zstd.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("invalid zstd content: %v", err)
+		}
+		return decoder.IOReadCloser(), nil
+	case bytes.HasPrefix(magic, bzip2Magic) && len(magic) == 4 && magic[3] >= '1' && magic[3] <= '9':
+		return io.NopCloser(bzip2.NewReader(buffered)), nil
+	}
+	return io.NopCloser(buffered), nil
+}
+
+// readCloser closes the decompressor and the file under it
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *readCloser) Close() error {
+	var err error
+	for _, closer := range r.closers {
+		if closeErr := closer.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,100 @@
+package source
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const content = "a,b\n1,x\n"
+
+// bzip2Content is content compressed by bzip2, which the standard library
+// only decompresses
+var bzip2Content = []byte{
+	0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0xd4, 0x7b,
+	0xf1, 0x6e, 0x00, 0x00, 0x02, 0xd9, 0x80, 0x00, 0x10, 0x00, 0x04, 0x20,
+	0x00, 0x30, 0x00, 0x00, 0x40, 0x20, 0x00, 0x21, 0xa6, 0x99, 0xa0, 0xc0,
+	0x28, 0x15, 0x0b, 0x0b, 0xb9, 0x22, 0x9c, 0x28, 0x48, 0x6a, 0x3d, 0xf8,
+	0xb7, 0x00,
+}
+
+func gzipContent(t *testing.T) []byte {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	_, err := writer.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buffer.Bytes()
+}
+
+func zstdContent(t *testing.T) []byte {
+	encoder, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	defer encoder.Close()
+	return encoder.EncodeAll([]byte(content), nil)
+}
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"data.csv", []byte(content)},
+		{"data.csv.gz", gzipContent(t)},
+		{"data.csv.zst", zstdContent(t)},
+		{"data.csv.bz2", bzip2Content},
+		// The magic bytes decide, not the extension
+		{"data.csv", gzipContent(t)},
+		{"data.csv.gz", []byte(content)},
+	}
+
+	for _, tc := range tests {
+		file := filepath.Join(t.TempDir(), tc.name)
+		require.NoError(t, os.WriteFile(file, tc.data, 0o644))
+
+		f, err := Open(file)
+		require.NoError(t, err, tc.name)
+		read, err := io.ReadAll(f)
+		require.NoError(t, err, tc.name)
+		assert.Equal(t, content, string(read), tc.name)
+		assert.NoError(t, f.Close())
+	}
+}
+
+func TestOpen_Missing(t *testing.T) {
+	_, err := Open(filepath.Join(t.TempDir(), "missing.csv"))
+	assert.Error(t, err)
+}
+
+func TestDecompress_Corrupt(t *testing.T) {
+	_, err := Decompress(bytes.NewReader([]byte{0x1f, 0x8b, 0x00}))
+	assert.Error(t, err)
+}
+
+func TestDecompress_TextStartingLikeBzip2(t *testing.T) {
+	reader, err := Decompress(bytes.NewReader([]byte("BZh,label\n")))
+	require.NoError(t, err)
+	read, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "BZh,label\n", string(read))
+}
+
+func TestBaseName(t *testing.T) {
+	assert.Equal(t, "data.csv", BaseName("data.csv.gz"))
+	assert.Equal(t, "data.jsonl", BaseName("data.jsonl.zst"))
+	assert.Equal(t, "golf.data", BaseName("golf.data.bz2"))
+	assert.Equal(t, "data.csv", BaseName("data.csv"))
+	assert.Equal(t, Stdin, BaseName(Stdin))
+}
+
+func TestExists(t *testing.T) {
+	assert.True(t, Exists(Stdin))
+	assert.False(t, Exists(filepath.Join(t.TempDir(), "missing.csv")))
+}